@@ -8,9 +8,15 @@ import (
 	"itv-movie/internal/api/routes"
 	"itv-movie/internal/api/services"
 	"itv-movie/internal/config"
+	"itv-movie/internal/pkg/enrichment"
+	"itv-movie/internal/pkg/jwt"
+	"itv-movie/internal/pkg/mailer"
+	"itv-movie/internal/pkg/oidc"
 	"itv-movie/internal/pkg/utils/logger"
+	"itv-movie/internal/pkg/video"
 	"itv-movie/internal/storage/database"
 	"itv-movie/internal/storage/database/repositories"
+	"itv-movie/internal/storage/media"
 	"log"
 	"log/slog"
 	"os"
@@ -34,28 +40,82 @@ func main() {
 			provideLoggerEnv,
 			logger.SetupLogger,
 			database.MustLoadDB,
+			jwt.NewKeySet,
+			mailer.NewMailer,
+			oidc.NewRegistry,
+			media.NewStorage,
+			video.NewDefaultResolver,
+			enrichment.NewProvider,
 
 			// Repositories
 			repositories.NewLanguageRepository,
 			repositories.NewGenreRepository,
+			repositories.NewTagRepository,
+			repositories.NewAwardRepository,
 			repositories.NewCountryRepository,
 			repositories.NewMovieRepository,
 			repositories.NewUserRepository,
 			repositories.NewSessionRepository,
+			repositories.NewSecurityEventRepository,
+			repositories.NewLoginThrottleRepository,
+			repositories.NewUserTokenRepository,
+			repositories.NewRecoveryCodeRepository,
+			repositories.NewUserIdentityRepository,
+			repositories.NewApiKeyRepository,
+			repositories.NewRoleRepository,
+			repositories.NewMediaAssetRepository,
+			repositories.NewSlugRepository,
+			repositories.NewStatsRepository,
+			repositories.NewActivityRepository,
+			repositories.NewRecommendationRepository,
+			repositories.NewPopularityRepository,
 
 			// Services
+			services.NewSlugService,
+			services.NewSimilarService,
 			services.NewLanguageService,
 			services.NewGenreService,
+			services.NewTagService,
+			services.NewAwardService,
 			services.NewCountryService,
 			services.NewMovieService,
+			services.NewLoginThrottleService,
+			services.NewRoleService,
+			services.NewAccountService,
+			services.NewMfaService,
+			services.NewApiKeyService,
 			services.NewAuthService,
+			services.NewOidcService,
+			services.NewSessionService,
+			services.NewMediaService,
+			services.NewEnrichmentService,
+			services.NewFeedService,
+			services.NewStatsService,
+			services.NewActivityService,
+			services.NewRecommendationService,
+			services.NewPopularityService,
 
 			// Handlers setup
 			handlers.NewLanguageHandler,
 			handlers.NewGenreHandler,
+			handlers.NewTagHandler,
+			handlers.NewAwardHandler,
 			handlers.NewCountryHandler,
 			handlers.NewMovieHandler,
 			handlers.NewAuthHandler,
+			handlers.NewSessionHandler,
+			handlers.NewAccountHandler,
+			handlers.NewMfaHandler,
+			handlers.NewOidcHandler,
+			handlers.NewApiKeyHandler,
+			handlers.NewRoleHandler,
+			handlers.NewMediaHandler,
+			handlers.NewEnrichmentHandler,
+			handlers.NewFeedHandler,
+			handlers.NewStatsHandler,
+			handlers.NewActivityHandler,
+			handlers.NewRecommendationHandler,
+			handlers.NewPopularityHandler,
 
 			// Router
 			routes.NewRouter,
@@ -66,6 +126,10 @@ func main() {
 
 		// Lifecycle hooks
 		fx.Invoke(registerHooks),
+		fx.Invoke(backfillSlugs),
+		fx.Invoke(startRecommendationJob),
+		fx.Invoke(startPopularityJob),
+		fx.Invoke(startLoginThrottleJob),
 		fx.Invoke(startHTTPServer),
 	)
 
@@ -122,3 +186,68 @@ func registerHooks(lc fx.Lifecycle, db *database.PostgresDB, cfg *config.Config,
 		},
 	})
 }
+
+func backfillSlugs(lc fx.Lifecycle, slugService *services.SlugService, log *slog.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// Rows created before slugs existed get them in the background
+			go func() {
+				if err := slugService.Backfill(context.Background()); err != nil {
+					log.Error("Failed to backfill slugs", "error", err)
+				}
+			}()
+			return nil
+		},
+	})
+}
+
+func startRecommendationJob(lc fx.Lifecycle, recommendationService *services.RecommendationService, log *slog.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Starting recommendation job")
+			go recommendationService.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			log.Info("Stopping recommendation job")
+			cancel()
+			return nil
+		},
+	})
+}
+
+func startPopularityJob(lc fx.Lifecycle, popularityService *services.PopularityService, log *slog.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Starting popularity job")
+			go popularityService.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			log.Info("Stopping popularity job")
+			cancel()
+			return nil
+		},
+	})
+}
+
+func startLoginThrottleJob(lc fx.Lifecycle, loginThrottleService *services.LoginThrottleService, log *slog.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("Starting login throttle cleanup job")
+			go loginThrottleService.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			log.Info("Stopping login throttle cleanup job")
+			cancel()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/mailer"
+	"net/http"
+	"strings"
+)
+
+// AccountHandler serves the emailed account flows: email verification and password reset
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// VerifyEmail confirms the email address with the token from the verification email
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var verifyRequest struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	if err := c.BindJSON(&verifyRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(c, verifyRequest.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Verification link is invalid or has expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email address verified"})
+}
+
+// ResendVerification mails a new verification link, the answer is the same whether the email is registered or not
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	var resendRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	if err := c.BindJSON(&resendRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResendVerification(c, resendRequest.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the address is registered and not verified yet, a verification link has been sent"})
+}
+
+// ForgotPassword mails a password reset link, the answer is the same whether the email is registered or not
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var forgotRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	if err := c.BindJSON(&forgotRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(c, forgotRequest.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send password reset email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the address is registered, a password reset link has been sent"})
+}
+
+// ResetPassword sets a new password with the token from the reset email
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var resetRequest struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=4"`
+	}
+
+	if err := c.BindJSON(&resetRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResetPassword(c, resetRequest.Token, resetRequest.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reset link is invalid or has expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed, please login again"})
+}
+
+// acceptedLocale picks the first supported language of the Accept-Language header, English otherwise
+func acceptedLocale(c *gin.Context) string {
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if mailer.IsSupportedLocale(language) {
+			return language
+		}
+	}
+	return mailer.DefaultLocale
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/api/services"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ActivityHandler handles HTTP requests for the current user's history, ratings and preferences
+type ActivityHandler struct {
+	activityService *services.ActivityService
+}
+
+// NewActivityHandler creates a new Activity handler
+func NewActivityHandler(activityService *services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+	}
+}
+
+func (h *ActivityHandler) GetHistory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := pagination(c)
+	history, total, err := h.activityService.GetHistory(c, userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve watch history: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  history,
+		"page":  page,
+		"pages": (int(total) + limit - 1) / limit,
+		"limit": limit,
+	})
+}
+
+func (h *ActivityHandler) AddWatch(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		MovieID   uuid.UUID  `json:"movieId" binding:"required"`
+		WatchedAt *time.Time `json:"watchedAt" binding:"omitempty"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	entry, err := h.activityService.AddWatch(c, userID, body.MovieID, body.WatchedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWatchedInFuture):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record watch: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *ActivityHandler) GetRatings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := pagination(c)
+	ratings, total, err := h.activityService.GetRatings(c, userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ratings: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  ratings,
+		"page":  page,
+		"pages": (int(total) + limit - 1) / limit,
+		"limit": limit,
+	})
+}
+
+func (h *ActivityHandler) RateMovie(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	movieID, err := uuid.Parse(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	var body struct {
+		Score int `json:"score" binding:"required"`
+	}
+
+	if err = c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	rating, err := h.activityService.RateMovie(c, userID, movieID, body.Score)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRatingScore):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rate movie: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+func (h *ActivityHandler) DeleteRating(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	movieID, err := uuid.Parse(c.Param("movieId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	if err = h.activityService.DeleteRating(c, userID, movieID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"message": "Rating deleted successfully"})
+}
+
+func (h *ActivityHandler) GetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	genres, err := h.activityService.GetPreferredGenres(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve preferences: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"genres": genres})
+}
+
+// UpdatePreferences replaces the preferred genres, given by name
+func (h *ActivityHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Genres []string `json:"genres" binding:"required"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	genres, err := h.activityService.SetPreferredGenres(c, userID, body.Genres)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownGenre) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update preferences: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"genres": genres})
+}
+
+// currentUserID returns the user set by AuthMiddleware, answering 401 when it is missing
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get("userID")
+	userID, ok := value.(uuid.UUID)
+	if !exists || !ok || userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// pagination reads ?page= and ?limit=, defaulting to the first page of 10
+func pagination(c *gin.Context) (page, limit int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err = strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	return page, limit
+}
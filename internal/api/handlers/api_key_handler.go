@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"itv-movie/internal/api/services"
+	"math"
+	"net/http"
+	"time"
+)
+
+// ApiKeyHandler handles the admin endpoints that issue and revoke API keys
+type ApiKeyHandler struct {
+	apiKeyService *services.ApiKeyService
+}
+
+// NewApiKeyHandler creates a new API key handler
+func NewApiKeyHandler(apiKeyService *services.ApiKeyService) *ApiKeyHandler {
+	return &ApiKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// Create issues a key, the response is the only place the key is ever shown
+func (h *ApiKeyHandler) Create(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var createRequest struct {
+		Name      string     `json:"name" binding:"required"`
+		UserID    uuid.UUID  `json:"userId" binding:"required"`
+		Scopes    []string   `json:"scopes" binding:"required"`
+		ExpiresAt *time.Time `json:"expiresAt"` // the key does not expire when omitted
+	}
+
+	if err := c.BindJSON(&createRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	key, rawKey, err := h.apiKeyService.Create(c, services.ApiKeyRequest{
+		Name:      createRequest.Name,
+		UserID:    createRequest.UserID,
+		Scopes:    createRequest.Scopes,
+		ExpiresAt: createRequest.ExpiresAt,
+	}, adminID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidApiKeyData) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": key,
+		"key":     rawKey,
+		"message": "Store the key now, it cannot be shown again",
+	})
+}
+
+// GetAll lists the issued keys, without the keys themselves
+func (h *ApiKeyHandler) GetAll(c *gin.Context) {
+	page, limit := pagination(c)
+
+	keys, total, err := h.apiKeyService.GetAll(c, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys: " + err.Error()})
+		return
+	}
+
+	lastPage := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": keys,
+		"meta": gin.H{
+			"total":        total,
+			"per_page":     limit,
+			"current_page": page,
+			"last_page":    lastPage,
+			"has_next":     page < lastPage,
+			"has_prev":     page > 1,
+		},
+	})
+}
+
+// Revoke stops a key from working
+func (h *ApiKeyHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err = h.apiKeyService.Revoke(c, id); err != nil {
+		if errors.Is(err, services.ErrApiKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
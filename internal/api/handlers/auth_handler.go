@@ -9,6 +9,7 @@ import (
 	"itv-movie/internal/models"
 	"itv-movie/internal/pkg/jwt"
 	"itv-movie/internal/pkg/utils/constants"
+	"itv-movie/internal/storage/database/repositories"
 	"math"
 	"net/http"
 	"strconv"
@@ -32,6 +33,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Username  string `json:"username" binding:"required"`
 		Email     string `json:"email" binding:"required,email"`
 		Password  string `json:"password" binding:"required,min=4"`
+		Language  string `json:"language" binding:"omitempty,oneof=uz ru en"` // of the emails, Accept-Language when empty
 	}
 
 	if err := c.BindJSON(&registerRequest); err != nil {
@@ -39,6 +41,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	locale := registerRequest.Language
+	if locale == "" {
+		locale = acceptedLocale(c)
+	}
+
 	newUser := &models.User{
 		FirstName: registerRequest.FirstName,
 		LastName:  registerRequest.LastName,
@@ -47,6 +54,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Password:  registerRequest.Password,
 		Role:      constants.UserRole,
 		Active:    true,
+		Locale:    locale,
 	}
 
 	createdUser, err := h.authService.RegisterUser(c, newUser)
@@ -78,27 +86,64 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	userAgent := c.GetHeader("User-Agent")
 	ipAddress := c.ClientIP()
 
-	user, session, err := h.authService.Login(c, loginRequest.Username, loginRequest.Password, userAgent, ipAddress)
+	result, err := h.authService.Login(c, loginRequest.Username, loginRequest.Password, userAgent, ipAddress)
 	if err != nil {
+		if loginBlocked(c, err) {
+			return
+		}
+
 		switch err {
 		case services.ErrInvalidCredentials:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
 		case services.ErrUserInactive:
 			c.JSON(http.StatusForbidden, gin.H{"error": "User account is inactive"})
+		case services.ErrEmailNotVerified:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed: " + err.Error()})
 		}
 		return
 	}
 
-	user.Password = ""
+	loginResponse(c, result)
+}
+
+// loginResponse answers with the session tokens, or with the MFA challenge to answer first
+func loginResponse(c *gin.Context, result *services.LoginResult) {
+	if result.Challenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required":        true,
+			"mfa_token":           result.Challenge.Token,
+			"expires_at":          result.Challenge.ExpiresAt,
+			"enrollment_required": result.Challenge.EnrollmentRequired,
+		})
+		return
+	}
+
+	result.User.Password = ""
 
 	c.JSON(http.StatusOK, gin.H{
-		"user":          user,
-		"access_token":  session.AccessToken,
-		"refresh_token": session.RefreshToken,
-		"expires_at":    session.ExpiresAt,
+		"user":          result.User,
+		"access_token":  result.Session.AccessToken,
+		"refresh_token": result.Session.RefreshToken,
+		"expires_at":    result.Session.ExpiresAt,
+	})
+}
+
+// loginBlocked answers 429 with Retry-After when err is a *services.LoginBlockedError
+func loginBlocked(c *gin.Context, err error) bool {
+	var blocked *services.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(blocked.RetryAfter().Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts, try again later",
+		"retry_after": retryAfter,
 	})
+	return true
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
@@ -146,6 +191,8 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid session"})
 		case errors.Is(err, services.ErrRefreshTokenExpired):
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has expired, please login again"})
+		case errors.Is(err, services.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used, please login again"})
 		case errors.Is(err, services.ErrInvalidToken):
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token format"})
 		default:
@@ -235,6 +282,7 @@ func (h *AuthHandler) RegisterAdmin(c *gin.Context) {
 		Password:  registerRequest.Password,
 		Role:      constants.AdminRole,
 		Active:    true,
+		Locale:    acceptedLocale(c),
 	}
 
 	createdUser, err := h.authService.RegisterUser(c, newUser)
@@ -274,6 +322,7 @@ func (h *AuthHandler) RegisterDirector(c *gin.Context) {
 		Password:  registerRequest.Password,
 		Role:      constants.DirectorRole,
 		Active:    true,
+		Locale:    acceptedLocale(c),
 	}
 
 	createdUser, err := h.authService.RegisterUser(c, newUser)
@@ -331,3 +380,108 @@ func (h *AuthHandler) GetAllUsers(c *gin.Context) {
 		},
 	})
 }
+
+// GetSecurityEvents lists suspicious authentication activity, filterable by ?userId= and ?type=
+func (h *AuthHandler) GetSecurityEvents(c *gin.Context) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	filter := repositories.SecurityEventFilter{Type: c.Query("type")}
+	if userIDStr := c.Query("userId"); userIDStr != "" {
+		if filter.UserID, err = uuid.Parse(userIDStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+			return
+		}
+	}
+
+	events, total, err := h.authService.GetSecurityEvents(c, filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch security events: " + err.Error()})
+		return
+	}
+
+	lastPage := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": events,
+		"meta": gin.H{
+			"total":        total,
+			"per_page":     limit,
+			"current_page": page,
+			"last_page":    lastPage,
+			"has_next":     page < lastPage,
+			"has_prev":     page > 1,
+		},
+	})
+}
+
+// GetJWKS publishes the public keys downstream services verify our tokens with
+func (h *AuthHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
+// GetLoginLockouts lists the usernames and IP addresses that are blocked from logging in
+func (h *AuthHandler) GetLoginLockouts(c *gin.Context) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	lockouts, total, err := h.authService.GetLoginLockouts(c, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockouts: " + err.Error()})
+		return
+	}
+
+	lastPage := int(math.Ceil(float64(total) / float64(limit)))
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": lockouts,
+		"meta": gin.H{
+			"total":        total,
+			"per_page":     limit,
+			"current_page": page,
+			"last_page":    lastPage,
+			"has_next":     page < lastPage,
+			"has_prev":     page > 1,
+		},
+	})
+}
+
+// UnlockLogin lifts the login lockout of a username and/or an IP address
+func (h *AuthHandler) UnlockLogin(c *gin.Context) {
+	var unlockRequest struct {
+		Username string `json:"username"`
+		IP       string `json:"ip"`
+	}
+
+	if err := c.BindJSON(&unlockRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	unlocked, err := h.authService.UnlockLogin(c, unlockRequest.Username, unlockRequest.IP)
+	if err != nil {
+		if errors.Is(err, services.ErrNothingToUnlock) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "username or ip is required"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Login unlocked", "unlocked": unlocked})
+}
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/models"
+	"net/http"
+)
+
+// AwardHandler handles HTTP requests for awards, award categories and nominations
+type AwardHandler struct {
+	awardService *services.AwardService
+}
+
+// NewAwardHandler creates a new Award handler
+func NewAwardHandler(awardService *services.AwardService) *AwardHandler {
+	return &AwardHandler{
+		awardService: awardService,
+	}
+}
+
+type nominationRequest struct {
+	CategoryID uuid.UUID `json:"categoryId" binding:"required"`
+	Year       int       `json:"year" binding:"required"`
+	Person     string    `json:"person" binding:"omitempty"` // empty when the movie itself is nominated
+	Won        bool      `json:"won"`
+}
+
+func (h *AwardHandler) CreateAward(c *gin.Context) {
+	var body struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description" binding:"omitempty"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	award, err := h.awardService.CreateAward(c, &models.Award{Name: body.Name, Description: body.Description})
+	if err != nil {
+		if errors.Is(err, services.ErrAwardExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create award: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, award)
+}
+
+func (h *AwardHandler) GetAllAwards(c *gin.Context) {
+	awards, err := h.awardService.GetAllAwards(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve awards: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": awards})
+}
+
+func (h *AwardHandler) GetAward(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award ID format"})
+		return
+	}
+
+	award, err := h.awardService.GetAward(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Award not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, award)
+}
+
+func (h *AwardHandler) UpdateAward(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award ID format"})
+		return
+	}
+
+	var body struct {
+		Name        *string `json:"name,omitempty"`
+		Description *string `json:"description,omitempty"`
+	}
+
+	if err = c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	award, err := h.awardService.GetAward(c, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Award not found"})
+		return
+	}
+
+	if body.Name != nil {
+		award.Name = *body.Name
+	}
+	if body.Description != nil {
+		award.Description = *body.Description
+	}
+
+	updatedAward, err := h.awardService.UpdateAward(c, award)
+	if err != nil {
+		if errors.Is(err, services.ErrAwardExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update award: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedAward)
+}
+
+func (h *AwardHandler) DeleteAward(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award ID format"})
+		return
+	}
+
+	if _, err = h.awardService.GetAward(c, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Award not found"})
+		return
+	}
+
+	if err = h.awardService.DeleteAward(c, id); err != nil {
+		if errors.Is(err, services.ErrAwardInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete award: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"message": "Award deleted successfully"})
+}
+
+func (h *AwardHandler) CreateCategory(c *gin.Context) {
+	awardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award ID format"})
+		return
+	}
+
+	var body struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err = c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	category, err := h.awardService.CreateCategory(c, &models.AwardCategory{AwardID: awardID, Name: body.Name})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Award not found"})
+			return
+		}
+		if errors.Is(err, services.ErrCategoryExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create award category: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *AwardHandler) UpdateCategory(c *gin.Context) {
+	category, ok := h.category(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	category.Name = body.Name
+	updatedCategory, err := h.awardService.UpdateCategory(c, category)
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update award category: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedCategory)
+}
+
+func (h *AwardHandler) DeleteCategory(c *gin.Context) {
+	category, ok := h.category(c)
+	if !ok {
+		return
+	}
+
+	if err := h.awardService.DeleteCategory(c, category.ID); err != nil {
+		if errors.Is(err, services.ErrCategoryInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete award category: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"message": "Award category deleted successfully"})
+}
+
+func (h *AwardHandler) GetMovieNominations(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	nominations, err := h.awardService.GetMovieNominations(c, movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve nominations: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": nominations})
+}
+
+func (h *AwardHandler) CreateNomination(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	var body nominationRequest
+	if err = c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	nomination, err := h.awardService.CreateNomination(c, &models.Nomination{
+		MovieID:    movieID,
+		CategoryID: body.CategoryID,
+		Year:       body.Year,
+		Person:     body.Person,
+		Won:        body.Won,
+	})
+	if err != nil {
+		h.nominationError(c, err, "Failed to create nomination: ")
+		return
+	}
+
+	c.JSON(http.StatusCreated, nomination)
+}
+
+func (h *AwardHandler) UpdateNomination(c *gin.Context) {
+	nomination, ok := h.nomination(c)
+	if !ok {
+		return
+	}
+
+	var body nominationRequest
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	nomination.CategoryID = body.CategoryID
+	nomination.Year = body.Year
+	nomination.Person = body.Person
+	nomination.Won = body.Won
+
+	updatedNomination, err := h.awardService.UpdateNomination(c, nomination)
+	if err != nil {
+		h.nominationError(c, err, "Failed to update nomination: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedNomination)
+}
+
+func (h *AwardHandler) DeleteNomination(c *gin.Context) {
+	nomination, ok := h.nomination(c)
+	if !ok {
+		return
+	}
+
+	if err := h.awardService.DeleteNomination(c, nomination); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete nomination: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"message": "Nomination deleted successfully"})
+}
+
+// category loads the :categoryId category of the :id award, answering 400 or 404 on failure
+func (h *AwardHandler) category(c *gin.Context) (*models.AwardCategory, bool) {
+	awardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award ID format"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award category ID format"})
+		return nil, false
+	}
+
+	category, err := h.awardService.GetCategory(c, awardID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Award category not found"})
+		return nil, false
+	}
+
+	return category, true
+}
+
+// nomination loads the :nominationId nomination of the :id movie, answering 400 or 404 on failure
+func (h *AwardHandler) nomination(c *gin.Context) (*models.Nomination, bool) {
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("nominationId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid nomination ID format"})
+		return nil, false
+	}
+
+	nomination, err := h.awardService.GetNomination(c, movieID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Nomination not found"})
+		return nil, false
+	}
+
+	return nomination, true
+}
+
+func (h *AwardHandler) nominationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCeremonyYear), errors.Is(err, services.ErrUnknownAwardCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNominationExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message + err.Error()})
+	}
+}
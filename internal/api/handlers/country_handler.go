@@ -7,6 +7,7 @@ import (
 	"itv-movie/internal/models"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // CountryHandler handles HTTP requests for Country
@@ -90,7 +91,17 @@ func (h *CountryHandler) GetCountry(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid country ID format"})
+		// not a UUID, so look it up as a slug
+		country, currentSlug, err := h.countryService.GetCountryBySlug(c, idStr)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Country not found"})
+			return
+		}
+		if currentSlug != "" {
+			c.Redirect(http.StatusMovedPermanently, strings.Replace(c.Request.URL.Path, idStr, currentSlug, 1))
+			return
+		}
+		c.JSON(http.StatusOK, country)
 		return
 	}
 
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/enrichment"
+	"net/http"
+	"strconv"
+)
+
+// EnrichmentHandler handles HTTP requests for metadata enrichment
+type EnrichmentHandler struct {
+	enrichmentService *services.EnrichmentService
+}
+
+// NewEnrichmentHandler creates a new Enrichment handler
+func NewEnrichmentHandler(enrichmentService *services.EnrichmentService) *EnrichmentHandler {
+	return &EnrichmentHandler{
+		enrichmentService: enrichmentService,
+	}
+}
+
+// ProposeEnrichment returns the field by field diff against the provider, ?imdbId= or ?tmdbId= override the stored IDs
+func (h *EnrichmentHandler) ProposeEnrichment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	ids := enrichment.ExternalIDs{ImdbID: c.Query("imdbId")}
+	if tmdbStr := c.Query("tmdbId"); tmdbStr != "" {
+		if ids.TmdbID, err = strconv.ParseInt(tmdbStr, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid TMDB ID"})
+			return
+		}
+	}
+
+	proposal, err := h.enrichmentService.Propose(c, id, ids)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, proposal)
+}
+
+// ApplyEnrichment merges the accepted fields into the movie
+func (h *EnrichmentHandler) ApplyEnrichment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	var body struct {
+		ImdbID string   `json:"imdbId" binding:"omitempty"`
+		TmdbID int64    `json:"tmdbId" binding:"omitempty"`
+		Fields []string `json:"fields" binding:"required,min=1"`
+	}
+
+	if err = c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	ids := enrichment.ExternalIDs{ImdbID: body.ImdbID, TmdbID: body.TmdbID}
+
+	movie, err := h.enrichmentService.Apply(c, id, ids, body.Fields)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, movie)
+}
+
+func (h *EnrichmentHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+	case errors.Is(err, enrichment.ErrMovieNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNoExternalID),
+		errors.Is(err, services.ErrUnknownEnrichField),
+		errors.Is(err, services.ErrNoEnrichFieldsGiven),
+		errors.Is(err, enrichment.ErrInvalidExternalID),
+		isLinkValidationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDuplicateExternal):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Enrichment failed: " + err.Error()})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"itv-movie/internal/api/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FeedHandler serves the sitemap and Atom feeds of the public catalog
+type FeedHandler struct {
+	feedService *services.FeedService
+}
+
+// NewFeedHandler creates a new Feed handler
+func NewFeedHandler(feedService *services.FeedService) *FeedHandler {
+	return &FeedHandler{
+		feedService: feedService,
+	}
+}
+
+// GetSitemap serves /sitemap.xml, which becomes a sitemap index once the catalog needs several pages
+func (h *FeedHandler) GetSitemap(c *gin.Context) {
+	stats, err := h.feedService.SitemapStats(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build sitemap: " + err.Error()})
+		return
+	}
+
+	if notModified(c, stats.LastModified) {
+		return
+	}
+
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err = h.feedService.WriteSitemap(c, c.Writer, stats); err != nil {
+		// the body is already being written, so the error can only be logged
+		_ = c.Error(err)
+	}
+}
+
+// GetSitemapPage serves a child sitemap, e.g. /sitemaps/movies-2.xml
+func (h *FeedHandler) GetSitemapPage(c *gin.Context) {
+	file := c.Param("file")
+	page, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file, "movies-"), ".xml"))
+	if err != nil || !strings.HasPrefix(file, "movies-") || !strings.HasSuffix(file, ".xml") {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrSitemapPageNotFound.Error()})
+		return
+	}
+
+	stats, err := h.feedService.SitemapStats(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build sitemap: " + err.Error()})
+		return
+	}
+	if page < 1 || page > h.feedService.SitemapPages(stats) {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrSitemapPageNotFound.Error()})
+		return
+	}
+
+	if notModified(c, stats.LastModified) {
+		return
+	}
+
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err = h.feedService.WriteSitemapPage(c, c.Writer, stats, page); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// GetMovieFeed serves the Atom feed of new movies, ?genre=<id or slug> narrows it to one genre
+func (h *FeedHandler) GetMovieFeed(c *gin.Context) {
+	movieFeed, err := h.feedService.GetMovieFeed(c, c.Query("genre"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed: " + err.Error()})
+		return
+	}
+
+	if notModified(c, movieFeed.Stats.LastModified) {
+		return
+	}
+
+	c.Header("Content-Type", "application/atom+xml; charset=utf-8")
+	c.Status(http.StatusOK)
+	selfURL := h.feedService.SelfURL(c.Request.URL.RequestURI())
+	if err = h.feedService.WriteMovieFeed(c, c.Writer, movieFeed, selfURL); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// notModified sets Last-Modified and answers 304 when the client copy is still current
+func notModified(c *gin.Context, lastModified time.Time) bool {
+	if lastModified.IsZero() {
+		return false
+	}
+
+	// HTTP dates have second precision
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !lastModified.After(since) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+
+	return false
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"itv-movie/internal/api/services"
 	"itv-movie/internal/models"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // GenreHandler handles HTTP requests for Genre
@@ -23,8 +26,9 @@ func NewGenreHandler(genreService *services.GenreService) *GenreHandler {
 
 func (h *GenreHandler) CreateGenre(c *gin.Context) {
 	var body struct {
-		Name        string `form:"name" binding:"required"`
-		Description string `form:"code" binding:"required"`
+		Name        string     `form:"name" binding:"required"`
+		Description string     `form:"code" binding:"required"`
+		ParentID    *uuid.UUID `json:"parentId" binding:"omitempty"`
 	}
 
 	if err := c.BindJSON(&body); err != nil {
@@ -35,10 +39,15 @@ func (h *GenreHandler) CreateGenre(c *gin.Context) {
 	newLang := models.Genre{
 		Name:        body.Name,
 		Description: body.Description,
+		ParentID:    body.ParentID,
 	}
 
 	createdGenre, err := h.genreService.CreateGenre(c, &newLang)
 	if err != nil {
+		if errors.Is(err, services.ErrUnknownParentGenre) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create genre: " + err.Error()})
 		return
 	}
@@ -84,11 +93,32 @@ func (h *GenreHandler) GetAllGenres(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetGenreTree returns all genres nested under their parents
+func (h *GenreHandler) GetGenreTree(c *gin.Context) {
+	tree, err := h.genreService.GetGenreTree(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve genre tree: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tree})
+}
+
 func (h *GenreHandler) GetGenre(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID format"})
+		// not a UUID, so look it up as a slug
+		genre, currentSlug, err := h.genreService.GetGenreBySlug(c, idStr)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+			return
+		}
+		if currentSlug != "" {
+			c.Redirect(http.StatusMovedPermanently, strings.Replace(c.Request.URL.Path, idStr, currentSlug, 1))
+			return
+		}
+		c.JSON(http.StatusOK, genre)
 		return
 	}
 
@@ -112,6 +142,7 @@ func (h *GenreHandler) UpdateGenre(c *gin.Context) {
 	var body struct {
 		Name        *string `json:"name,omitempty"`
 		Description *string `json:"description,omitempty"`
+		ParentID    *string `json:"parentId,omitempty"` // empty moves the genre to the top level
 	}
 
 	if err = c.BindJSON(&body); err != nil {
@@ -131,9 +162,24 @@ func (h *GenreHandler) UpdateGenre(c *gin.Context) {
 	if body.Description != nil {
 		genre.Description = *body.Description
 	}
+	if body.ParentID != nil {
+		genre.ParentID = nil
+		if *body.ParentID != "" {
+			parentID, err := uuid.Parse(*body.ParentID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent genre ID format"})
+				return
+			}
+			genre.ParentID = &parentID
+		}
+	}
 
 	updatedGenre, err := h.genreService.UpdateGenre(c, genre)
 	if err != nil {
+		if errors.Is(err, services.ErrUnknownParentGenre) || errors.Is(err, services.ErrGenreCycle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update genre: " + err.Error()})
 		return
 	}
@@ -155,8 +201,27 @@ func (h *GenreHandler) DeleteGenre(c *gin.Context) {
 		return
 	}
 
-	if err := h.genreService.DeleteGenre(c, genre); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete genre: " + err.Error()})
+	var reassignTo *uuid.UUID
+	if target := c.Query("reassignTo"); target != "" {
+		targetID, err := uuid.Parse(target)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reassignment genre ID format"})
+			return
+		}
+		reassignTo = &targetID
+	}
+
+	if err := h.genreService.DeleteGenre(c, genre, reassignTo); err != nil {
+		switch {
+		case errors.Is(err, services.ErrGenreInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidReassignGenre):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reassignment genre not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete genre: " + err.Error()})
+		}
 		return
 	}
 
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/storage/media"
+	"net/http"
+)
+
+// MediaHandler handles HTTP requests for movie images
+type MediaHandler struct {
+	mediaService *services.MediaService
+}
+
+// NewMediaHandler creates a new Media handler
+func NewMediaHandler(mediaService *services.MediaService) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+	}
+}
+
+// UploadMovieMedia accepts a multipart "file" with a "kind" of poster or backdrop
+func (h *MediaHandler) UploadMovieMedia(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	// leave some room for the multipart envelope and the other form fields
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.mediaService.MaxUploadSize()+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrMediaTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required: " + err.Error()})
+		return
+	}
+
+	if fileHeader.Size > h.mediaService.MaxUploadSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrMediaTooLarge.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	asset, err := h.mediaService.UploadMovieMedia(c, movieID, c.PostForm("kind"), file)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		case errors.Is(err, services.ErrInvalidMediaKind), errors.Is(err, services.ErrInvalidImage):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUnsupportedMediaType):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrMediaTooLarge), errors.Is(err, services.ErrImageTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload media: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"asset": asset,
+		"urls":  asset.VariantURLs(),
+	})
+}
+
+func (h *MediaHandler) GetMovieMedia(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	assets, err := h.mediaService.GetMovieMedia(c, movieID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve media: " + err.Error()})
+		return
+	}
+
+	data := make([]gin.H, 0, len(assets))
+	for _, asset := range assets {
+		data = append(data, gin.H{
+			"asset": asset,
+			"urls":  asset.VariantURLs(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// ServeMedia streams a stored variant, files are immutable so they can be cached for long
+func (h *MediaHandler) ServeMedia(c *gin.Context) {
+	assetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media ID format"})
+		return
+	}
+
+	file, info, err := h.mediaService.OpenMediaVariant(c, assetID, c.Param("variant"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownMediaVariant):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, media.ErrObjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open media: " + err.Error()})
+		}
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", info.ContentType)
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", `"`+assetID.String()+"-"+c.Param("variant")+`"`)
+
+	// ServeContent takes care of Last-Modified, conditional and range requests
+	http.ServeContent(c.Writer, c.Request, "", info.ModifiedAt, file)
+}
+
+func (h *MediaHandler) DeleteMovieMedia(c *gin.Context) {
+	movieID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	assetID, err := uuid.Parse(c.Param("assetId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media ID format"})
+		return
+	}
+
+	if err = h.mediaService.DeleteMovieMedia(c, movieID, assetID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, services.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, gin.H{"message": "Media deleted successfully"})
+}
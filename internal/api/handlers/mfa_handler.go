@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/services"
+	"net/http"
+)
+
+// MfaHandler handles the second login step and the TOTP setup of the current user
+type MfaHandler struct {
+	authService *services.AuthService
+	mfaService  *services.MfaService
+}
+
+// NewMfaHandler creates a new MFA handler
+func NewMfaHandler(authService *services.AuthService, mfaService *services.MfaService) *MfaHandler {
+	return &MfaHandler{
+		authService: authService,
+		mfaService:  mfaService,
+	}
+}
+
+type mfaCodeRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// Verify exchanges the MFA token of a login and a TOTP or recovery code for the session
+func (h *MfaHandler) Verify(c *gin.Context) {
+	var verifyRequest mfaCodeRequest
+	if err := c.BindJSON(&verifyRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	user, session, err := h.authService.VerifyMfa(c, verifyRequest.MfaToken, verifyRequest.Code, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	user.Password = ""
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":          user,
+		"access_token":  session.AccessToken,
+		"refresh_token": session.RefreshToken,
+		"expires_at":    session.ExpiresAt,
+	})
+}
+
+// StartLoginEnrollment starts TOTP enrollment for a user whose login requires 2FA they have not set up yet
+func (h *MfaHandler) StartLoginEnrollment(c *gin.Context) {
+	var enrollRequest struct {
+		MfaToken string `json:"mfa_token" binding:"required"`
+	}
+	if err := c.BindJSON(&enrollRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	enrollment, err := h.authService.StartMfaEnrollment(c, enrollRequest.MfaToken)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmLoginEnrollment confirms the enrollment with a code and completes the login
+func (h *MfaHandler) ConfirmLoginEnrollment(c *gin.Context) {
+	var confirmRequest mfaCodeRequest
+	if err := c.BindJSON(&confirmRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	user, session, recoveryCodes, err := h.authService.ConfirmMfaEnrollment(c, confirmRequest.MfaToken, confirmRequest.Code, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	user.Password = ""
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":           user,
+		"access_token":   session.AccessToken,
+		"refresh_token":  session.RefreshToken,
+		"expires_at":     session.ExpiresAt,
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// GetStatus tells whether the current user has 2FA enabled or must enable it
+func (h *MfaHandler) GetStatus(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(c, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// StartEnrollment returns a new TOTP secret and its provisioning URI for the current user
+func (h *MfaHandler) StartEnrollment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.mfaService.StartEnrollment(c, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmEnrollment enables TOTP for the current user and returns the recovery codes
+func (h *MfaHandler) ConfirmEnrollment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	code, ok := bindMfaCode(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.ConfirmEnrollment(c, userID, code)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recovery_codes": recoveryCodes})
+}
+
+// Disable turns TOTP off for the current user
+func (h *MfaHandler) Disable(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	code, ok := bindMfaCode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(c, userID, code); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user
+func (h *MfaHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	code, ok := bindMfaCode(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.RegenerateRecoveryCodes(c, userID, code)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+func bindMfaCode(c *gin.Context) (string, bool) {
+	var codeRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.BindJSON(&codeRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return "", false
+	}
+	return codeRequest.Code, true
+}
+
+func (h *MfaHandler) handleError(c *gin.Context, err error) {
+	if loginBlocked(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrInvalidMfaToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "MFA token is invalid or expired, please login again"})
+	case errors.Is(err, services.ErrInvalidMfaCode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+	case errors.Is(err, services.ErrUserInactive):
+		c.JSON(http.StatusForbidden, gin.H{"error": "User account is inactive"})
+	case errors.Is(err, services.ErrMfaMandatory):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is mandatory for your role"})
+	case errors.Is(err, services.ErrMfaAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+	case errors.Is(err, services.ErrMfaNotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+	case errors.Is(err, services.ErrMfaEnrollmentMissing):
+		c.JSON(http.StatusConflict, gin.H{"error": "Start the enrollment first"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Two-factor authentication failed: " + err.Error()})
+	}
+}
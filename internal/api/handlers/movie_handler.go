@@ -1,27 +1,53 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"gorm.io/gorm"
 	"itv-movie/internal/pkg/utils/constants"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"itv-movie/internal/api/middlewares"
 	"itv-movie/internal/api/services"
 	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/video"
+	"itv-movie/internal/storage/database/repositories"
 )
 
 // MovieHandler handles HTTP requests for Movies
 type MovieHandler struct {
-	movieService *services.MovieService
+	movieService   *services.MovieService
+	similarService *services.SimilarService
+	genreService   *services.GenreService
+	tagService     *services.TagService
+	awardService   *services.AwardService
+}
+
+type alternateTitleRequest struct {
+	Title   string `json:"title" binding:"required"`
+	Country string `json:"country" binding:"omitempty"` // ISO 3166-1 alpha-2 code
+	Type    string `json:"type" binding:"omitempty,oneof=alternate original"`
+}
+
+type releaseDateRequest struct {
+	Country string `json:"country" binding:"required"` // ISO 3166-1 alpha-2 code
+	Type    string `json:"type" binding:"required,oneof=theatrical digital physical tv"`
+	Date    string `json:"date" binding:"required"` // YYYY-MM-DD
 }
 
 // NewMovieHandler creates a new Movie handler
-func NewMovieHandler(movieService *services.MovieService) *MovieHandler {
+func NewMovieHandler(movieService *services.MovieService, similarService *services.SimilarService, genreService *services.GenreService, tagService *services.TagService, awardService *services.AwardService) *MovieHandler {
 	return &MovieHandler{
-		movieService: movieService,
+		movieService:   movieService,
+		similarService: similarService,
+		genreService:   genreService,
+		tagService:     tagService,
+		awardService:   awardService,
 	}
 }
 
@@ -35,10 +61,21 @@ func (h *MovieHandler) CreateMovie(c *gin.Context) {
 		Rating      *float32 `json:"rating" binding:"omitempty"`
 		PosterUrl   string   `json:"posterUrl" binding:"required"`
 		TrailerUrl  string   `json:"trailerUrl" binding:"required"`
-		ReleaseDate string   `json:"releaseDate" binding:"required"`
+		ReleaseDate string   `json:"releaseDate" binding:"required_without=ReleaseDates"`
 		Language    string   `json:"language" binding:"required"`
 		Genres      []string `json:"genres" binding:"omitempty"`
+		Tags        []string `json:"tags" binding:"omitempty"`
 		Countries   []string `json:"countries" binding:"omitempty"`
+
+		AudioLanguages    []string                `json:"audioLanguages" binding:"omitempty"`
+		SubtitleLanguages []string                `json:"subtitleLanguages" binding:"omitempty"`
+		AlternateTitles   []alternateTitleRequest `json:"alternateTitles" binding:"omitempty,dive"`
+		ReleaseDates      []releaseDateRequest    `json:"releaseDates" binding:"omitempty,min=1,dive"` // an empty list would stand in for releaseDate
+
+		ImdbID *string `json:"imdbId" binding:"omitempty"`
+		TmdbID *int64  `json:"tmdbId" binding:"omitempty,min=1"`
+
+		Published *bool `json:"published" binding:"omitempty"` // defaults to true, false keeps the movie a draft
 	}
 
 	if err := c.BindJSON(&body); err != nil {
@@ -46,10 +83,15 @@ func (h *MovieHandler) CreateMovie(c *gin.Context) {
 		return
 	}
 
-	releaseDate, err := time.Parse(constants.DateFormat, body.ReleaseDate)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid release date format. Use YYYY-MM-DD"})
-		return
+	// without releaseDate the primary date is computed from releaseDates
+	var releaseDate *time.Time
+	if body.ReleaseDate != "" {
+		date, err := time.Parse(constants.DateFormat, body.ReleaseDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid release date format. Use YYYY-MM-DD"})
+			return
+		}
+		releaseDate = &date
 	}
 
 	language, err := h.movieService.GetLangByCode(c, body.Language)
@@ -66,14 +108,21 @@ func (h *MovieHandler) CreateMovie(c *gin.Context) {
 		Runtime:     body.Runtime,
 		PosterURL:   body.PosterUrl,
 		TrailerURL:  body.TrailerUrl,
-		ReleaseDate: &releaseDate,
+		ReleaseDate: releaseDate,
 		LanguageID:  language.ID,
+		ImdbID:      body.ImdbID,
+		TmdbID:      body.TmdbID,
 	}
 
 	if body.Rating != nil {
 		newMovie.Rating = *body.Rating
 	}
 
+	if body.Published == nil || *body.Published {
+		now := time.Now()
+		newMovie.PublishedAt = &now
+	}
+
 	if len(body.Genres) > 0 {
 		genreList := make([]models.Genre, 0, len(body.Genres))
 		for _, genreName := range body.Genres {
@@ -87,6 +136,13 @@ func (h *MovieHandler) CreateMovie(c *gin.Context) {
 		newMovie.Genres = genreList
 	}
 
+	if len(body.Tags) > 0 {
+		if newMovie.Tags, err = h.tagService.EnsureTags(c, body.Tags); err != nil {
+			h.tagError(c, err)
+			return
+		}
+	}
+
 	if len(body.Countries) > 0 {
 		countryList := make([]models.Country, 0, len(body.Countries))
 		for _, countryCode := range body.Countries {
@@ -100,8 +156,36 @@ func (h *MovieHandler) CreateMovie(c *gin.Context) {
 		newMovie.Countries = countryList
 	}
 
+	if newMovie.AudioLanguages, err = h.languagesByCode(c, body.AudioLanguages); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if newMovie.SubtitleLanguages, err = h.languagesByCode(c, body.SubtitleLanguages); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if newMovie.AlternateTitles, err = h.alternateTitles(c, body.AlternateTitles); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if newMovie.ReleaseDates, err = h.releaseDates(c, body.ReleaseDates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	createdMovie, err := h.movieService.CreateMovie(c, newMovie)
 	if err != nil {
+		if isLinkValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateExternal) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create movie: " + err.Error()})
 		return
 	}
@@ -123,17 +207,72 @@ func (h *MovieHandler) GetAllMovies(c *gin.Context) {
 		limit = 10
 	}
 
-	query := c.Query("search")
+	filter := repositories.MovieFilter{
+		Query:            c.Query("search"),
+		AudioLanguage:    c.Query("audio"),
+		SubtitleLanguage: c.Query("subtitles"),
+		AllTags:          c.Query("tagMatch") == "all",
+		Awards:           c.Query("awards"),
+		ReleasedIn:       c.Query("released_in"),
+		Sort:             c.Query("sort"),
+		Region:           c.Query("region"),
+		Drafts:           canViewDrafts(c),
+	}
+
+	if genreRef := c.Query("genre"); genreRef != "" {
+		genre, err := h.genreService.GetGenreByRef(c, genreRef)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+			return
+		}
+		filter.GenreID = genre.ID
+	}
+
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = services.NormalizeTags(strings.Split(tags, ","))
+	}
+
+	var ok bool
+	if filter.ReleasedAfter, ok = queryDate(c, "released_after"); !ok {
+		return
+	}
+	if filter.ReleasedBefore, ok = queryDate(c, "released_before"); !ok {
+		return
+	}
+
+	if awardID := c.Query("awardId"); awardID != "" {
+		if filter.AwardID, err = uuid.Parse(awardID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid award ID format"})
+			return
+		}
+		if filter.Awards == "" {
+			filter.Awards = repositories.NominatedAwards
+		}
+	}
+
+	switch filter.Awards {
+	case "", repositories.WonAwards, repositories.NominatedAwards:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid awards filter, expected won or nominated"})
+		return
+	}
+
+	switch filter.Sort {
+	case "", repositories.TrendingSort, repositories.PopularSort:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort, expected trending or popular"})
+		return
+	}
 
 	var movies []*models.Movie
 	var total int
 
-	if query != "" {
-		movies, total, err = h.movieService.SearchMovies(c, query, page, limit)
+	if !filter.IsEmpty() {
+		movies, total, err = h.movieService.SearchMovies(c, filter, page, limit)
 	} else {
-		movies, err = h.movieService.GetAllMovies(c, page, limit)
+		movies, err = h.movieService.GetAllMovies(c, page, limit, filter.Drafts)
 		if err == nil {
-			total, err = h.movieService.GetTotalMovieCount(c)
+			total, err = h.movieService.GetTotalMovieCount(c, filter.Drafts)
 		}
 	}
 
@@ -158,19 +297,85 @@ func (h *MovieHandler) GetMovie(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		// not a UUID, so look it up as a slug
+		movie, currentSlug, err := h.movieService.GetMovieBySlug(c, idStr)
+		if err != nil || !isVisible(c, movie) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		if currentSlug != "" {
+			c.Redirect(http.StatusMovedPermanently, strings.Replace(c.Request.URL.Path, idStr, currentSlug, 1))
+			return
+		}
+		h.movieDetail(c, movie)
 		return
 	}
 
 	movie, err := h.movieService.GetMovie(c, id)
+	if err == nil && !isVisible(c, movie) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		return
+	}
 	if err != nil {
+		// merged movies keep resolving through a permanent redirect to the survivor
+		if survivorID, redirectErr := h.movieService.ResolveRedirect(c, id); redirectErr == nil {
+			c.Redirect(http.StatusMovedPermanently, strings.Replace(c.Request.URL.Path, idStr, survivorID.String(), 1))
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
 		return
 	}
 
+	h.movieDetail(c, movie)
+}
+
+// movieDetail answers with the movie and its award summary
+func (h *MovieHandler) movieDetail(c *gin.Context, movie *models.Movie) {
+	if err := h.awardService.SummarizeAwards(c, movie); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve awards: " + err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, movie)
 }
 
+// canViewDrafts reports whether the caller may see unpublished movies, which are hidden from everyone
+// who cannot edit them
+func canViewDrafts(c *gin.Context) bool {
+	return middlewares.HasPermission(c, constants.MovieUpdate)
+}
+
+// isVisible reports whether the movie is published or the caller may see drafts
+func isVisible(c *gin.Context, movie *models.Movie) bool {
+	return movie.PublishedAt != nil || canViewDrafts(c)
+}
+
+// GetSimilarMovies returns the "More like this" rail of a movie, ?limit= overrides the configured size
+func (h *MovieHandler) GetSimilarMovies(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	similar, err := h.similarService.GetSimilar(c, id, limit, canViewDrafts(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find similar movies: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": similar})
+}
+
 // UpdateMovie updates an existing movie
 func (h *MovieHandler) UpdateMovie(c *gin.Context) {
 	// Parse ID from URL parameter
@@ -201,7 +406,18 @@ func (h *MovieHandler) UpdateMovie(c *gin.Context) {
 		ReleaseDate *string  `json:"releaseDate,omitempty"`
 		Language    *string  `json:"language,omitempty"`
 		Genres      []string `json:"genres,omitempty"`
+		Tags        []string `json:"tags,omitempty"`
 		Countries   []string `json:"countries,omitempty"`
+
+		AudioLanguages    []string                `json:"audioLanguages,omitempty"`
+		SubtitleLanguages []string                `json:"subtitleLanguages,omitempty"`
+		AlternateTitles   []alternateTitleRequest `json:"alternateTitles,omitempty" binding:"omitempty,dive"`
+		ReleaseDates      []releaseDateRequest    `json:"releaseDates,omitempty" binding:"omitempty,dive"` // [] removes them all, releaseDate then keeps its last value
+
+		ImdbID *string `json:"imdbId,omitempty"`
+		TmdbID *int64  `json:"tmdbId,omitempty" binding:"omitempty,min=0"`
+
+		Published *bool `json:"published,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&update); err != nil {
@@ -276,6 +492,14 @@ func (h *MovieHandler) UpdateMovie(c *gin.Context) {
 		movie.Genres = genreList
 	}
 
+	// Update tags if provided, unknown ones are created
+	if update.Tags != nil {
+		if movie.Tags, err = h.tagService.EnsureTags(c, update.Tags); err != nil {
+			h.tagError(c, err)
+			return
+		}
+	}
+
 	// Update countries if provided
 	if update.Countries != nil {
 		countryList := make([]models.Country, 0, len(update.Countries))
@@ -290,8 +514,70 @@ func (h *MovieHandler) UpdateMovie(c *gin.Context) {
 		movie.Countries = countryList
 	}
 
+	// an empty string / zero detaches the external ID
+	if update.ImdbID != nil {
+		movie.ImdbID = update.ImdbID
+		if *update.ImdbID == "" {
+			movie.ImdbID = nil
+		}
+	}
+
+	if update.TmdbID != nil {
+		movie.TmdbID = update.TmdbID
+		if *update.TmdbID == 0 {
+			movie.TmdbID = nil
+		}
+	}
+
+	// publishing keeps the original publish date, unpublishing turns the movie back into a draft
+	if update.Published != nil {
+		if !*update.Published {
+			movie.PublishedAt = nil
+		} else if movie.PublishedAt == nil {
+			now := time.Now()
+			movie.PublishedAt = &now
+		}
+	}
+
+	if update.AudioLanguages != nil {
+		if movie.AudioLanguages, err = h.languagesByCode(c, update.AudioLanguages); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if update.SubtitleLanguages != nil {
+		if movie.SubtitleLanguages, err = h.languagesByCode(c, update.SubtitleLanguages); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if update.AlternateTitles != nil {
+		if movie.AlternateTitles, err = h.alternateTitles(c, update.AlternateTitles); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// release dates replace the existing ones, the primary date is recomputed from them
+	if update.ReleaseDates != nil {
+		if movie.ReleaseDates, err = h.releaseDates(c, update.ReleaseDates); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	updatedMovie, err := h.movieService.UpdateMovie(c, movie)
 	if err != nil {
+		if isLinkValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrDuplicateExternal) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie: " + err.Error()})
 		return
 	}
@@ -314,3 +600,177 @@ func (h *MovieHandler) DeleteMovie(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, gin.H{"message": "Movie deleted successfully"})
 }
+
+// FindDuplicates lists likely duplicate pairs, ?threshold= (0..1) and ?limit= tune the result
+func (h *MovieHandler) FindDuplicates(c *gin.Context) {
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", "0.8"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	pairs, err := h.movieService.FindDuplicates(c, threshold, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find duplicates: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": pairs})
+}
+
+// MergeMovies merges duplicate movies into a surviving record
+func (h *MovieHandler) MergeMovies(c *gin.Context) {
+	var body struct {
+		SurvivorID   uuid.UUID   `json:"survivorId" binding:"required"`
+		DuplicateIDs []uuid.UUID `json:"duplicateIds" binding:"required,min=1"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	movie, err := h.movieService.MergeMovies(c, body.SurvivorID, body.DuplicateIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidMerge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survivor or duplicate movie not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge movies: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, movie)
+}
+
+// GetMovieTrailer returns the normalized trailer reference with embed URL and oEmbed metadata
+func (h *MovieHandler) GetMovieTrailer(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	trailer, meta, err := h.movieService.GetTrailer(c, id, canViewDrafts(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		case errors.Is(err, services.ErrInvalidTrailerURL), errors.Is(err, video.ErrVideoNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie has no valid trailer"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch trailer metadata: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trailer": trailer,
+		"oembed":  meta,
+	})
+}
+
+// languagesByCode resolves a list of language codes into languages
+func (h *MovieHandler) languagesByCode(c *gin.Context, codes []string) ([]models.Language, error) {
+	languages := make([]models.Language, 0, len(codes))
+	for _, code := range codes {
+		language, err := h.movieService.GetLangByCode(c, code)
+		if err != nil {
+			return nil, fmt.Errorf("Language with code '%s' not found", code)
+		}
+		languages = append(languages, *language)
+	}
+	return languages, nil
+}
+
+// alternateTitles converts request titles into models, resolving the optional country codes
+func (h *MovieHandler) alternateTitles(c *gin.Context, titles []alternateTitleRequest) ([]models.AlternateTitle, error) {
+	result := make([]models.AlternateTitle, 0, len(titles))
+	for _, t := range titles {
+		title := models.AlternateTitle{
+			Title: t.Title,
+			Type:  models.AlternateTitleType,
+		}
+		if t.Type != "" {
+			title.Type = t.Type
+		}
+
+		if t.Country != "" {
+			country, err := h.movieService.GetCountryByCode(c, t.Country)
+			if err != nil {
+				return nil, fmt.Errorf("Country with code '%s' not found", t.Country)
+			}
+			title.CountryID = &country.ID
+		}
+
+		result = append(result, title)
+	}
+	return result, nil
+}
+
+// releaseDates converts request release dates into models, resolving the country codes.
+// A movie has one release per country and type
+func (h *MovieHandler) releaseDates(c *gin.Context, dates []releaseDateRequest) ([]models.ReleaseDate, error) {
+	result := make([]models.ReleaseDate, 0, len(dates))
+	seen := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		date, err := time.Parse(constants.DateFormat, d.Date)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid release date '%s'. Use YYYY-MM-DD", d.Date)
+		}
+
+		country, err := h.movieService.GetCountryByCode(c, d.Country)
+		if err != nil {
+			return nil, fmt.Errorf("Country with code '%s' not found", d.Country)
+		}
+
+		key := country.ID.String() + "/" + d.Type
+		if seen[key] {
+			return nil, fmt.Errorf("Duplicate %s release date for country '%s'", d.Type, d.Country)
+		}
+		seen[key] = true
+
+		result = append(result, models.ReleaseDate{CountryID: country.ID, Type: d.Type, Date: date})
+	}
+	return result, nil
+}
+
+// queryDate parses an optional YYYY-MM-DD query parameter, answering 400 when it is malformed
+func queryDate(c *gin.Context, param string) (*time.Time, bool) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, true
+	}
+
+	date, err := time.Parse(constants.DateFormat, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " format. Use YYYY-MM-DD"})
+		return nil, false
+	}
+
+	return &date, true
+}
+
+func isLinkValidationError(err error) bool {
+	return errors.Is(err, services.ErrInvalidTrailerURL) ||
+		errors.Is(err, services.ErrInvalidPosterURL) ||
+		errors.Is(err, services.ErrInvalidImdbID)
+}
+
+// tagError answers a failed EnsureTags
+func (h *MovieHandler) tagError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidTagName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tags: " + err.Error()})
+}
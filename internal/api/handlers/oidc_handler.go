@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/oidc"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// OidcHandler handles the sign in with external OpenID Connect providers
+type OidcHandler struct {
+	oidcService *services.OidcService
+}
+
+// NewOidcHandler creates a new OIDC handler
+func NewOidcHandler(oidcService *services.OidcService) *OidcHandler {
+	return &OidcHandler{
+		oidcService: oidcService,
+	}
+}
+
+// GetProviders lists the providers users can sign in with
+func (h *OidcHandler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.oidcService.Providers()})
+}
+
+// oidcStateCookie binds a login to the browser that started it
+const oidcStateCookie = "oidc_state"
+
+// Login redirects the browser to the provider and remembers the state in a cookie only the callback
+// receives. With ?redirect=false the authorization URL is returned as JSON instead, for browser clients
+// that navigate to it themselves; the cookie is set all the same, so the response must reach the browser
+func (h *OidcHandler) Login(c *gin.Context) {
+	login, err := h.oidcService.StartLogin(c, c.Param("provider"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	setStateCookie(c, login.RedirectURL, login.State, int(time.Until(login.ExpiresAt).Seconds()))
+
+	if c.Query("redirect") == "false" {
+		c.JSON(http.StatusOK, gin.H{
+			"auth_url":   login.AuthURL,
+			"expires_at": login.ExpiresAt,
+		})
+		return
+	}
+
+	c.Redirect(http.StatusFound, login.AuthURL)
+}
+
+// setStateCookie sets (or with maxAge < 0 clears) the HttpOnly state cookie scoped to the callback path.
+// SameSite=Lax still sends it on the top level redirect back from the provider
+func setStateCookie(c *gin.Context, redirectURL, value string, maxAge int) {
+	path, secure := "/", c.Request.TLS != nil
+	if callback, err := url.Parse(redirectURL); err == nil {
+		if callback.Path != "" {
+			path = callback.Path
+		}
+		secure = secure || callback.Scheme == "https"
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, path, "", secure, true)
+}
+
+// Callback is the redirect URL registered at the provider. It answers like POST /auth/login
+func (h *OidcHandler) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             "Sign in was not completed at the provider",
+			"provider_error":    providerError,
+			"error_description": c.Query("error_description"),
+		})
+		return
+	}
+
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state and code are required"})
+		return
+	}
+
+	redirectURL, err := h.oidcService.RedirectURL(c.Param("provider"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	browserState, _ := c.Cookie(oidcStateCookie)
+	// the state is single use, whatever the outcome the cookie has served its purpose
+	setStateCookie(c, redirectURL, "", -1)
+
+	result, err := h.oidcService.Callback(c, c.Param("provider"), state, browserState, code, acceptedLocale(c), c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	loginResponse(c, result)
+}
+
+func (h *OidcHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, oidc.ErrUnknownProvider):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown provider"})
+	case errors.Is(err, services.ErrInvalidOidcState):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login state is invalid, expired or already used, start again"})
+	case errors.Is(err, oidc.ErrInvalidIDToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Provider returned an invalid ID token"})
+	case errors.Is(err, oidc.ErrProviderFailed):
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Provider request failed: " + err.Error()})
+	case errors.Is(err, services.ErrOidcEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": "The provider account has no verified email address"})
+	case errors.Is(err, services.ErrOidcEmailConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email exists, verify its email address before signing in with a provider"})
+	case errors.Is(err, services.ErrUserInactive):
+		c.JSON(http.StatusForbidden, gin.H{"error": "User account is inactive"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed: " + err.Error()})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/models"
+	"net/http"
+)
+
+// PopularityHandler handles HTTP requests for engagement events and score recomputation
+type PopularityHandler struct {
+	popularityService *services.PopularityService
+}
+
+// NewPopularityHandler creates a new Popularity handler
+func NewPopularityHandler(popularityService *services.PopularityService) *PopularityHandler {
+	return &PopularityHandler{
+		popularityService: popularityService,
+	}
+}
+
+// RecordEvent ingests a view. The user is taken from the token when one is sent, anonymous views are
+// told apart by IP address. Reviews are recorded by the server, clients cannot send them
+func (h *PopularityHandler) RecordEvent(c *gin.Context) {
+	var body struct {
+		Type    string    `json:"type" binding:"omitempty"` // view, the only type clients may send
+		MovieID uuid.UUID `json:"movieId" binding:"required"`
+		Country string    `json:"country" binding:"omitempty"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	if body.Type != "" && body.Type != models.ViewEvent {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only view events are accepted, other events are recorded by the server"})
+		return
+	}
+
+	var userID *uuid.UUID
+	if value, exists := c.Get("userID"); exists {
+		if id, ok := value.(uuid.UUID); ok && id != uuid.Nil {
+			userID = &id
+		}
+	}
+
+	event, err := h.popularityService.RecordView(c, body.MovieID, userID, c.ClientIP(), body.Country)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTooManyEvents):
+			c.Header("Retry-After", "60")
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many events, try again later"})
+		case errors.Is(err, services.ErrUnknownCountry):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record event: " + err.Error()})
+		}
+		return
+	}
+
+	if event == nil {
+		c.JSON(http.StatusAccepted, gin.H{"message": "View already counted"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, event)
+}
+
+// RecomputePopularity rebuilds the trending and popularity scores without waiting for the background job
+func (h *PopularityHandler) RecomputePopularity(c *gin.Context) {
+	if err := h.popularityService.Recompute(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute popularity: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Popularity recomputed successfully"})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/services"
+	"net/http"
+	"strconv"
+)
+
+// RecommendationHandler handles HTTP requests for personal recommendations
+type RecommendationHandler struct {
+	recommendationService *services.RecommendationService
+}
+
+// NewRecommendationHandler creates a new Recommendation handler
+func NewRecommendationHandler(recommendationService *services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationService: recommendationService,
+	}
+}
+
+// GetRecommendations returns the current user's recommendations, ?limit= overrides the configured size
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	recommendations, err := h.recommendationService.GetRecommendations(c, userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recommendations: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": recommendations})
+}
+
+// RecomputeRecommendations rebuilds the co-occurrence model without waiting for the background job
+func (h *RecommendationHandler) RecomputeRecommendations(c *gin.Context) {
+	if err := h.recommendationService.Recompute(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute recommendations: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recommendations recomputed successfully"})
+}
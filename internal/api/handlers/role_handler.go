@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"itv-movie/internal/api/services"
+	"net/http"
+)
+
+// RoleHandler handles the admin endpoints that edit roles, their permissions and the role of users
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+type roleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// GetPermissions lists every permission a role can grant
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	permissions, err := h.roleService.GetPermissions(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": permissions})
+}
+
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	roles, err := h.roleService.GetAll(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": roles})
+}
+
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.roleService.GetByName(c, c.Param("name"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var createRequest roleRequest
+	if err := c.BindJSON(&createRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	role, err := h.roleService.Create(c, services.RoleRequest{
+		Name:        createRequest.Name,
+		Description: createRequest.Description,
+		Permissions: createRequest.Permissions,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole replaces the description and the permissions of a role, the name cannot change
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var updateRequest roleRequest
+	if err := c.BindJSON(&updateRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	role, err := h.roleService.Update(c, c.Param("name"), services.RoleRequest{
+		Description: updateRequest.Description,
+		Permissions: updateRequest.Permissions,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.roleService.Delete(c, c.Param("name")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// AssignRole changes the role of a user, who is signed out everywhere
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	var assignRequest struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err = c.BindJSON(&assignRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	if err = h.roleService.AssignRole(c, userID, assignRequest.Role); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User role updated successfully"})
+}
+
+func (h *RoleHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, services.ErrRoleExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Role with this name already exists"})
+	case errors.Is(err, services.ErrRoleInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Role is assigned to users, give them another role first"})
+	case errors.Is(err, services.ErrRoleProtected), errors.Is(err, services.ErrRoleSystem), errors.Is(err, services.ErrLastAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidRoleData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Role operation failed: " + err.Error()})
+	}
+}
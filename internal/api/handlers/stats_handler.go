@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
+	"itv-movie/internal/storage/database/repositories"
+	"net/http"
+	"time"
+)
+
+// StatsHandler handles HTTP requests for catalog reports
+type StatsHandler struct {
+	statsService *services.StatsService
+}
+
+// NewStatsHandler creates a new Stats handler
+func NewStatsHandler(statsService *services.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+	}
+}
+
+// GetReport runs a catalog report over movies added between ?from= and ?to= (YYYY-MM-DD, both inclusive).
+// ?format=csv downloads it as CSV instead of JSON
+func (h *StatsHandler) GetReport(c *gin.Context) {
+	var rng repositories.StatsRange
+
+	if from := c.Query("from"); from != "" {
+		date, err := time.Parse(constants.DateFormat, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date format. Use YYYY-MM-DD"})
+			return
+		}
+		rng.From = &date
+	}
+	if to := c.Query("to"); to != "" {
+		date, err := time.Parse(constants.DateFormat, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date format. Use YYYY-MM-DD"})
+			return
+		}
+		// include the whole last day
+		date = date.AddDate(0, 0, 1)
+		rng.To = &date
+	}
+
+	report, err := h.statsService.Report(c, c.Param("report"), rng)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownReport):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report: " + err.Error()})
+		}
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+report.Report+`.csv"`)
+		c.Status(http.StatusOK)
+		if err = report.WriteCSV(c.Writer); err != nil {
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/api/services"
+	"net/http"
+	"strconv"
+)
+
+// TagHandler handles HTTP requests for movie tags
+type TagHandler struct {
+	tagService *services.TagService
+}
+
+// NewTagHandler creates a new Tag handler
+func NewTagHandler(tagService *services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// Autocomplete suggests tags for ?q=, ?limit= caps the suggestions
+func (h *TagHandler) Autocomplete(c *gin.Context) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	tags, err := h.tagService.Autocomplete(c, c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// GetTagCloud returns the most used tags with their movie counts
+func (h *TagHandler) GetTagCloud(c *gin.Context) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	tags, err := h.tagService.GetTagCloud(c, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tag cloud: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID format"})
+		return
+	}
+
+	var body struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	if err = c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.RenameTag(c, id, body.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTagName):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTagNameTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename tag: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// MergeTags folds the duplicate tags into the survivor, their movies keep the survivor
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	var body struct {
+		SurvivorID   uuid.UUID   `json:"survivorId" binding:"required"`
+		DuplicateIDs []uuid.UUID `json:"duplicateIds" binding:"required,min=1"`
+	}
+
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.MergeTags(c, body.SurvivorID, body.DuplicateIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTagMerge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survivor or duplicate tag not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge tags: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"itv-movie/internal/api/services"
@@ -8,8 +9,16 @@ import (
 	"net/http"
 )
 
+// apiKeyHeader carries the API keys of services, in place of a bearer token
+const apiKeyHeader = "X-API-Key"
+
 func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := c.GetHeader(apiKeyHeader); rawKey != "" {
+			authenticateApiKey(c, authService, rawKey)
+			return
+		}
+
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -41,36 +50,69 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
 		c.Set("accessToken", tokenString)
+		c.Set("tokenID", claims.ID)
+
+		if !setPermissions(c, authService, claims.Role) {
+			return
+		}
 
 		c.Next()
 	}
 }
 
-// RoleMiddleware checks if the user has a specific role
-func RoleMiddleware(roles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userRole, exists := c.Get("role")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User role not found in context"})
-			c.Abort()
-			return
+// authenticateApiKey sets the user info of the key owner, the same values the token path sets
+func authenticateApiKey(c *gin.Context, authService *services.AuthService, rawKey string) {
+	key, err := authService.ValidateApiKey(c, rawKey, c.Request.Method)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidApiKey):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid, expired or revoked API key"})
+		case errors.Is(err, services.ErrApiKeyScope):
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key does not have the scope for this request"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
 		}
+		c.Abort()
+		return
+	}
 
-		// Check if user's role is in the allowed roles
-		roleMatched := false
-		for _, role := range roles {
-			if role == userRole.(string) {
-				roleMatched = true
-				break
-			}
-		}
+	c.Set("userID", key.UserID)
+	c.Set("username", key.User.Username)
+	c.Set("email", key.User.Email)
+	c.Set("role", key.User.Role)
+	c.Set("apiKeyID", key.ID)
 
-		if !roleMatched {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to access this resource"})
-			c.Abort()
+	if !setPermissions(c, authService, key.User.Role) {
+		return
+	}
+
+	c.Next()
+}
+
+// setPermissions puts the permissions of the role in the context for RequirePermission
+func setPermissions(c *gin.Context, authService *services.AuthService, role string) bool {
+	permissions, err := authService.RolePermissions(c, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load permissions"})
+		c.Abort()
+		return false
+	}
+
+	c.Set("permissions", permissions)
+	return true
+}
+
+// OptionalAuthMiddleware sets the user info like AuthMiddleware when an Authorization or X-API-Key header
+// is sent and lets anonymous requests through
+func OptionalAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
+	authenticate := AuthMiddleware(authService)
+
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && c.GetHeader(apiKeyHeader) == "" {
+			c.Next()
 			return
 		}
 
-		c.Next()
+		authenticate(c)
 	}
 }
@@ -2,20 +2,40 @@ package middlewares
 
 import (
 	"github.com/gin-gonic/gin"
-	"itv-movie/internal/pkg/utils/constants"
+	"net/http"
 )
 
-// AdminOnly middleware ensures the user is an admin
-func AdminOnly() gin.HandlerFunc {
-	return RoleMiddleware(constants.AdminRole)
-}
+// RequirePermission lets the request through when the role of the user grants the permission, e.g.
+// RequirePermission(constants.MovieUpdate). It runs after AuthMiddleware, which loads the permissions
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("permissions"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User permissions not found in context"})
+			c.Abort()
+			return
+		}
+
+		if HasPermission(c, permission) {
+			c.Next()
+			return
+		}
 
-// AdminOrDirectorOnly checks if the user is either an admin or a director
-func AdminOrDirectorOnly() gin.HandlerFunc {
-	return RoleMiddleware(constants.AdminRole, constants.DirectorRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to access this resource"})
+		c.Abort()
+	}
 }
 
-// DirectorOnly ensures the user is a director
-func DirectorOnly() gin.HandlerFunc {
-	return RoleMiddleware(constants.DirectorRole)
+// HasPermission reports whether the role of the authenticated user grants the permission,
+// false for anonymous requests. Handlers behind OptionalAuthMiddleware use it to widen their answers
+func HasPermission(c *gin.Context, permission string) bool {
+	value, _ := c.Get("permissions")
+	permissions, _ := value.([]string)
+
+	for _, granted := range permissions {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,26 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/handlers"
+	"itv-movie/internal/api/middlewares"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
+)
+
+func RegisterAdminRoutes(r *gin.RouterGroup, moviesHandler *handlers.MovieHandler, tagHandler *handlers.TagHandler, statsHandler *handlers.StatsHandler, recommendationHandler *handlers.RecommendationHandler, popularityHandler *handlers.PopularityHandler, authService *services.AuthService) {
+	admin := r.Group("/admin")
+	admin.Use(middlewares.AuthMiddleware(authService))
+	{
+		admin.GET("/movies/duplicates", middlewares.RequirePermission(constants.MovieMerge), moviesHandler.FindDuplicates)
+		admin.POST("/movies/merge", middlewares.RequirePermission(constants.MovieMerge), moviesHandler.MergeMovies)
+
+		admin.PUT("/tags/:id", middlewares.RequirePermission(constants.TagManage), tagHandler.RenameTag)
+		admin.POST("/tags/merge", middlewares.RequirePermission(constants.TagManage), tagHandler.MergeTags)
+
+		admin.GET("/stats/:report", middlewares.RequirePermission(constants.StatsRead), statsHandler.GetReport)
+
+		admin.POST("/recommendations/recompute", middlewares.RequirePermission(constants.JobsRun), recommendationHandler.RecomputeRecommendations)
+		admin.POST("/popularity/recompute", middlewares.RequirePermission(constants.JobsRun), popularityHandler.RecomputePopularity)
+	}
+}
@@ -5,9 +5,20 @@ import (
 	"itv-movie/internal/api/handlers"
 	"itv-movie/internal/api/middlewares"
 	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
 )
 
-func RegisterAuthRoutes(router *gin.RouterGroup, authHandler *handlers.AuthHandler, authService *services.AuthService) {
+func RegisterAuthRoutes(
+	router *gin.RouterGroup,
+	authHandler *handlers.AuthHandler,
+	sessionHandler *handlers.SessionHandler,
+	accountHandler *handlers.AccountHandler,
+	mfaHandler *handlers.MfaHandler,
+	oidcHandler *handlers.OidcHandler,
+	apiKeyHandler *handlers.ApiKeyHandler,
+	roleHandler *handlers.RoleHandler,
+	authService *services.AuthService,
+) {
 	auth := router.Group("/auth")
 	{
 		// Public routes
@@ -15,6 +26,16 @@ func RegisterAuthRoutes(router *gin.RouterGroup, authHandler *handlers.AuthHandl
 		auth.POST("/register-admin", authHandler.RegisterAdmin)
 		auth.POST("/login", authHandler.Login)
 		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/verify-email", accountHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", accountHandler.ResendVerification)
+		auth.POST("/forgot-password", accountHandler.ForgotPassword)
+		auth.POST("/reset-password", accountHandler.ResetPassword)
+		auth.POST("/mfa/verify", mfaHandler.Verify)
+		auth.POST("/mfa/enroll", mfaHandler.StartLoginEnrollment)
+		auth.POST("/mfa/enroll/confirm", mfaHandler.ConfirmLoginEnrollment)
+		auth.GET("/oidc/providers", oidcHandler.GetProviders)
+		auth.GET("/oidc/:provider/login", oidcHandler.Login)
+		auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
 
 		// Protected routes
 		auth.Use(middlewares.AuthMiddleware(authService))
@@ -22,10 +43,34 @@ func RegisterAuthRoutes(router *gin.RouterGroup, authHandler *handlers.AuthHandl
 
 		// Admin routes
 		admin := auth.Group("/admin")
-		admin.Use(middlewares.AdminOnly())
-		admin.POST("/register-director", authHandler.RegisterDirector)
-		admin.GET("/users", authHandler.GetAllUsers)
-		admin.PUT("/status", authHandler.UpdateStatus)
-		admin.DELETE("/users/:id", authHandler.DeleteUser)
+
+		users := admin.Group("")
+		users.Use(middlewares.RequirePermission(constants.UserManage))
+		users.GET("/users", authHandler.GetAllUsers)
+		users.PUT("/status", authHandler.UpdateStatus)
+		users.DELETE("/users/:id", authHandler.DeleteUser)
+		users.GET("/users/:id/sessions", sessionHandler.GetUserSessions)
+		users.DELETE("/users/:id/sessions/:sessionId", sessionHandler.RevokeUserSession)
+		users.GET("/security-events", authHandler.GetSecurityEvents)
+		users.GET("/lockouts", authHandler.GetLoginLockouts)
+		users.POST("/unlock", authHandler.UnlockLogin)
+
+		apiKeys := admin.Group("/api-keys")
+		apiKeys.Use(middlewares.RequirePermission(constants.ApiKeyManage))
+		apiKeys.GET("", apiKeyHandler.GetAll)
+		apiKeys.POST("", apiKeyHandler.Create)
+		apiKeys.DELETE("/:id", apiKeyHandler.Revoke)
+
+		// granting roles can grant any permission, so it needs role:manage rather than user:manage
+		roles := admin.Group("")
+		roles.Use(middlewares.RequirePermission(constants.RoleManage))
+		roles.POST("/register-director", authHandler.RegisterDirector)
+		roles.PUT("/users/:id/role", roleHandler.AssignRole)
+		roles.GET("/permissions", roleHandler.GetPermissions)
+		roles.GET("/roles", roleHandler.GetRoles)
+		roles.GET("/roles/:name", roleHandler.GetRole)
+		roles.POST("/roles", roleHandler.CreateRole)
+		roles.PUT("/roles/:name", roleHandler.UpdateRole)
+		roles.DELETE("/roles/:name", roleHandler.DeleteRole)
 	}
 }
@@ -0,0 +1,42 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/handlers"
+	"itv-movie/internal/api/middlewares"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
+)
+
+func RegisterAwardRoutes(r *gin.RouterGroup, handler *handlers.AwardHandler, authService *services.AuthService) {
+	awards := r.Group("/awards")
+	{
+		awards.GET("", handler.GetAllAwards)
+		awards.GET("/:id", handler.GetAward)
+
+		restricted := awards.Group("")
+		restricted.Use(middlewares.AuthMiddleware(authService))
+		{
+			restricted.POST("", middlewares.RequirePermission(constants.AwardCreate), handler.CreateAward)
+			restricted.PUT("/:id", middlewares.RequirePermission(constants.AwardUpdate), handler.UpdateAward)
+			restricted.DELETE("/:id", middlewares.RequirePermission(constants.AwardDelete), handler.DeleteAward)
+
+			restricted.POST("/:id/categories", middlewares.RequirePermission(constants.AwardCreate), handler.CreateCategory)
+			restricted.PUT("/:id/categories/:categoryId", middlewares.RequirePermission(constants.AwardUpdate), handler.UpdateCategory)
+			restricted.DELETE("/:id/categories/:categoryId", middlewares.RequirePermission(constants.AwardDelete), handler.DeleteCategory)
+		}
+	}
+
+	nominations := r.Group("/movies/:id/nominations")
+	{
+		nominations.GET("", handler.GetMovieNominations)
+
+		restricted := nominations.Group("")
+		restricted.Use(middlewares.AuthMiddleware(authService))
+		{
+			restricted.POST("", middlewares.RequirePermission(constants.AwardCreate), handler.CreateNomination)
+			restricted.PUT("/:nominationId", middlewares.RequirePermission(constants.AwardUpdate), handler.UpdateNomination)
+			restricted.DELETE("/:nominationId", middlewares.RequirePermission(constants.AwardDelete), handler.DeleteNomination)
+		}
+	}
+}
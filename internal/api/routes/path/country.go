@@ -5,6 +5,7 @@ import (
 	"itv-movie/internal/api/handlers"
 	"itv-movie/internal/api/middlewares"
 	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
 )
 
 func RegisterCountryRoutes(r *gin.RouterGroup, handler *handlers.CountryHandler, authService *services.AuthService) {
@@ -15,11 +16,10 @@ func RegisterCountryRoutes(r *gin.RouterGroup, handler *handlers.CountryHandler,
 
 		restricted := countries.Group("")
 		restricted.Use(middlewares.AuthMiddleware(authService))
-		restricted.Use(middlewares.AdminOrDirectorOnly())
 		{
-			restricted.POST("", handler.CreateCountry)
-			restricted.PUT("/:id", handler.UpdateCountry)
-			restricted.DELETE("/:id", handler.DeleteCountry)
+			restricted.POST("", middlewares.RequirePermission(constants.CountryCreate), handler.CreateCountry)
+			restricted.PUT("/:id", middlewares.RequirePermission(constants.CountryUpdate), handler.UpdateCountry)
+			restricted.DELETE("/:id", middlewares.RequirePermission(constants.CountryDelete), handler.DeleteCountry)
 		}
 	}
 }
@@ -0,0 +1,12 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/handlers"
+	"itv-movie/internal/api/middlewares"
+	"itv-movie/internal/api/services"
+)
+
+func RegisterEventRoutes(r *gin.RouterGroup, popularityHandler *handlers.PopularityHandler, authService *services.AuthService) {
+	r.POST("/events", middlewares.OptionalAuthMiddleware(authService), popularityHandler.RecordEvent)
+}
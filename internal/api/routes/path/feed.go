@@ -0,0 +1,13 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/handlers"
+)
+
+// RegisterFeedRoutes registers the public sitemap and feeds, they live at the site root rather than under /api/v1
+func RegisterFeedRoutes(r *gin.RouterGroup, handler *handlers.FeedHandler) {
+	r.GET("/sitemap.xml", handler.GetSitemap)
+	r.GET("/sitemaps/:file", handler.GetSitemapPage)
+	r.GET("/feeds/movies.atom", handler.GetMovieFeed)
+}
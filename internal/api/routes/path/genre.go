@@ -5,21 +5,22 @@ import (
 	"itv-movie/internal/api/handlers"
 	"itv-movie/internal/api/middlewares"
 	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
 )
 
 func RegisterGenreRoutes(r *gin.RouterGroup, handler *handlers.GenreHandler, authService *services.AuthService) {
 	genres := r.Group("/genres")
 	{
 		genres.GET("", handler.GetAllGenres)
+		genres.GET("/tree", handler.GetGenreTree)
 		genres.GET("/:id", handler.GetGenre)
 
 		restricted := genres.Group("")
 		restricted.Use(middlewares.AuthMiddleware(authService))
-		restricted.Use(middlewares.AdminOrDirectorOnly())
 		{
-			restricted.POST("", handler.CreateGenre)
-			restricted.PUT("/:id", handler.UpdateGenre)
-			restricted.DELETE("/:id", handler.DeleteGenre)
+			restricted.POST("", middlewares.RequirePermission(constants.GenreCreate), handler.CreateGenre)
+			restricted.PUT("/:id", middlewares.RequirePermission(constants.GenreUpdate), handler.UpdateGenre)
+			restricted.DELETE("/:id", middlewares.RequirePermission(constants.GenreDelete), handler.DeleteGenre)
 		}
 	}
 }
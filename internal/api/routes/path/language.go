@@ -5,6 +5,7 @@ import (
 	"itv-movie/internal/api/handlers"
 	"itv-movie/internal/api/middlewares"
 	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
 )
 
 func RegisterLanguageRoutes(r *gin.RouterGroup, handler *handlers.LanguageHandler, authService *services.AuthService) {
@@ -15,11 +16,10 @@ func RegisterLanguageRoutes(r *gin.RouterGroup, handler *handlers.LanguageHandle
 
 		restricted := languages.Group("")
 		restricted.Use(middlewares.AuthMiddleware(authService))
-		restricted.Use(middlewares.AdminOrDirectorOnly())
 		{
-			restricted.POST("", handler.CreateLanguage)
-			restricted.PUT("/:id", handler.UpdateLanguage)
-			restricted.DELETE("/:id", handler.DeleteLanguage)
+			restricted.POST("", middlewares.RequirePermission(constants.LanguageCreate), handler.CreateLanguage)
+			restricted.PUT("/:id", middlewares.RequirePermission(constants.LanguageUpdate), handler.UpdateLanguage)
+			restricted.DELETE("/:id", middlewares.RequirePermission(constants.LanguageDelete), handler.DeleteLanguage)
 		}
 	}
 }
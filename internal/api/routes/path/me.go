@@ -0,0 +1,44 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/handlers"
+	"itv-movie/internal/api/middlewares"
+	"itv-movie/internal/api/services"
+)
+
+// RegisterMeRoutes registers the routes of the signed in user
+func RegisterMeRoutes(
+	r *gin.RouterGroup,
+	activityHandler *handlers.ActivityHandler,
+	recommendationHandler *handlers.RecommendationHandler,
+	sessionHandler *handlers.SessionHandler,
+	mfaHandler *handlers.MfaHandler,
+	authService *services.AuthService,
+) {
+	me := r.Group("/me")
+	me.Use(middlewares.AuthMiddleware(authService))
+	{
+		me.GET("/history", activityHandler.GetHistory)
+		me.POST("/history", activityHandler.AddWatch)
+
+		me.GET("/ratings", activityHandler.GetRatings)
+		me.PUT("/ratings/:movieId", activityHandler.RateMovie)
+		me.DELETE("/ratings/:movieId", activityHandler.DeleteRating)
+
+		me.GET("/preferences", activityHandler.GetPreferences)
+		me.PUT("/preferences", activityHandler.UpdatePreferences)
+
+		me.GET("/recommendations", recommendationHandler.GetRecommendations)
+
+		me.GET("/sessions", sessionHandler.GetMySessions)
+		me.DELETE("/sessions", sessionHandler.RevokeMyOtherSessions)
+		me.DELETE("/sessions/:id", sessionHandler.RevokeMySession)
+
+		me.GET("/mfa", mfaHandler.GetStatus)
+		me.POST("/mfa/totp", mfaHandler.StartEnrollment)
+		me.POST("/mfa/totp/confirm", mfaHandler.ConfirmEnrollment)
+		me.DELETE("/mfa/totp", mfaHandler.Disable)
+		me.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+	}
+}
@@ -0,0 +1,25 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/handlers"
+	"itv-movie/internal/api/middlewares"
+	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
+)
+
+func RegisterMediaRoutes(r *gin.RouterGroup, handler *handlers.MediaHandler, authService *services.AuthService) {
+	r.GET("/media/:id/:variant", handler.ServeMedia)
+
+	movies := r.Group("/movies/:id/media")
+	{
+		movies.GET("", handler.GetMovieMedia)
+
+		restricted := movies.Group("")
+		restricted.Use(middlewares.AuthMiddleware(authService))
+		{
+			restricted.POST("", middlewares.RequirePermission(constants.MediaUpload), handler.UploadMovieMedia)
+			restricted.DELETE("/:assetId", middlewares.RequirePermission(constants.MediaDelete), handler.DeleteMovieMedia)
+		}
+	}
+}
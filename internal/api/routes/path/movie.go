@@ -5,21 +5,31 @@ import (
 	"itv-movie/internal/api/handlers"
 	"itv-movie/internal/api/middlewares"
 	"itv-movie/internal/api/services"
+	"itv-movie/internal/pkg/utils/constants"
 )
 
-func RegisterMovieRoutes(r *gin.RouterGroup, handler *handlers.MovieHandler, authService *services.AuthService) {
+func RegisterMovieRoutes(r *gin.RouterGroup, handler *handlers.MovieHandler, enrichmentHandler *handlers.EnrichmentHandler, authService *services.AuthService) {
 	movies := r.Group("/movies")
 	{
-		movies.GET("", handler.GetAllMovies)
-		movies.GET("/:id", handler.GetMovie)
+		// anonymous reads, editors signed in also see drafts
+		public := movies.Group("")
+		public.Use(middlewares.OptionalAuthMiddleware(authService))
+		{
+			public.GET("", handler.GetAllMovies)
+			public.GET("/:id", handler.GetMovie)
+			public.GET("/:id/trailer", handler.GetMovieTrailer)
+			public.GET("/:id/similar", handler.GetSimilarMovies)
+		}
 
 		restricted := movies.Group("")
 		restricted.Use(middlewares.AuthMiddleware(authService))
-		restricted.Use(middlewares.AdminOrDirectorOnly())
 		{
-			restricted.POST("", handler.CreateMovie)
-			restricted.PUT("/:id", handler.UpdateMovie)
-			restricted.DELETE("/:id", handler.DeleteMovie)
+			restricted.POST("", middlewares.RequirePermission(constants.MovieCreate), handler.CreateMovie)
+			restricted.PUT("/:id", middlewares.RequirePermission(constants.MovieUpdate), handler.UpdateMovie)
+			restricted.DELETE("/:id", middlewares.RequirePermission(constants.MovieDelete), handler.DeleteMovie)
+
+			restricted.GET("/:id/enrichment", middlewares.RequirePermission(constants.MovieUpdate), enrichmentHandler.ProposeEnrichment)
+			restricted.POST("/:id/enrichment", middlewares.RequirePermission(constants.MovieUpdate), enrichmentHandler.ApplyEnrichment)
 		}
 	}
 }
@@ -0,0 +1,14 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/handlers"
+)
+
+func RegisterTagRoutes(r *gin.RouterGroup, handler *handlers.TagHandler) {
+	tags := r.Group("/tags")
+	{
+		tags.GET("/autocomplete", handler.Autocomplete)
+		tags.GET("/cloud", handler.GetTagCloud)
+	}
+}
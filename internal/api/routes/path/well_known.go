@@ -0,0 +1,14 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"itv-movie/internal/api/handlers"
+)
+
+// RegisterWellKnownRoutes registers the /.well-known documents at the site root
+func RegisterWellKnownRoutes(r *gin.RouterGroup, authHandler *handlers.AuthHandler) {
+	wellKnown := r.Group("/.well-known")
+	{
+		wellKnown.GET("/jwks.json", authHandler.GetJWKS)
+	}
+}
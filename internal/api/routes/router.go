@@ -13,13 +13,18 @@ type Router struct {
 }
 
 // NewRouter creates a new router instance
-func NewRouter(cfg *config.Config) *Router {
+func NewRouter(cfg *config.Config) (*Router, error) {
 	if cfg.Env == config.ReleaseEnv {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	r := gin.New()
 
+	// the client IP feeds the login throttle, so forwarded headers count only from our own proxies
+	if err := r.SetTrustedProxies(cfg.Internal.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid server.trusted_proxies: %w", err)
+	}
+
 	// Middleware
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
@@ -27,7 +32,7 @@ func NewRouter(cfg *config.Config) *Router {
 	return &Router{
 		engine: r,
 		config: cfg,
-	}
+	}, nil
 }
 
 func (r *Router) Engine() *gin.Engine {
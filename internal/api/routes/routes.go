@@ -9,17 +9,41 @@ import (
 func RegisterRoutes(router *Router,
 	languageHandler *handlers.LanguageHandler,
 	genreHandler *handlers.GenreHandler,
+	tagHandler *handlers.TagHandler,
+	awardHandler *handlers.AwardHandler,
 	countriesHandler *handlers.CountryHandler,
 	moviesHandler *handlers.MovieHandler,
 	authHandler *handlers.AuthHandler,
+	sessionHandler *handlers.SessionHandler,
+	accountHandler *handlers.AccountHandler,
+	mfaHandler *handlers.MfaHandler,
+	oidcHandler *handlers.OidcHandler,
+	apiKeyHandler *handlers.ApiKeyHandler,
+	roleHandler *handlers.RoleHandler,
+	mediaHandler *handlers.MediaHandler,
+	enrichmentHandler *handlers.EnrichmentHandler,
+	feedHandler *handlers.FeedHandler,
+	statsHandler *handlers.StatsHandler,
+	activityHandler *handlers.ActivityHandler,
+	recommendationHandler *handlers.RecommendationHandler,
+	popularityHandler *handlers.PopularityHandler,
 	authService *services.AuthService,
 ) {
+	path.RegisterFeedRoutes(router.Engine().Group(""), feedHandler)
+	path.RegisterWellKnownRoutes(router.Engine().Group(""), authHandler)
+
 	api := router.Engine().Group("/api/v1")
 	{
 		path.RegisterLanguageRoutes(api, languageHandler, authService)
 		path.RegisterGenreRoutes(api, genreHandler, authService)
+		path.RegisterTagRoutes(api, tagHandler)
 		path.RegisterCountryRoutes(api, countriesHandler, authService)
-		path.RegisterMovieRoutes(api, moviesHandler, authService)
-		path.RegisterAuthRoutes(api, authHandler, authService)
+		path.RegisterMovieRoutes(api, moviesHandler, enrichmentHandler, authService)
+		path.RegisterAuthRoutes(api, authHandler, sessionHandler, accountHandler, mfaHandler, oidcHandler, apiKeyHandler, roleHandler, authService)
+		path.RegisterMediaRoutes(api, mediaHandler, authService)
+		path.RegisterAwardRoutes(api, awardHandler, authService)
+		path.RegisterAdminRoutes(api, moviesHandler, tagHandler, statsHandler, recommendationHandler, popularityHandler, authService)
+		path.RegisterMeRoutes(api, activityHandler, recommendationHandler, sessionHandler, mfaHandler, authService)
+		path.RegisterEventRoutes(api, popularityHandler, authService)
 	}
 }
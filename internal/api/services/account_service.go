@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/mailer"
+	"itv-movie/internal/pkg/utils/securetoken"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidAccountToken = errors.New("token is invalid, expired or already used")
+	ErrEmailNotVerified    = errors.New("email address is not verified")
+)
+
+// AccountService runs the emailed account flows: email verification and password reset
+type AccountService struct {
+	userRepo    *repositories.UserRepository
+	tokenRepo   *repositories.UserTokenRepository
+	sessionRepo *repositories.SessionRepository
+	mailer      mailer.Mailer
+	cfg         config.Accounts
+	log         *slog.Logger
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(
+	userRepo *repositories.UserRepository,
+	tokenRepo *repositories.UserTokenRepository,
+	sessionRepo *repositories.SessionRepository,
+	mailer mailer.Mailer,
+	cfg *config.Config,
+	log *slog.Logger,
+) *AccountService {
+	accountsCfg := cfg.Internal.Accounts
+	if accountsCfg.VerificationTTL < 1 {
+		accountsCfg.VerificationTTL = 48 * 3600
+	}
+	if accountsCfg.ResetTTL < 1 {
+		accountsCfg.ResetTTL = 3600
+	}
+
+	return &AccountService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		sessionRepo: sessionRepo,
+		mailer:      mailer,
+		cfg:         accountsCfg,
+		log:         log,
+	}
+}
+
+// RequiresVerifiedEmail reports whether logins are refused until the email address is verified
+func (s *AccountService) RequiresVerifiedEmail() bool {
+	return s.cfg.RequireVerifiedEmail
+}
+
+// SendVerification mails the user a link that confirms the email address
+func (s *AccountService) SendVerification(ctx context.Context, user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.send(ctx, user, models.EmailVerificationToken, mailer.VerifyEmailTemplate, s.cfg.VerifyURL, s.cfg.VerificationTTL)
+}
+
+// ResendVerification mails a new verification link. Unknown and already verified addresses are
+// silently ignored so the endpoint does not reveal which emails are registered
+func (s *AccountService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return s.SendVerification(ctx, user)
+}
+
+// VerifyEmail redeems a verification token
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.tokenRepo.Consume(ctx, models.EmailVerificationToken, securetoken.Hash(token))
+	if err != nil {
+		if isNotFound(err) {
+			return ErrInvalidAccountToken
+		}
+		return err
+	}
+	return s.userRepo.MarkEmailVerified(ctx, userID)
+}
+
+// RequestPasswordReset mails a reset link to an active user, unknown addresses are silently ignored
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !user.Active {
+		return nil
+	}
+	return s.send(ctx, user, models.PasswordResetToken, mailer.ResetPasswordTemplate, s.cfg.ResetURL, s.cfg.ResetTTL)
+}
+
+// ResetPassword redeems a reset token, sets the new password and signs the user out everywhere.
+// Receiving the link proves the inbox, so the email address counts as verified too
+func (s *AccountService) ResetPassword(ctx context.Context, token, password string) error {
+	userID, err := s.tokenRepo.Consume(ctx, models.PasswordResetToken, securetoken.Hash(token))
+	if err != nil {
+		if isNotFound(err) {
+			return ErrInvalidAccountToken
+		}
+		return err
+	}
+
+	passwordHash, err := models.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err = s.userRepo.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		return err
+	}
+	if err = s.userRepo.MarkEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAllForUser(ctx, userID)
+}
+
+// send stores a new token for the purpose and mails its link rendered from the template in the user's language
+func (s *AccountService) send(ctx context.Context, user *models.User, purpose, templateName, pageURL string, ttl int) error {
+	token, err := securetoken.Generate(32)
+	if err != nil {
+		return err
+	}
+
+	expiresIn := time.Duration(ttl) * time.Second
+	if err = s.tokenRepo.Replace(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: securetoken.Hash(token),
+		ExpiresAt: time.Now().Add(expiresIn),
+	}); err != nil {
+		return err
+	}
+
+	link, err := withToken(pageURL, token)
+	if err != nil {
+		return err
+	}
+
+	msg, err := mailer.Render(templateName, user.Locale, map[string]interface{}{
+		"Name":  strings.TrimSpace(user.FirstName + " " + user.LastName),
+		"Link":  link,
+		"Hours": max(int(expiresIn.Round(time.Hour).Hours()), 1),
+	})
+	if err != nil {
+		return err
+	}
+	msg.To = user.Email
+
+	if err = s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send %s email: %w", purpose, err)
+	}
+
+	s.log.Info("account email sent", "purpose", purpose, "user_id", user.ID)
+	return nil
+}
+
+func withToken(pageURL, token string) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
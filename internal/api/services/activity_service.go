@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrInvalidRatingScore = fmt.Errorf("rating score must be between %d and %d", models.MinRatingScore, models.MaxRatingScore)
+	ErrWatchedInFuture    = errors.New("watchedAt must not be in the future")
+	ErrUnknownGenre       = errors.New("genre not found")
+)
+
+// ActivityService handles what users watch, rate and prefer
+type ActivityService struct {
+	activityRepo *repositories.ActivityRepository
+	movieRepo    *repositories.MovieRepository
+	genreRepo    *repositories.GenreRepository
+	popularity   *PopularityService
+	log          *slog.Logger
+}
+
+// NewActivityService creates a new activity service
+func NewActivityService(
+	activityRepo *repositories.ActivityRepository,
+	movieRepo *repositories.MovieRepository,
+	genreRepo *repositories.GenreRepository,
+	popularity *PopularityService,
+	log *slog.Logger,
+) *ActivityService {
+	return &ActivityService{
+		activityRepo: activityRepo,
+		movieRepo:    movieRepo,
+		genreRepo:    genreRepo,
+		popularity:   popularity,
+		log:          log,
+	}
+}
+
+// AddWatch records that the user watched a movie, at watchedAt or now when it is nil
+func (s *ActivityService) AddWatch(ctx context.Context, userID, movieID uuid.UUID, watchedAt *time.Time) (*models.WatchHistory, error) {
+	at := time.Now()
+	if watchedAt != nil {
+		if watchedAt.After(at) {
+			return nil, ErrWatchedInFuture
+		}
+		at = *watchedAt
+	}
+
+	if _, err := s.movieRepo.GetByID(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	return s.activityRepo.AddWatch(ctx, userID, movieID, at)
+}
+
+func (s *ActivityService) GetHistory(ctx context.Context, userID uuid.UUID, page, limit int) ([]*models.WatchHistory, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	return s.activityRepo.GetHistory(ctx, userID, page, limit)
+}
+
+// RateMovie sets the user's own score of a movie, replacing an earlier one. The rating is the user's
+// review of the movie and counts towards its popularity
+func (s *ActivityService) RateMovie(ctx context.Context, userID, movieID uuid.UUID, score int) (*models.MovieRating, error) {
+	if score < models.MinRatingScore || score > models.MaxRatingScore {
+		return nil, ErrInvalidRatingScore
+	}
+
+	if _, err := s.movieRepo.GetByID(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	rating, err := s.activityRepo.RateMovie(ctx, userID, movieID, score)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rating is saved either way, a missed popularity signal is not worth failing it for
+	if err = s.popularity.RecordReview(ctx, userID, movieID); err != nil {
+		s.log.Error("failed to record review event", "movie_id", movieID, "error", err)
+	}
+
+	return rating, nil
+}
+
+func (s *ActivityService) DeleteRating(ctx context.Context, userID, movieID uuid.UUID) error {
+	return s.activityRepo.DeleteRating(ctx, userID, movieID)
+}
+
+func (s *ActivityService) GetRatings(ctx context.Context, userID uuid.UUID, page, limit int) ([]*models.MovieRating, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	return s.activityRepo.GetRatings(ctx, userID, page, limit)
+}
+
+func (s *ActivityService) GetPreferredGenres(ctx context.Context, userID uuid.UUID) ([]models.Genre, error) {
+	return s.activityRepo.GetPreferredGenres(ctx, userID)
+}
+
+// SetPreferredGenres replaces the user's preferred genres, given by name
+func (s *ActivityService) SetPreferredGenres(ctx context.Context, userID uuid.UUID, names []string) ([]models.Genre, error) {
+	ids := make([]uuid.UUID, 0, len(names))
+	for _, name := range names {
+		genre, err := s.genreRepo.GetByName(ctx, name)
+		if err != nil {
+			if isNotFound(err) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownGenre, name)
+			}
+			return nil, err
+		}
+		ids = append(ids, genre.ID)
+	}
+
+	if err := s.activityRepo.SetPreferredGenres(ctx, userID, ids); err != nil {
+		return nil, err
+	}
+
+	return s.activityRepo.GetPreferredGenres(ctx, userID)
+}
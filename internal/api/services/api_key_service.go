@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/utils/securetoken"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyPrefix = "itv_"
+	// apiKeyTouchInterval bounds how often last_used_at is written for a key
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidApiKey     = errors.New("API key is invalid, expired or revoked")
+	ErrApiKeyScope       = errors.New("API key does not have the scope for this request")
+	ErrApiKeyNotFound    = errors.New("API key not found")
+	ErrInvalidApiKeyData = errors.New("invalid API key data")
+)
+
+// ApiKeyRequest describes a key to issue. A nil ExpiresAt issues a key that does not expire
+type ApiKeyRequest struct {
+	Name      string
+	UserID    uuid.UUID
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// ApiKeyService issues API keys and authenticates the requests that carry one
+type ApiKeyService struct {
+	apiKeyRepo *repositories.ApiKeyRepository
+	userRepo   *repositories.UserRepository
+	log        *slog.Logger
+}
+
+// NewApiKeyService creates a new API key service
+func NewApiKeyService(apiKeyRepo *repositories.ApiKeyRepository, userRepo *repositories.UserRepository, log *slog.Logger) *ApiKeyService {
+	return &ApiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		log:        log,
+	}
+}
+
+// Create issues a key acting as the given user. The key itself is returned only here, it is stored hashed
+func (s *ApiKeyService) Create(ctx context.Context, request ApiKeyRequest, createdBy uuid.UUID) (*models.ApiKey, string, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidApiKeyData)
+	}
+
+	scopes, err := normalizeScopes(request.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidApiKeyData)
+	}
+
+	owner, err := s.userRepo.GetByID(ctx, request.UserID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, "", fmt.Errorf("%w: user not found", ErrInvalidApiKeyData)
+		}
+		return nil, "", err
+	}
+	if !owner.Active {
+		return nil, "", fmt.Errorf("%w: user is inactive", ErrInvalidApiKeyData)
+	}
+
+	prefix, err := securetoken.Generate(6)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, "", err
+	}
+	prefix = apiKeyPrefix + prefix
+	rawKey := prefix + "." + secret
+
+	key := &models.ApiKey{
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     securetoken.Hash(rawKey),
+		Scopes:      strings.Join(scopes, " "),
+		UserID:      owner.ID,
+		CreatedByID: createdBy,
+		ExpiresAt:   request.ExpiresAt,
+	}
+	if err = s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	owner.Password = ""
+	key.User = *owner
+	s.log.Info("API key issued", "api_key_id", key.ID, "prefix", prefix, "user_id", owner.ID, "created_by", createdBy)
+
+	return key, rawKey, nil
+}
+
+// normalizeScopes checks the scopes and removes duplicates, write implies nothing about read
+func normalizeScopes(scopes []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope != models.ReadScope && scope != models.WriteScope {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidApiKeyData, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidApiKeyData)
+	}
+	return normalized, nil
+}
+
+func (s *ApiKeyService) GetAll(ctx context.Context, page, limit int) ([]*models.ApiKey, int64, error) {
+	keys, total, err := s.apiKeyRepo.GetAll(ctx, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, key := range keys {
+		key.User.Password = ""
+	}
+	return keys, total, nil
+}
+
+// Revoke stops a key from working, revoking an already revoked key is not an error
+func (s *ApiKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	revoked, err := s.apiKeyRepo.Revoke(ctx, id)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		s.log.Info("API key revoked", "api_key_id", id)
+		return nil
+	}
+
+	exists, err := s.apiKeyRepo.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrApiKeyNotFound
+	}
+	return nil
+}
+
+// Authenticate returns the key of a request with the given method, with its owner
+func (s *ApiKeyService) Authenticate(ctx context.Context, rawKey, method string) (*models.ApiKey, error) {
+	key, err := s.apiKeyRepo.GetByHash(ctx, securetoken.Hash(strings.TrimSpace(rawKey)))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrInvalidApiKey
+		}
+		return nil, err
+	}
+
+	if !key.IsUsable() || !key.User.Active {
+		return nil, ErrInvalidApiKey
+	}
+
+	scope := models.WriteScope
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		scope = models.ReadScope
+	}
+	if !key.HasScope(scope) {
+		return nil, ErrApiKeyScope
+	}
+
+	// a failed bookkeeping write must not fail the request
+	if err = s.apiKeyRepo.TouchLastUsed(ctx, key.ID, apiKeyTouchInterval); err != nil {
+		s.log.Error("failed to record API key use", "api_key_id", key.ID, "error", err)
+	}
+
+	return key, nil
+}
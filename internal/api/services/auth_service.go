@@ -3,11 +3,14 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"itv-movie/internal/config"
 	"itv-movie/internal/models"
 	jwtpkg "itv-movie/internal/pkg/jwt"
 	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"time"
 )
 
 var (
@@ -17,19 +20,64 @@ var (
 	ErrSessionInvalid      = errors.New("session is invalid or expired")
 	ErrInvalidToken        = errors.New("invalid token format")
 	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+	ErrInvalidMfaToken     = errors.New("MFA token is invalid or expired")
 )
 
+// LoginResult carries the new session, or the MFA challenge to answer first when the user has a second factor
+type LoginResult struct {
+	User      *models.User
+	Session   *models.Session
+	Challenge *MfaChallenge
+}
+
+// MfaChallenge is the second step of a login, its token is exchanged together with a code for the session.
+// EnrollmentRequired means the role must use 2FA and the user has to enroll TOTP first
+type MfaChallenge struct {
+	Token              string
+	ExpiresAt          time.Time
+	EnrollmentRequired bool
+}
+
 type AuthService struct {
-	userRepo    *repositories.UserRepository
-	sessionRepo *repositories.SessionRepository
-	config      *config.Config
+	userRepo          *repositories.UserRepository
+	sessionRepo       *repositories.SessionRepository
+	securityEventRepo *repositories.SecurityEventRepository
+	loginThrottle     *LoginThrottleService
+	accounts          *AccountService
+	mfa               *MfaService
+	apiKeys           *ApiKeyService
+	roles             *RoleService
+	keys              *jwtpkg.KeySet
+	config            *config.Config
+	log               *slog.Logger
 }
 
-func NewAuthService(userRepo *repositories.UserRepository, sessionRepo *repositories.SessionRepository, config *config.Config) *AuthService {
+func NewAuthService(
+	userRepo *repositories.UserRepository,
+	sessionRepo *repositories.SessionRepository,
+	securityEventRepo *repositories.SecurityEventRepository,
+	loginThrottle *LoginThrottleService,
+	accounts *AccountService,
+	mfa *MfaService,
+	apiKeys *ApiKeyService,
+	roles *RoleService,
+	keys *jwtpkg.KeySet,
+	config *config.Config,
+	log *slog.Logger,
+) *AuthService {
 	return &AuthService{
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
-		config:      config,
+		userRepo:          userRepo,
+		sessionRepo:       sessionRepo,
+		securityEventRepo: securityEventRepo,
+		loginThrottle:     loginThrottle,
+		accounts:          accounts,
+		mfa:               mfa,
+		apiKeys:           apiKeys,
+		roles:             roles,
+		keys:              keys,
+		config:            config,
+		log:               log,
 	}
 }
 
@@ -53,33 +101,148 @@ func (s *AuthService) RegisterUser(ctx context.Context, userData *models.User) (
 		return nil, err
 	}
 
+	// the account exists either way, a new link can be requested when mailing fails
+	if err = s.accounts.SendVerification(ctx, user); err != nil {
+		s.log.Error("failed to send verification email", "user_id", user.ID, "error", err)
+	}
+
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, username, password, userAgent, ipAddress string) (*models.User, *models.Session, error) {
+// Login checks the credentials and opens a new session, or returns an MFA challenge when a second
+// factor is needed. It returns a *LoginBlockedError while the username or the IP address is throttled
+// after failed attempts
+func (s *AuthService) Login(ctx context.Context, username, password, userAgent, ipAddress string) (*LoginResult, error) {
+	if err := s.loginThrottle.Check(ctx, username, ipAddress); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByUsername(ctx, username)
 	if err != nil {
-		return nil, nil, ErrInvalidCredentials
+		return nil, s.loginFailed(ctx, username, ipAddress, userAgent)
 	}
 
 	if !user.Active {
-		return nil, nil, ErrUserInactive
+		return nil, ErrUserInactive
 	}
 
 	if !user.CheckPassword(password) {
-		return nil, nil, ErrInvalidCredentials
+		return nil, s.loginFailed(ctx, username, ipAddress, userAgent)
 	}
 
-	if err = s.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
-		return nil, nil, err
+	if err = s.loginThrottle.Succeed(ctx, username); err != nil {
+		return nil, err
+	}
+
+	if s.accounts.RequiresVerifiedEmail() && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
 	}
 
-	session, err := user.GenerateTokens(&s.config.Internal.Jwt, userAgent, ipAddress)
+	return s.completeLogin(ctx, user, userAgent, ipAddress)
+}
+
+// LoginExternal opens a session for a user authenticated by an external identity provider, the MFA
+// challenge still applies
+func (s *AuthService) LoginExternal(ctx context.Context, user *models.User, userAgent, ipAddress string) (*LoginResult, error) {
+	if !user.Active {
+		return nil, ErrUserInactive
+	}
+
+	return s.completeLogin(ctx, user, userAgent, ipAddress)
+}
+
+// completeLogin returns the MFA challenge when the user needs one and opens the session otherwise
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User, userAgent, ipAddress string) (*LoginResult, error) {
+	if user.TotpEnabledAt != nil || s.mfa.IsRequired(ctx, user) {
+		challenge, err := s.mfaChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{User: user, Challenge: challenge}, nil
+	}
+
+	session, err := s.openSession(ctx, user, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{User: user, Session: session}, nil
+}
+
+// openSession records the login and creates a session with new tokens
+func (s *AuthService) openSession(ctx context.Context, user *models.User, userAgent, ipAddress string) (*models.Session, error) {
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	session, err := user.GenerateTokens(s.keys, &s.config.Internal.Jwt, userAgent, ipAddress)
 	if err != nil {
+		return nil, err
+	}
+
+	return s.sessionRepo.Create(ctx, session)
+}
+
+// mfaChallenge issues the short lived token that stands for a correct password
+func (s *AuthService) mfaChallenge(user *models.User) (*MfaChallenge, error) {
+	token, _, expiresAt, err := jwtpkg.GenerateToken(user.ID.String(), user.Username, user.Email, user.Role, s.keys, &s.config.Internal.Jwt, jwtpkg.MfaToken, s.mfa.ChallengeTTL())
+	if err != nil {
+		return nil, err
+	}
+
+	return &MfaChallenge{
+		Token:              token,
+		ExpiresAt:          expiresAt,
+		EnrollmentRequired: user.TotpEnabledAt == nil,
+	}, nil
+}
+
+// userFromMfaToken returns the still active user an MFA token was issued to
+func (s *AuthService) userFromMfaToken(ctx context.Context, mfaToken string) (*models.User, error) {
+	claims, err := jwtpkg.ValidateToken(mfaToken, s.keys)
+	if err != nil || claims.TokenType != jwtpkg.MfaToken {
+		return nil, ErrInvalidMfaToken
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidMfaToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrInvalidMfaToken
+	}
+
+	if !user.Active {
+		return nil, ErrUserInactive
+	}
+
+	return user, nil
+}
+
+// VerifyMfa completes a login by exchanging the MFA token and a TOTP or recovery code for a session.
+// Wrong codes count as failed logins
+func (s *AuthService) VerifyMfa(ctx context.Context, mfaToken, code, userAgent, ipAddress string) (*models.User, *models.Session, error) {
+	user, err := s.userFromMfaToken(ctx, mfaToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = s.loginThrottle.Check(ctx, user.Username, ipAddress); err != nil {
 		return nil, nil, err
 	}
 
-	session, err = s.sessionRepo.Create(ctx, session)
+	if err = s.mfa.Verify(ctx, user, code); err != nil {
+		if errors.Is(err, ErrInvalidMfaCode) {
+			if err := s.loginThrottle.Fail(ctx, user.Username, ipAddress, userAgent); err != nil {
+				return nil, nil, err
+			}
+		}
+		return nil, nil, err
+	}
+
+	session, err := s.openSession(ctx, user, userAgent, ipAddress)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -87,8 +250,71 @@ func (s *AuthService) Login(ctx context.Context, username, password, userAgent,
 	return user, session, nil
 }
 
+// StartMfaEnrollment begins TOTP enrollment for a user who must use 2FA but has not enrolled yet
+func (s *AuthService) StartMfaEnrollment(ctx context.Context, mfaToken string) (*MfaEnrollment, error) {
+	user, err := s.userFromMfaToken(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mfa.StartEnrollment(ctx, user.ID)
+}
+
+// ConfirmMfaEnrollment confirms the enrollment started with the MFA token and completes the login,
+// the recovery codes are returned this once
+func (s *AuthService) ConfirmMfaEnrollment(ctx context.Context, mfaToken, code, userAgent, ipAddress string) (*models.User, *models.Session, []string, error) {
+	user, err := s.userFromMfaToken(ctx, mfaToken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err = s.loginThrottle.Check(ctx, user.Username, ipAddress); err != nil {
+		return nil, nil, nil, err
+	}
+
+	recoveryCodes, err := s.mfa.ConfirmEnrollment(ctx, user.ID, code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMfaCode) {
+			if err := s.loginThrottle.Fail(ctx, user.Username, ipAddress, userAgent); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		return nil, nil, nil, err
+	}
+
+	session, err := s.openSession(ctx, user, userAgent, ipAddress)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return user, session, recoveryCodes, nil
+}
+
+// loginFailed counts the failed attempt and returns ErrInvalidCredentials
+func (s *AuthService) loginFailed(ctx context.Context, username, ipAddress, userAgent string) error {
+	if err := s.loginThrottle.Fail(ctx, username, ipAddress, userAgent); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
+// GetLoginLockouts lists the usernames and IP addresses that may not log in right now
+func (s *AuthService) GetLoginLockouts(ctx context.Context, page, limit int) ([]*models.LoginThrottle, int64, error) {
+	return s.loginThrottle.GetBlocked(ctx, page, limit)
+}
+
+// UnlockLogin lifts the login lockout of a username and/or an IP address
+func (s *AuthService) UnlockLogin(ctx context.Context, username, ipAddress string) (int64, error) {
+	return s.loginThrottle.Unlock(ctx, username, ipAddress)
+}
+
 func (s *AuthService) Logout(ctx context.Context, accessToken string) error {
-	session, err := s.sessionRepo.GetByAccessToken(ctx, accessToken)
+	claims, err := jwtpkg.ValidateToken(accessToken, s.keys)
+	if err != nil || claims.TokenType != jwtpkg.AccessToken {
+		return ErrSessionInvalid
+	}
+
+	session, err := s.sessionRepo.GetByAccessJTI(ctx, claims.ID)
 	if err != nil {
 		return ErrSessionInvalid
 	}
@@ -97,7 +323,7 @@ func (s *AuthService) Logout(ctx context.Context, accessToken string) error {
 }
 
 func (s *AuthService) ValidateAccessToken(ctx context.Context, accessToken string) (*jwtpkg.CustomClaims, error) {
-	claims, err := jwtpkg.ValidateToken(accessToken, s.config.Internal.Jwt.Secret)
+	claims, err := jwtpkg.ValidateToken(accessToken, s.keys)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +332,7 @@ func (s *AuthService) ValidateAccessToken(ctx context.Context, accessToken strin
 		return nil, ErrInvalidToken
 	}
 
-	session, err := s.sessionRepo.GetByAccessToken(ctx, accessToken)
+	session, err := s.sessionRepo.GetByAccessJTI(ctx, claims.ID)
 	if err != nil || session.IsRevoked || !session.IsAccessTokenValid() {
 		return nil, ErrSessionInvalid
 	}
@@ -114,8 +340,23 @@ func (s *AuthService) ValidateAccessToken(ctx context.Context, accessToken strin
 	return claims, nil
 }
 
+// ValidateApiKey authenticates a request made with an API key, see ApiKeyService.Authenticate
+func (s *AuthService) ValidateApiKey(ctx context.Context, rawKey, method string) (*models.ApiKey, error) {
+	return s.apiKeys.Authenticate(ctx, rawKey, method)
+}
+
+// RolePermissions returns the permissions granted to a role, see RoleService.Permissions
+func (s *AuthService) RolePermissions(ctx context.Context, role string) ([]string, error) {
+	return s.roles.Permissions(ctx, role)
+}
+
+// JWKS returns the public keys that verify our tokens
+func (s *AuthService) JWKS() jwtpkg.JWKS {
+	return s.keys.JWKS()
+}
+
 func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken, userAgent, ipAddress string) (*models.Session, error) {
-	claims, err := jwtpkg.ValidateToken(refreshToken, s.config.Internal.Jwt.Secret)
+	claims, err := jwtpkg.ValidateToken(refreshToken, s.keys)
 	if err != nil {
 		if errors.Is(err, jwtpkg.ErrExpiredToken) {
 			return nil, ErrRefreshTokenExpired
@@ -127,11 +368,23 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken, userAgent
 		return nil, ErrInvalidToken
 	}
 
-	oldSession, err := s.sessionRepo.GetByRefreshToken(ctx, refreshToken)
+	oldSession, err := s.sessionRepo.FindByRefreshJTI(ctx, claims.ID)
 	if err != nil {
 		return nil, ErrSessionInvalid
 	}
 
+	if oldSession.IsRevoked {
+		// a token that was already exchanged is being replayed, it has likely leaked
+		rotated, err := s.sessionRepo.IsRotated(ctx, oldSession.ID)
+		if err != nil {
+			return nil, err
+		}
+		if rotated {
+			return nil, s.refreshTokenReused(ctx, oldSession, userAgent, ipAddress)
+		}
+		return nil, ErrSessionInvalid
+	}
+
 	if !oldSession.IsRefreshTokenValid() {
 		return nil, ErrRefreshTokenExpired
 	}
@@ -146,16 +399,22 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken, userAgent
 		return nil, err
 	}
 
-	err = s.sessionRepo.RevokeByID(ctx, oldSession.ID)
+	revoked, err := s.sessionRepo.RevokeIfActive(ctx, oldSession.ID)
 	if err != nil {
 		return nil, err
 	}
+	if !revoked {
+		// another request exchanged the same token in the meantime
+		return nil, s.refreshTokenReused(ctx, oldSession, userAgent, ipAddress)
+	}
 
 	// Generate new tokens
-	newSession, err := user.GenerateTokens(&s.config.Internal.Jwt, userAgent, ipAddress)
+	newSession, err := user.GenerateTokens(s.keys, &s.config.Internal.Jwt, userAgent, ipAddress)
 	if err != nil {
 		return nil, err
 	}
+	newSession.FamilyID = oldSession.FamilyID
+	newSession.RotatedFromID = &oldSession.ID
 
 	// Save the new session
 	newSession, err = s.sessionRepo.Create(ctx, newSession)
@@ -166,6 +425,48 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken, userAgent
 	return newSession, nil
 }
 
+// refreshTokenReused revokes every session of the token family and records a security event
+func (s *AuthService) refreshTokenReused(ctx context.Context, session *models.Session, userAgent, ipAddress string) error {
+	revoked, err := s.sessionRepo.RevokeFamily(ctx, session.FamilyID)
+	if err != nil {
+		return err
+	}
+
+	s.log.Warn("refresh token reuse detected, token family revoked",
+		"user_id", session.UserID,
+		"session_id", session.ID,
+		"family_id", session.FamilyID,
+		"revoked", revoked,
+		"ip", ipAddress,
+	)
+
+	event := &models.SecurityEvent{
+		UserID:    &session.UserID,
+		Type:      models.RefreshTokenReuseEvent,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Details:   fmt.Sprintf("session %s of family %s replayed, %d sessions revoked", session.ID, session.FamilyID, revoked),
+	}
+	if err = s.securityEventRepo.Create(ctx, event); err != nil {
+		s.log.Error("failed to record security event", "error", err)
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// GetSecurityEvents lists the recorded security events, newest first
+func (s *AuthService) GetSecurityEvents(ctx context.Context, filter repositories.SecurityEventFilter, page, limit int) ([]*models.SecurityEvent, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if limit < 1 || limit > 1000 {
+		limit = 10
+	}
+
+	return s.securityEventRepo.GetAll(ctx, filter, page, limit)
+}
+
 func (s *AuthService) UpdateUserStatus(ctx context.Context, userID uuid.UUID, active bool) error {
 	return s.userRepo.UpdateStatus(ctx, userID, active)
 }
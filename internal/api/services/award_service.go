@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database/repositories"
+	"strings"
+	"time"
+)
+
+// firstCeremonyYear is the year of the first Academy Awards ceremony, no earlier award is tracked
+const firstCeremonyYear = 1929
+
+var (
+	ErrAwardInUse           = errors.New("award has categories, delete them first")
+	ErrCategoryInUse        = errors.New("category has nominations, delete them first")
+	ErrCategoryNotInAward   = errors.New("category does not belong to this award")
+	ErrNominationNotOfMovie = errors.New("nomination does not belong to this movie")
+	ErrInvalidCeremonyYear  = errors.New("ceremony year is out of range")
+	ErrUnknownAwardCategory = errors.New("award category not found")
+	ErrAwardExists          = errors.New("award with this name already exists")
+	ErrCategoryExists       = errors.New("award already has a category with this name")
+	ErrNominationExists     = errors.New("movie already has this nomination")
+)
+
+// AwardService handles awards, their categories and the nominations of movies
+type AwardService struct {
+	awardRepo *repositories.AwardRepository
+	movieRepo *repositories.MovieRepository
+	similar   *SimilarService
+}
+
+// NewAwardService creates a new award service
+func NewAwardService(
+	awardRepo *repositories.AwardRepository,
+	movieRepo *repositories.MovieRepository,
+	similar *SimilarService,
+) *AwardService {
+	return &AwardService{
+		awardRepo: awardRepo,
+		movieRepo: movieRepo,
+		similar:   similar,
+	}
+}
+
+func (s *AwardService) CreateAward(ctx context.Context, award *models.Award) (*models.Award, error) {
+	award.Name = strings.TrimSpace(award.Name)
+	created, err := s.awardRepo.Create(ctx, award)
+	if isDuplicateKey(err) {
+		return nil, ErrAwardExists
+	}
+	return created, err
+}
+
+func (s *AwardService) GetAllAwards(ctx context.Context) ([]*models.Award, error) {
+	return s.awardRepo.GetAll(ctx)
+}
+
+func (s *AwardService) GetAward(ctx context.Context, id uuid.UUID) (*models.Award, error) {
+	return s.awardRepo.GetByID(ctx, id)
+}
+
+func (s *AwardService) UpdateAward(ctx context.Context, award *models.Award) (*models.Award, error) {
+	award.Name = strings.TrimSpace(award.Name)
+	updated, err := s.awardRepo.Update(ctx, award)
+	if isDuplicateKey(err) {
+		return nil, ErrAwardExists
+	}
+	return updated, err
+}
+
+// DeleteAward deletes an award without categories
+func (s *AwardService) DeleteAward(ctx context.Context, id uuid.UUID) error {
+	count, err := s.awardRepo.CountCategories(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAwardInUse
+	}
+
+	return s.awardRepo.Delete(ctx, id)
+}
+
+func (s *AwardService) CreateCategory(ctx context.Context, category *models.AwardCategory) (*models.AwardCategory, error) {
+	if _, err := s.awardRepo.GetByID(ctx, category.AwardID); err != nil {
+		return nil, err
+	}
+
+	category.Name = strings.TrimSpace(category.Name)
+	created, err := s.awardRepo.CreateCategory(ctx, category)
+	if isDuplicateKey(err) {
+		return nil, ErrCategoryExists
+	}
+	return created, err
+}
+
+// GetCategory finds a category of the given award
+func (s *AwardService) GetCategory(ctx context.Context, awardID, id uuid.UUID) (*models.AwardCategory, error) {
+	category, err := s.awardRepo.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category.AwardID != awardID {
+		return nil, ErrCategoryNotInAward
+	}
+
+	return category, nil
+}
+
+func (s *AwardService) UpdateCategory(ctx context.Context, category *models.AwardCategory) (*models.AwardCategory, error) {
+	category.Name = strings.TrimSpace(category.Name)
+	updated, err := s.awardRepo.UpdateCategory(ctx, category)
+	if isDuplicateKey(err) {
+		return nil, ErrCategoryExists
+	}
+	return updated, err
+}
+
+// DeleteCategory deletes a category without nominations
+func (s *AwardService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	count, err := s.awardRepo.CountNominations(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryInUse
+	}
+
+	return s.awardRepo.DeleteCategory(ctx, id)
+}
+
+func (s *AwardService) GetMovieNominations(ctx context.Context, movieID uuid.UUID) ([]*models.Nomination, error) {
+	if _, err := s.movieRepo.GetByID(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	return s.awardRepo.GetMovieNominations(ctx, movieID)
+}
+
+// GetNomination finds a nomination of the given movie
+func (s *AwardService) GetNomination(ctx context.Context, movieID, id uuid.UUID) (*models.Nomination, error) {
+	nomination, err := s.awardRepo.GetNomination(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if nomination.MovieID != movieID {
+		return nil, ErrNominationNotOfMovie
+	}
+
+	return nomination, nil
+}
+
+func (s *AwardService) CreateNomination(ctx context.Context, nomination *models.Nomination) (*models.Nomination, error) {
+	if _, err := s.movieRepo.GetByID(ctx, nomination.MovieID); err != nil {
+		return nil, err
+	}
+	if err := s.checkNomination(ctx, nomination); err != nil {
+		return nil, err
+	}
+
+	created, err := s.awardRepo.CreateNomination(ctx, nomination)
+	if err != nil {
+		if isDuplicateKey(err) {
+			return nil, ErrNominationExists
+		}
+		return nil, err
+	}
+	s.similar.Invalidate(nomination.MovieID)
+
+	return created, nil
+}
+
+func (s *AwardService) UpdateNomination(ctx context.Context, nomination *models.Nomination) (*models.Nomination, error) {
+	if err := s.checkNomination(ctx, nomination); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.awardRepo.UpdateNomination(ctx, nomination)
+	if err != nil {
+		if isDuplicateKey(err) {
+			return nil, ErrNominationExists
+		}
+		return nil, err
+	}
+	s.similar.Invalidate(nomination.MovieID)
+
+	return updated, nil
+}
+
+func (s *AwardService) DeleteNomination(ctx context.Context, nomination *models.Nomination) error {
+	if err := s.awardRepo.DeleteNomination(ctx, nomination.ID); err != nil {
+		return err
+	}
+	s.similar.Invalidate(nomination.MovieID)
+
+	return nil
+}
+
+// SummarizeAwards fills the award summary of a movie
+func (s *AwardService) SummarizeAwards(ctx context.Context, movie *models.Movie) error {
+	summary, err := s.awardRepo.Summary(ctx, movie.ID)
+	if err != nil {
+		return err
+	}
+
+	movie.Awards = summary
+	return nil
+}
+
+// checkNomination validates the ceremony year and that the category exists
+func (s *AwardService) checkNomination(ctx context.Context, nomination *models.Nomination) error {
+	if nomination.Year < firstCeremonyYear || nomination.Year > time.Now().Year()+1 {
+		return ErrInvalidCeremonyYear
+	}
+
+	if _, err := s.awardRepo.GetCategory(ctx, nomination.CategoryID); err != nil {
+		if isNotFound(err) {
+			return ErrUnknownAwardCategory
+		}
+		return err
+	}
+
+	nomination.Person = strings.TrimSpace(nomination.Person)
+	return nil
+}
@@ -10,18 +10,27 @@ import (
 // CountryService handles business logic for country
 type CountryService struct {
 	countryRepo *repositories.CountryRepository
+	slugService *SlugService
 }
 
 // NewCountryService creates a new country service
 func NewCountryService(
 	countryRepo *repositories.CountryRepository,
+	slugService *SlugService,
 ) *CountryService {
 	return &CountryService{
 		countryRepo: countryRepo,
+		slugService: slugService,
 	}
 }
 
 func (s *CountryService) CreateCountry(ctx context.Context, country *models.Country) (*models.Country, error) {
+	slug, err := s.slugService.Generate(ctx, models.CountrySlugEntity, country.Name, country.ID)
+	if err != nil {
+		return nil, err
+	}
+	country.Slug = slug
+
 	createdCountry, err := s.countryRepo.Create(ctx, country)
 	if err != nil {
 		return nil, err
@@ -55,12 +64,38 @@ func (s *CountryService) GetCountry(ctx context.Context, id uuid.UUID) (*models.
 	return country, nil
 }
 
+// GetCountryBySlug finds a country by its current slug or an old one, currentSlug is set when the slug is outdated
+func (s *CountryService) GetCountryBySlug(ctx context.Context, slug string) (country *models.Country, currentSlug string, err error) {
+	country, err = s.countryRepo.GetBySlug(ctx, slug)
+	if err == nil || !isNotFound(err) {
+		return country, "", err
+	}
+
+	id, err := s.slugService.ResolveHistory(ctx, models.CountrySlugEntity, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	country, err = s.countryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return country, country.Slug, nil
+}
+
 func (s *CountryService) UpdateCountry(ctx context.Context, country *models.Country) (*models.Country, error) {
-	_, err := s.countryRepo.GetByID(ctx, country.ID) // check if exists
+	existing, err := s.countryRepo.GetByID(ctx, country.ID) // check if exists
 	if err != nil {
 		return nil, err
 	}
 
+	if existing.Name != country.Name || existing.Slug == "" {
+		if country.Slug, err = s.slugService.Rename(ctx, models.CountrySlugEntity, existing.Slug, country.Name, country.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	updatedCountry, err := s.countryRepo.Update(ctx, country)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/enrichment"
+	"itv-movie/internal/pkg/utils/constants"
+	"itv-movie/internal/storage/database/repositories"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoExternalID        = errors.New("movie has no IMDb or TMDB ID, provide one to enrich from")
+	ErrUnknownEnrichField  = errors.New("unknown enrichment field")
+	ErrNoEnrichFieldsGiven = errors.New("at least one field must be accepted")
+)
+
+// Fields an editor can accept from an enrichment proposal
+const (
+	EnrichImdbID      = "imdbId"
+	EnrichTmdbID      = "tmdbId"
+	EnrichTitle       = "title"
+	EnrichYear        = "year"
+	EnrichPlot        = "plot"
+	EnrichRuntime     = "runtime"
+	EnrichRating      = "rating"
+	EnrichPosterURL   = "posterUrl"
+	EnrichReleaseDate = "releaseDate"
+	EnrichDirector    = "director"
+	EnrichCredits     = "credits"
+)
+
+var enrichFields = []string{
+	EnrichImdbID, EnrichTmdbID, EnrichTitle, EnrichYear, EnrichPlot, EnrichRuntime,
+	EnrichRating, EnrichPosterURL, EnrichReleaseDate, EnrichDirector, EnrichCredits,
+}
+
+// FieldChange is a single field of a proposed merge
+type FieldChange struct {
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Proposed interface{} `json:"proposed"`
+	Changed  bool        `json:"changed"`
+}
+
+// EnrichmentProposal is the field by field diff between a movie and provider metadata
+type EnrichmentProposal struct {
+	MovieID uuid.UUID     `json:"movieId"`
+	Source  string        `json:"source"`
+	Fields  []FieldChange `json:"fields"`
+}
+
+// EnrichmentService proposes and applies metadata from an external provider
+type EnrichmentService struct {
+	provider     enrichment.Provider
+	movieService *MovieService
+	movieRepo    *repositories.MovieRepository
+}
+
+// NewEnrichmentService creates a new enrichment service
+func NewEnrichmentService(
+	provider enrichment.Provider,
+	movieService *MovieService,
+	movieRepo *repositories.MovieRepository,
+) *EnrichmentService {
+	return &EnrichmentService{
+		provider:     provider,
+		movieService: movieService,
+		movieRepo:    movieRepo,
+	}
+}
+
+// Propose fetches provider metadata and compares it with the stored movie.
+// When ids is empty the movie's own IMDb/TMDB IDs are used
+func (s *EnrichmentService) Propose(ctx context.Context, movieID uuid.UUID, ids enrichment.ExternalIDs) (*EnrichmentProposal, error) {
+	movie, meta, err := s.fetch(ctx, movieID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	current := enrichValues(movie)
+	proposed := metadataValues(meta)
+
+	proposal := &EnrichmentProposal{
+		MovieID: movie.ID,
+		Source:  meta.Source,
+		Fields:  make([]FieldChange, 0, len(enrichFields)),
+	}
+
+	for _, field := range enrichFields {
+		proposal.Fields = append(proposal.Fields, FieldChange{
+			Field:    field,
+			Current:  current[field],
+			Proposed: proposed[field],
+			Changed:  !reflect.DeepEqual(current[field], proposed[field]),
+		})
+	}
+
+	return proposal, nil
+}
+
+// Apply re-fetches the metadata and copies the accepted fields onto the movie
+func (s *EnrichmentService) Apply(ctx context.Context, movieID uuid.UUID, ids enrichment.ExternalIDs, fields []string) (*models.Movie, error) {
+	if len(fields) == 0 {
+		return nil, ErrNoEnrichFieldsGiven
+	}
+
+	accepted := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !isEnrichField(field) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownEnrichField, field)
+		}
+		accepted[field] = true
+	}
+
+	movie, meta, err := s.fetch(ctx, movieID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	applyMetadata(movie, meta, accepted)
+
+	if _, err = s.movieService.UpdateMovie(ctx, movie); err != nil {
+		return nil, err
+	}
+
+	if credits := metadataCredits(meta); accepted[EnrichCredits] && len(credits) > 0 {
+		if err = s.movieService.ReplaceCredits(ctx, movie.ID, credits); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.movieRepo.GetByID(ctx, movie.ID)
+}
+
+// applyMetadata copies the accepted fields onto the movie. Fields the provider left empty are skipped,
+// a gap in the provider's data must not wipe what the movie already has
+func applyMetadata(movie *models.Movie, meta *enrichment.Metadata, accepted map[string]bool) {
+	if accepted[EnrichImdbID] && meta.ImdbID != "" {
+		movie.ImdbID = &meta.ImdbID
+	}
+	if accepted[EnrichTmdbID] && meta.TmdbID != 0 {
+		movie.TmdbID = &meta.TmdbID
+	}
+	if accepted[EnrichTitle] && meta.Title != "" {
+		movie.Title = meta.Title
+	}
+	if accepted[EnrichYear] && meta.Year != 0 {
+		movie.Year = meta.Year
+	}
+	if accepted[EnrichPlot] && meta.Plot != "" {
+		movie.Plot = meta.Plot
+	}
+	if accepted[EnrichRuntime] && meta.Runtime != 0 {
+		movie.Runtime = meta.Runtime
+	}
+	if accepted[EnrichRating] && meta.Rating != 0 {
+		movie.Rating = meta.Rating
+	}
+	if accepted[EnrichPosterURL] && meta.PosterURL != "" {
+		movie.PosterURL = meta.PosterURL
+	}
+	if accepted[EnrichReleaseDate] && meta.ReleaseDate != nil {
+		movie.ReleaseDate = meta.ReleaseDate
+	}
+	if director := metadataDirector(meta); accepted[EnrichDirector] && director != "" {
+		movie.Director = director
+	}
+}
+
+func (s *EnrichmentService) fetch(ctx context.Context, movieID uuid.UUID, ids enrichment.ExternalIDs) (*models.Movie, *enrichment.Metadata, error) {
+	movie, err := s.movieRepo.GetByID(ctx, movieID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ids.IsEmpty() {
+		if movie.ImdbID != nil {
+			ids.ImdbID = *movie.ImdbID
+		}
+		if movie.TmdbID != nil {
+			ids.TmdbID = *movie.TmdbID
+		}
+	}
+
+	if ids.IsEmpty() {
+		return nil, nil, ErrNoExternalID
+	}
+
+	meta, err := s.provider.FetchMovie(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return movie, meta, nil
+}
+
+func isEnrichField(field string) bool {
+	for _, f := range enrichFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func enrichValues(movie *models.Movie) map[string]interface{} {
+	values := map[string]interface{}{
+		EnrichImdbID:      "",
+		EnrichTmdbID:      int64(0),
+		EnrichTitle:       movie.Title,
+		EnrichYear:        movie.Year,
+		EnrichPlot:        movie.Plot,
+		EnrichRuntime:     movie.Runtime,
+		EnrichRating:      movie.Rating,
+		EnrichPosterURL:   movie.PosterURL,
+		EnrichReleaseDate: formatDate(movie.ReleaseDate),
+		EnrichDirector:    movie.Director,
+	}
+
+	if movie.ImdbID != nil {
+		values[EnrichImdbID] = *movie.ImdbID
+	}
+	if movie.TmdbID != nil {
+		values[EnrichTmdbID] = *movie.TmdbID
+	}
+
+	credits := make([]enrichment.Credit, 0, len(movie.Credits))
+	for _, credit := range movie.Credits {
+		credits = append(credits, enrichment.Credit{
+			Name:      credit.Name,
+			Role:      credit.Role,
+			Character: credit.Character,
+			Position:  credit.Position,
+		})
+	}
+	values[EnrichCredits] = credits
+
+	return values
+}
+
+func metadataValues(meta *enrichment.Metadata) map[string]interface{} {
+	credits := meta.Credits
+	if credits == nil {
+		credits = []enrichment.Credit{}
+	}
+
+	return map[string]interface{}{
+		EnrichImdbID:      meta.ImdbID,
+		EnrichTmdbID:      meta.TmdbID,
+		EnrichTitle:       meta.Title,
+		EnrichYear:        meta.Year,
+		EnrichPlot:        meta.Plot,
+		EnrichRuntime:     meta.Runtime,
+		EnrichRating:      meta.Rating,
+		EnrichPosterURL:   meta.PosterURL,
+		EnrichReleaseDate: formatDate(meta.ReleaseDate),
+		EnrichDirector:    metadataDirector(meta),
+		EnrichCredits:     credits,
+	}
+}
+
+func metadataDirector(meta *enrichment.Metadata) string {
+	var directors []string
+	for _, credit := range meta.Credits {
+		if credit.Role == models.DirectorCredit {
+			directors = append(directors, credit.Name)
+		}
+	}
+	return strings.Join(directors, ", ")
+}
+
+func metadataCredits(meta *enrichment.Metadata) []models.MovieCredit {
+	credits := make([]models.MovieCredit, 0, len(meta.Credits))
+	for _, credit := range meta.Credits {
+		credits = append(credits, models.MovieCredit{
+			Name:      credit.Name,
+			Role:      credit.Role,
+			Character: credit.Character,
+			Position:  credit.Position,
+		})
+	}
+	return credits
+}
+
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(constants.DateFormat)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/enrichment"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// sparseFixture is a TMDB answer with most fields missing, as for obscure titles
+const sparseFixture = `{
+	"id": 42,
+	"imdb_id": "",
+	"title": "Untitled Short",
+	"overview": "",
+	"runtime": 0,
+	"release_date": "",
+	"vote_average": 0,
+	"poster_path": null,
+	"credits": {"cast": [], "crew": []}
+}`
+
+func fetchFixture(t *testing.T, body string) *enrichment.Metadata {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	meta, err := enrichment.NewTmdbClient(server.URL, "", "", server.Client()).
+		FetchMovie(context.Background(), enrichment.ExternalIDs{TmdbID: 42})
+	if err != nil {
+		t.Fatalf("FetchMovie: %v", err)
+	}
+	return meta
+}
+
+func TestApplyMetadataSkipsEmptyFields(t *testing.T) {
+	meta := fetchFixture(t, sparseFixture)
+
+	imdbID := "tt0000042"
+	released := time.Date(2001, 5, 4, 0, 0, 0, 0, time.UTC)
+	movie := &models.Movie{
+		Title:       "Old Title",
+		Year:        2001,
+		Plot:        "A plot written by an editor.",
+		Runtime:     12,
+		Rating:      6.5,
+		PosterURL:   "https://cdn.example/poster.jpg",
+		ReleaseDate: &released,
+		Director:    "Jane Doe",
+		ImdbID:      &imdbID,
+	}
+
+	accepted := make(map[string]bool, len(enrichFields))
+	for _, field := range enrichFields {
+		accepted[field] = true
+	}
+
+	applyMetadata(movie, meta, accepted)
+
+	if movie.Title != "Untitled Short" {
+		t.Errorf("title = %q, the provider value should win", movie.Title)
+	}
+	if movie.TmdbID == nil || *movie.TmdbID != 42 {
+		t.Errorf("tmdb id = %v, want 42", movie.TmdbID)
+	}
+	if movie.ImdbID == nil || *movie.ImdbID != imdbID {
+		t.Errorf("imdb id = %v, the empty provider value must not replace it", movie.ImdbID)
+	}
+	if movie.Year != 2001 || movie.Plot != "A plot written by an editor." || movie.Runtime != 12 || movie.Rating != 6.5 {
+		t.Errorf("empty provider values overwrote the movie: %+v", movie)
+	}
+	if movie.PosterURL != "https://cdn.example/poster.jpg" || movie.ReleaseDate != &released || movie.Director != "Jane Doe" {
+		t.Errorf("empty provider values overwrote the movie: %+v", movie)
+	}
+	if len(metadataCredits(meta)) != 0 {
+		t.Errorf("the fixture has no credits, Apply would keep the existing ones")
+	}
+}
+
+func TestApplyMetadataOnlyAcceptedFields(t *testing.T) {
+	meta := fetchFixture(t, `{
+		"id": 42,
+		"title": "New Title",
+		"overview": "New plot.",
+		"runtime": 95,
+		"release_date": "2010-01-02",
+		"credits": {"crew": [{"name": "John Roe", "job": "Director", "department": "Directing"}]}
+	}`)
+
+	movie := &models.Movie{Title: "Old Title", Plot: "Old plot.", Runtime: 90, Director: "Jane Doe"}
+
+	applyMetadata(movie, meta, map[string]bool{EnrichPlot: true, EnrichDirector: true})
+
+	if movie.Title != "Old Title" || movie.Runtime != 90 || movie.Year != 0 || movie.ReleaseDate != nil {
+		t.Errorf("fields that were not accepted changed: %+v", movie)
+	}
+	if movie.Plot != "New plot." || movie.Director != "John Roe" {
+		t.Errorf("accepted fields were not applied: %+v", movie)
+	}
+}
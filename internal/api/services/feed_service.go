@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/feed"
+	"itv-movie/internal/storage/database/repositories"
+	"strings"
+)
+
+var (
+	ErrSitemapPageNotFound = errors.New("sitemap page not found")
+)
+
+// FeedService builds the sitemap and Atom feeds of the public catalog
+type FeedService struct {
+	movieRepo    *repositories.MovieRepository
+	genreService *GenreService
+	cfg          config.Feed
+}
+
+// NewFeedService creates a new feed service
+func NewFeedService(
+	movieRepo *repositories.MovieRepository,
+	genreService *GenreService,
+	cfg *config.Config,
+) *FeedService {
+	feedCfg := cfg.Internal.Feed
+	if feedCfg.SitemapPageSize < 1 || feedCfg.SitemapPageSize > feed.MaxSitemapURLs {
+		feedCfg.SitemapPageSize = feed.MaxSitemapURLs
+	}
+	if feedCfg.EntryLimit < 1 {
+		feedCfg.EntryLimit = 50
+	}
+
+	return &FeedService{
+		movieRepo:    movieRepo,
+		genreService: genreService,
+		cfg:          feedCfg,
+	}
+}
+
+// MovieFeed is an Atom feed of the newest movies, of a single genre and its sub-genres when Genre is set
+type MovieFeed struct {
+	Genre *models.Genre
+	Stats *repositories.FeedStats
+}
+
+// SitemapStats returns the movie count and last change of the sitemap
+func (s *FeedService) SitemapStats(ctx context.Context) (*repositories.FeedStats, error) {
+	return s.movieRepo.FeedStats(ctx, uuid.Nil)
+}
+
+// SitemapPages returns how many child sitemaps the movies need
+func (s *FeedService) SitemapPages(stats *repositories.FeedStats) int {
+	return int((stats.Count + int64(s.cfg.SitemapPageSize) - 1) / int64(s.cfg.SitemapPageSize))
+}
+
+// WriteSitemap writes /sitemap.xml: the movie URLs themselves while they fit into one sitemap,
+// a sitemap index of paged child sitemaps once they do not
+func (s *FeedService) WriteSitemap(ctx context.Context, w io.Writer, stats *repositories.FeedStats) error {
+	pages := s.SitemapPages(stats)
+	if pages <= 1 {
+		return s.writeSitemapPage(ctx, w, 0)
+	}
+
+	writer, err := feed.NewSitemapIndexWriter(w)
+	if err != nil {
+		return err
+	}
+	for page := 1; page <= pages; page++ {
+		ref := feed.SitemapRef{
+			Loc:     s.SelfURL(fmt.Sprintf("/sitemaps/movies-%d.xml", page)),
+			LastMod: feed.FormatTime(stats.LastModified),
+		}
+		if err = writer.Write(ref); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// WriteSitemapPage writes the child sitemap with the given 1-based page number
+func (s *FeedService) WriteSitemapPage(ctx context.Context, w io.Writer, stats *repositories.FeedStats, page int) error {
+	if page < 1 || page > s.SitemapPages(stats) {
+		return ErrSitemapPageNotFound
+	}
+
+	return s.writeSitemapPage(ctx, w, (page-1)*s.cfg.SitemapPageSize)
+}
+
+func (s *FeedService) writeSitemapPage(ctx context.Context, w io.Writer, offset int) error {
+	writer, err := feed.NewSitemapWriter(w)
+	if err != nil {
+		return err
+	}
+
+	err = s.movieRepo.EachForSitemap(ctx, offset, s.cfg.SitemapPageSize, func(entry repositories.SitemapEntry) error {
+		return writer.Write(feed.SitemapURL{
+			Loc:     s.moviePageURL(entry.ID, entry.Slug),
+			LastMod: feed.FormatTime(entry.UpdatedAt),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// GetMovieFeed prepares the feed of new movies, genreRef narrows it to a genre given by ID or slug
+func (s *FeedService) GetMovieFeed(ctx context.Context, genreRef string) (*MovieFeed, error) {
+	movieFeed := &MovieFeed{}
+
+	genreID := uuid.Nil
+	if genreRef != "" {
+		var err error
+		if movieFeed.Genre, err = s.genreService.GetGenreByRef(ctx, genreRef); err != nil {
+			return nil, err
+		}
+		genreID = movieFeed.Genre.ID
+	}
+
+	stats, err := s.movieRepo.FeedStats(ctx, genreID)
+	if err != nil {
+		return nil, err
+	}
+	movieFeed.Stats = stats
+
+	return movieFeed, nil
+}
+
+// WriteMovieFeed streams the Atom feed, selfURL is the URL the feed was requested with
+func (s *FeedService) WriteMovieFeed(ctx context.Context, w io.Writer, movieFeed *MovieFeed, selfURL string) error {
+	header := feed.AtomHeader{
+		ID:      selfURL,
+		Title:   s.cfg.Title + ": new movies",
+		Author:  s.cfg.Title,
+		Updated: movieFeed.Stats.LastModified,
+		Links: []feed.AtomLink{
+			{Rel: "self", Href: selfURL, Type: "application/atom+xml"},
+			{Rel: "alternate", Href: strings.TrimRight(s.cfg.SiteURL, "/"), Type: "text/html"},
+		},
+	}
+
+	genreID := uuid.Nil
+	if movieFeed.Genre != nil {
+		genreID = movieFeed.Genre.ID
+		header.Title = s.cfg.Title + ": new " + movieFeed.Genre.Name + " movies"
+	}
+
+	writer, err := feed.NewAtomWriter(w, header)
+	if err != nil {
+		return err
+	}
+
+	err = s.movieRepo.EachLatest(ctx, genreID, s.cfg.EntryLimit, func(movie *models.Movie) error {
+		entry := feed.AtomEntry{
+			ID:        "urn:uuid:" + movie.ID.String(),
+			Title:     movie.Title,
+			Updated:   feed.FormatTime(movie.UpdatedAt),
+			Published: feed.FormatTime(*movie.PublishedAt),
+			Links:     []feed.AtomLink{{Rel: "alternate", Href: s.moviePageURL(movie.ID, movie.Slug), Type: "text/html"}},
+			Summary:   movie.Plot,
+		}
+		if movie.Year > 0 {
+			entry.Title = fmt.Sprintf("%s (%d)", movie.Title, movie.Year)
+		}
+		if movie.Director != "" {
+			entry.Author = &feed.AtomPerson{Name: movie.Director}
+		}
+		return writer.Write(entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// SelfURL builds the public URL of a path served by this service
+func (s *FeedService) SelfURL(path string) string {
+	return strings.TrimRight(s.cfg.BaseURL, "/") + path
+}
+
+// moviePageURL links the frontend page of a movie, by slug when it has one
+func (s *FeedService) moviePageURL(id uuid.UUID, slug string) string {
+	ref := slug
+	if ref == "" {
+		ref = id.String()
+	}
+	return strings.TrimRight(s.cfg.SiteURL, "/") + "/movies/" + ref
+}
@@ -2,26 +2,47 @@ package services
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"itv-movie/internal/models"
 	"itv-movie/internal/storage/database/repositories"
 )
 
+var (
+	ErrUnknownParentGenre   = errors.New("parent genre not found")
+	ErrGenreCycle           = errors.New("a genre cannot be its own ancestor")
+	ErrGenreInUse           = errors.New("genre has sub-genres or movies, give a genre to reassign them to")
+	ErrInvalidReassignGenre = errors.New("genres cannot be reassigned to the deleted genre or one of its sub-genres")
+)
+
 // GenreService handles business logic for genre
 type GenreService struct {
-	genreRepo *repositories.GenreRepository
+	genreRepo   *repositories.GenreRepository
+	slugService *SlugService
 }
 
 // NewGenreService creates a new genre service
 func NewGenreService(
 	genreRepo *repositories.GenreRepository,
+	slugService *SlugService,
 ) *GenreService {
 	return &GenreService{
-		genreRepo: genreRepo,
+		genreRepo:   genreRepo,
+		slugService: slugService,
 	}
 }
 
 func (s *GenreService) CreateGenre(ctx context.Context, genre *models.Genre) (*models.Genre, error) {
+	if err := s.checkParent(ctx, genre); err != nil {
+		return nil, err
+	}
+
+	slug, err := s.slugService.Generate(ctx, models.GenreSlugEntity, genre.Name, genre.ID)
+	if err != nil {
+		return nil, err
+	}
+	genre.Slug = slug
+
 	createdGenre, err := s.genreRepo.Create(ctx, genre)
 	if err != nil {
 		return nil, err
@@ -55,12 +76,42 @@ func (s *GenreService) GetGenre(ctx context.Context, id uuid.UUID) (*models.Genr
 	return genre, nil
 }
 
+// GetGenreBySlug finds a genre by its current slug or an old one, currentSlug is set when the slug is outdated
+func (s *GenreService) GetGenreBySlug(ctx context.Context, slug string) (genre *models.Genre, currentSlug string, err error) {
+	genre, err = s.genreRepo.GetBySlug(ctx, slug)
+	if err == nil || !isNotFound(err) {
+		return genre, "", err
+	}
+
+	id, err := s.slugService.ResolveHistory(ctx, models.GenreSlugEntity, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	genre, err = s.genreRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return genre, genre.Slug, nil
+}
+
 func (s *GenreService) UpdateGenre(ctx context.Context, genre *models.Genre) (*models.Genre, error) {
-	_, err := s.genreRepo.GetByID(ctx, genre.ID) // check if exists
+	existing, err := s.genreRepo.GetByID(ctx, genre.ID) // check if exists
 	if err != nil {
 		return nil, err
 	}
 
+	if err = s.checkParent(ctx, genre); err != nil {
+		return nil, err
+	}
+
+	if existing.Name != genre.Name || existing.Slug == "" {
+		if genre.Slug, err = s.slugService.Rename(ctx, models.GenreSlugEntity, existing.Slug, genre.Name, genre.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	updatedGenre, err := s.genreRepo.Update(ctx, genre)
 	if err != nil {
 		return nil, err
@@ -69,8 +120,99 @@ func (s *GenreService) UpdateGenre(ctx context.Context, genre *models.Genre) (*m
 	return updatedGenre, nil
 }
 
-func (s *GenreService) DeleteGenre(ctx context.Context, genre *models.Genre) error {
-	return s.genreRepo.Delete(ctx, genre.ID)
+// DeleteGenre deletes a genre without sub-genres or movies. Otherwise reassignTo must be given,
+// the sub-genres, movies and user preferences then move to that genre
+func (s *GenreService) DeleteGenre(ctx context.Context, genre *models.Genre, reassignTo *uuid.UUID) error {
+	if reassignTo == nil {
+		children, movies, err := s.genreRepo.CountUsage(ctx, genre.ID)
+		if err != nil {
+			return err
+		}
+		if children > 0 || movies > 0 {
+			return ErrGenreInUse
+		}
+
+		return s.genreRepo.Delete(ctx, genre.ID)
+	}
+
+	if _, err := s.genreRepo.GetByID(ctx, *reassignTo); err != nil {
+		return err
+	}
+
+	inSubtree, err := s.genreRepo.InSubtree(ctx, genre.ID, *reassignTo)
+	if err != nil {
+		return err
+	}
+	if inSubtree {
+		return ErrInvalidReassignGenre
+	}
+
+	return s.genreRepo.DeleteAndReassign(ctx, genre.ID, *reassignTo)
+}
+
+// GetGenreByRef finds a genre by ID or by its current or an old slug
+func (s *GenreService) GetGenreByRef(ctx context.Context, ref string) (*models.Genre, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return s.genreRepo.GetByID(ctx, id)
+	}
+
+	genre, _, err := s.GetGenreBySlug(ctx, ref)
+	return genre, err
+}
+
+// GetGenreTree returns the top-level genres with their sub-genres nested in Children
+func (s *GenreService) GetGenreTree(ctx context.Context) ([]*models.Genre, error) {
+	genres, err := s.genreRepo.GetAllOrdered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*models.Genre, len(genres))
+	for _, genre := range genres {
+		byID[genre.ID] = genre
+	}
+
+	roots := make([]*models.Genre, 0)
+	for _, genre := range genres {
+		if genre.ParentID != nil {
+			if parent, ok := byID[*genre.ParentID]; ok {
+				parent.Children = append(parent.Children, genre)
+				continue
+			}
+		}
+		// top-level genres, and genres whose parent was deleted
+		roots = append(roots, genre)
+	}
+
+	return roots, nil
+}
+
+// checkParent makes sure the parent of the genre exists and is not the genre itself or one of its descendants
+func (s *GenreService) checkParent(ctx context.Context, genre *models.Genre) error {
+	if genre.ParentID == nil {
+		return nil
+	}
+
+	if _, err := s.genreRepo.GetByID(ctx, *genre.ParentID); err != nil {
+		if isNotFound(err) {
+			return ErrUnknownParentGenre
+		}
+		return err
+	}
+
+	if genre.ID == uuid.Nil {
+		return nil
+	}
+
+	inSubtree, err := s.genreRepo.InSubtree(ctx, genre.ID, *genre.ParentID)
+	if err != nil {
+		return err
+	}
+	if inSubtree {
+		return ErrGenreCycle
+	}
+
+	return nil
 }
 
 func (s *GenreService) GetTotalGenreCount(ctx context.Context) (int, error) {
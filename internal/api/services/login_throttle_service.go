@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+	ErrNothingToUnlock      = errors.New("username or ip is required")
+)
+
+// LoginBlockedError is returned while the username or the IP address of a login is blocked
+type LoginBlockedError struct {
+	Until time.Time
+}
+
+func (e *LoginBlockedError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// RetryAfter is the time left until the block is lifted, at least a second
+func (e *LoginBlockedError) RetryAfter() time.Duration {
+	return max(time.Until(e.Until), time.Second)
+}
+
+// LoginThrottleService slows down and locks out repeated failed logins per username and per IP address
+type LoginThrottleService struct {
+	throttleRepo      *repositories.LoginThrottleRepository
+	securityEventRepo *repositories.SecurityEventRepository
+	cfg               config.LoginProtection
+	log               *slog.Logger
+}
+
+// NewLoginThrottleService creates a new login throttle service
+func NewLoginThrottleService(
+	throttleRepo *repositories.LoginThrottleRepository,
+	securityEventRepo *repositories.SecurityEventRepository,
+	cfg *config.Config,
+	log *slog.Logger,
+) *LoginThrottleService {
+	protectionCfg := cfg.Internal.LoginProtection
+	if protectionCfg.FreeAttempts < 0 {
+		protectionCfg.FreeAttempts = 0
+	}
+	if protectionCfg.BaseDelay < 1 {
+		protectionCfg.BaseDelay = 1
+	}
+	if protectionCfg.MaxDelay < protectionCfg.BaseDelay {
+		protectionCfg.MaxDelay = 300
+	}
+	if protectionCfg.MaxUsernameFailures < 1 {
+		protectionCfg.MaxUsernameFailures = 10
+	}
+	if protectionCfg.MaxIPFailures < 1 {
+		protectionCfg.MaxIPFailures = 50
+	}
+	if protectionCfg.LockoutDuration < 1 {
+		protectionCfg.LockoutDuration = 900
+	}
+	if protectionCfg.Window < 1 {
+		protectionCfg.Window = 3600
+	}
+
+	return &LoginThrottleService{
+		throttleRepo:      throttleRepo,
+		securityEventRepo: securityEventRepo,
+		cfg:               protectionCfg,
+		log:               log,
+	}
+}
+
+func normalizeLoginUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Check returns a *LoginBlockedError when the username or the IP address may not try to log in right now
+func (s *LoginThrottleService) Check(ctx context.Context, username, ipAddress string) error {
+	until, err := s.throttleRepo.BlockedUntil(ctx, normalizeLoginUsername(username), ipAddress, time.Now())
+	if err != nil {
+		return err
+	}
+	if until != nil {
+		return &LoginBlockedError{Until: *until}
+	}
+	return nil
+}
+
+// Fail counts a failed login against the username and the IP address and blocks them for the backoff
+// delay, or for the lockout duration once they reach their maximum number of failures
+func (s *LoginThrottleService) Fail(ctx context.Context, username, ipAddress, userAgent string) error {
+	if err := s.fail(ctx, models.UsernameThrottle, normalizeLoginUsername(username), s.cfg.MaxUsernameFailures, ipAddress, userAgent); err != nil {
+		return err
+	}
+	return s.fail(ctx, models.IPThrottle, ipAddress, s.cfg.MaxIPFailures, ipAddress, userAgent)
+}
+
+func (s *LoginThrottleService) fail(ctx context.Context, scope, subject string, maxFailures int, ipAddress, userAgent string) error {
+	if subject == "" {
+		return nil
+	}
+
+	now := time.Now()
+	failures, err := s.throttleRepo.RecordFailure(ctx, scope, subject, now, now.Add(-time.Duration(s.cfg.Window)*time.Second))
+	if err != nil {
+		return err
+	}
+
+	if failures >= maxFailures {
+		if err = s.throttleRepo.Block(ctx, scope, subject, now.Add(time.Duration(s.cfg.LockoutDuration)*time.Second)); err != nil {
+			return err
+		}
+		if failures == maxFailures {
+			s.lockedOut(ctx, scope, subject, failures, ipAddress, userAgent)
+		}
+		return nil
+	}
+
+	if delay := s.backoff(failures); delay > 0 {
+		return s.throttleRepo.Block(ctx, scope, subject, now.Add(delay))
+	}
+	return nil
+}
+
+// backoff is the delay after the given number of failures in a row, doubling after the free attempts
+func (s *LoginThrottleService) backoff(failures int) time.Duration {
+	exceeded := failures - s.cfg.FreeAttempts
+	if exceeded < 1 {
+		return 0
+	}
+
+	delay := s.cfg.BaseDelay
+	for i := 1; i < exceeded && delay < s.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	return time.Duration(min(delay, s.cfg.MaxDelay)) * time.Second
+}
+
+func (s *LoginThrottleService) lockedOut(ctx context.Context, scope, subject string, failures int, ipAddress, userAgent string) {
+	s.log.Warn("login locked out after repeated failures", "scope", scope, "subject", subject, "failures", failures, "ip", ipAddress)
+
+	event := &models.SecurityEvent{
+		Type:      models.LoginLockoutEvent,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Details:   fmt.Sprintf("%s %s locked out for %ds after %d failed logins", scope, subject, s.cfg.LockoutDuration, failures),
+	}
+	if err := s.securityEventRepo.Create(ctx, event); err != nil {
+		s.log.Error("failed to record security event", "error", err)
+	}
+}
+
+// Succeed forgets the failures of the username after a successful login. The IP address keeps
+// its count, otherwise one valid account would let an attacker reset it
+func (s *LoginThrottleService) Succeed(ctx context.Context, username string) error {
+	_, err := s.throttleRepo.Reset(ctx, models.UsernameThrottle, normalizeLoginUsername(username))
+	return err
+}
+
+// Unlock lifts the block and forgets the failures of a username and/or an IP address
+func (s *LoginThrottleService) Unlock(ctx context.Context, username, ipAddress string) (int64, error) {
+	username = normalizeLoginUsername(username)
+	ipAddress = strings.TrimSpace(ipAddress)
+	if username == "" && ipAddress == "" {
+		return 0, ErrNothingToUnlock
+	}
+
+	var unlocked int64
+	if username != "" {
+		n, err := s.throttleRepo.Reset(ctx, models.UsernameThrottle, username)
+		if err != nil {
+			return 0, err
+		}
+		unlocked += n
+	}
+	if ipAddress != "" {
+		n, err := s.throttleRepo.Reset(ctx, models.IPThrottle, ipAddress)
+		if err != nil {
+			return 0, err
+		}
+		unlocked += n
+	}
+
+	return unlocked, nil
+}
+
+// GetBlocked lists the usernames and IP addresses that are blocked right now
+func (s *LoginThrottleService) GetBlocked(ctx context.Context, page, limit int) ([]*models.LoginThrottle, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if limit < 1 || limit > 1000 {
+		limit = 10
+	}
+
+	return s.throttleRepo.GetBlocked(ctx, time.Now(), page, limit)
+}
+
+// Run prunes the counters that no longer matter every window until ctx is cancelled
+func (s *LoginThrottleService) Run(ctx context.Context) {
+	window := time.Duration(s.cfg.Window) * time.Second
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		if _, err := s.throttleRepo.DeleteStale(ctx, now.Add(-window), now); err != nil && ctx.Err() == nil {
+			s.log.Error("failed to prune login throttles", "error", err)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+	"image"
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder
+	"io"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database/repositories"
+	"itv-movie/internal/storage/media"
+	"log/slog"
+	"net/http"
+)
+
+var (
+	ErrInvalidMediaKind     = errors.New("media kind must be poster or backdrop")
+	ErrUnsupportedMediaType = errors.New("only JPEG, PNG and WebP images are supported")
+	ErrMediaTooLarge        = errors.New("uploaded file is too large")
+	ErrImageTooLarge        = errors.New("uploaded image has too many pixels")
+	ErrInvalidImage         = errors.New("uploaded file is not a valid image")
+	ErrUnknownMediaVariant  = errors.New("unknown media variant")
+	ErrMediaNotFound        = errors.New("media asset not found")
+)
+
+const (
+	defaultMaxUploadSize  = 10 << 20   // 10 MB
+	defaultMaxImagePixels = 40_000_000 // e.g. 8000×5000
+)
+
+// allowedMediaTypes maps accepted content types to the extension of the stored original
+var allowedMediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// MediaService handles uploading, resizing and serving movie images
+type MediaService struct {
+	mediaRepo *repositories.MediaAssetRepository
+	movieRepo *repositories.MovieRepository
+	storage   media.Storage
+	similar   *SimilarService
+	config    *config.Config
+	log       *slog.Logger
+}
+
+// NewMediaService creates a new media service
+func NewMediaService(
+	mediaRepo *repositories.MediaAssetRepository,
+	movieRepo *repositories.MovieRepository,
+	storage media.Storage,
+	similar *SimilarService,
+	config *config.Config,
+	log *slog.Logger,
+) *MediaService {
+	return &MediaService{
+		mediaRepo: mediaRepo,
+		movieRepo: movieRepo,
+		storage:   storage,
+		similar:   similar,
+		config:    config,
+		log:       log,
+	}
+}
+
+// MaxUploadSize returns the configured upload limit in bytes
+func (s *MediaService) MaxUploadSize() int64 {
+	if size := s.config.Internal.Storage.MaxUploadSize; size > 0 {
+		return size
+	}
+	return defaultMaxUploadSize
+}
+
+// MaxImagePixels returns the configured limit of width × height of an uploaded image
+func (s *MediaService) MaxImagePixels() int64 {
+	if pixels := s.config.Internal.Storage.MaxImagePixels; pixels > 0 {
+		return pixels
+	}
+	return defaultMaxImagePixels
+}
+
+// UploadMovieMedia validates the image, stores the original and generates resized variants
+func (s *MediaService) UploadMovieMedia(ctx context.Context, movieID uuid.UUID, kind string, file io.Reader) (*models.MediaAsset, error) {
+	if kind != models.PosterMedia && kind != models.BackdropMedia {
+		return nil, ErrInvalidMediaKind
+	}
+
+	if _, err := s.movieRepo.GetByID(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	// read one byte over the limit to detect oversized uploads
+	data, err := io.ReadAll(io.LimitReader(file, s.MaxUploadSize()+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.MaxUploadSize() {
+		return nil, ErrMediaTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedMediaTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	// a small compressed file can declare huge dimensions, check them before decoding allocates the pixels
+	imgConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if imgConfig.Width <= 0 || imgConfig.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if int64(imgConfig.Width)*int64(imgConfig.Height) > s.MaxImagePixels() {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	asset := &models.MediaAsset{
+		ID:          uuid.New(),
+		MovieID:     movieID,
+		Kind:        kind,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}
+	asset.StorageKey = fmt.Sprintf("movies/%s/%s/original%s", movieID, asset.ID, ext)
+
+	stored := make([]string, 0, len(models.MediaVariantWidths)+1)
+	cleanup := func() {
+		for _, key := range stored {
+			if err := s.storage.Delete(ctx, key); err != nil {
+				s.log.Warn("failed to clean up media object", "key", key, "error", err)
+			}
+		}
+	}
+
+	if err = s.storage.Put(ctx, asset.StorageKey, bytes.NewReader(data), contentType); err != nil {
+		return nil, err
+	}
+	stored = append(stored, asset.StorageKey)
+
+	for variant, width := range models.MediaVariantWidths {
+		var buf bytes.Buffer
+		if err = jpeg.Encode(&buf, resizeToWidth(img, width), &jpeg.Options{Quality: 85}); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		key := asset.VariantKey(variant)
+		if err = s.storage.Put(ctx, key, &buf, "image/jpeg"); err != nil {
+			cleanup()
+			return nil, err
+		}
+		stored = append(stored, key)
+	}
+
+	created, err := s.mediaRepo.Create(ctx, asset)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	// keep the legacy posterUrl field pointing at the latest uploaded poster
+	if kind == models.PosterMedia {
+		if err = s.movieRepo.UpdatePosterURL(ctx, movieID, created.VariantURLs()["large"]); err != nil {
+			return nil, err
+		}
+		s.similar.Invalidate(movieID)
+	}
+
+	return created, nil
+}
+
+// OpenMediaVariant opens a stored variant of an asset for reading
+func (s *MediaService) OpenMediaVariant(ctx context.Context, assetID uuid.UUID, variant string) (io.ReadSeekCloser, *media.ObjectInfo, error) {
+	if _, ok := models.MediaVariantWidths[variant]; !ok && variant != models.OriginalVariant {
+		return nil, nil, ErrUnknownMediaVariant
+	}
+
+	asset, err := s.mediaRepo.GetByID(ctx, assetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, info, err := s.storage.Open(ctx, asset.VariantKey(variant))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if variant == models.OriginalVariant {
+		info.ContentType = asset.ContentType
+	} else {
+		info.ContentType = "image/jpeg"
+	}
+
+	return file, info, nil
+}
+
+func (s *MediaService) GetMovieMedia(ctx context.Context, movieID uuid.UUID) ([]*models.MediaAsset, error) {
+	return s.mediaRepo.GetByMovieID(ctx, movieID)
+}
+
+// DeleteMovieMedia removes the asset row and all of its stored files.
+// A posterUrl pointing at a deleted poster moves to the newest remaining poster, or is cleared
+func (s *MediaService) DeleteMovieMedia(ctx context.Context, movieID, assetID uuid.UUID) error {
+	asset, err := s.mediaRepo.GetByID(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	if asset.MovieID != movieID {
+		return ErrMediaNotFound
+	}
+
+	if err = s.mediaRepo.Delete(ctx, asset.ID); err != nil {
+		return err
+	}
+
+	if asset.Kind == models.PosterMedia {
+		if err = s.repointPoster(ctx, movieID, asset.VariantURLs()["large"]); err != nil {
+			return err
+		}
+	}
+
+	keys := []string{asset.VariantKey(models.OriginalVariant)}
+	for variant := range models.MediaVariantWidths {
+		keys = append(keys, asset.VariantKey(variant))
+	}
+	for _, key := range keys {
+		if err = s.storage.Delete(ctx, key); err != nil {
+			s.log.Warn("failed to delete media object", "key", key, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// repointPoster replaces a posterUrl equal to deletedURL with the newest remaining poster.
+// A posterUrl set by hand to another address is left alone
+func (s *MediaService) repointPoster(ctx context.Context, movieID uuid.UUID, deletedURL string) error {
+	movie, err := s.movieRepo.GetByID(ctx, movieID)
+	if err != nil {
+		return err
+	}
+	if movie.PosterURL != deletedURL {
+		return nil
+	}
+
+	assets, err := s.mediaRepo.GetByMovieID(ctx, movieID)
+	if err != nil {
+		return err
+	}
+
+	posterURL := ""
+	for _, remaining := range assets {
+		// assets are ordered oldest first, the last poster wins
+		if remaining.Kind == models.PosterMedia {
+			posterURL = remaining.VariantURLs()["large"]
+		}
+	}
+
+	if err = s.movieRepo.UpdatePosterURL(ctx, movieID, posterURL); err != nil {
+		return err
+	}
+	s.similar.Invalidate(movieID)
+
+	return nil
+}
+
+// resizeToWidth scales the image down to the given width keeping the aspect ratio, smaller images are not upscaled
+func resizeToWidth(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= width {
+		width = bounds.Dx()
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	// variants are JPEG, so transparent areas are flattened onto white
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"github.com/google/uuid"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/totp"
+	"itv-movie/internal/pkg/utils/securetoken"
+	"itv-movie/internal/storage/database/repositories"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMfaNotEnabled        = errors.New("two-factor authentication is not enabled")
+	ErrMfaAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrMfaEnrollmentMissing = errors.New("two-factor enrollment was not started")
+	ErrMfaMandatory         = errors.New("two-factor authentication is mandatory for this role")
+	ErrInvalidMfaCode       = errors.New("invalid two-factor code")
+)
+
+// totpSkew is the number of 30s steps a code may be early or late
+const totpSkew = 1
+
+// MfaEnrollment is what an authenticator app needs, URI is the content of the QR code
+type MfaEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// MfaStatus describes the 2FA setup of a user
+type MfaStatus struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"`
+	RecoveryCodesLeft int64 `json:"recoveryCodesLeft"`
+}
+
+// MfaService manages TOTP enrollment, code verification and recovery codes
+type MfaService struct {
+	userRepo     *repositories.UserRepository
+	recoveryRepo *repositories.RecoveryCodeRepository
+	throttle     *LoginThrottleService
+	roles        *RoleService
+	cfg          config.Mfa
+}
+
+// NewMfaService creates a new MFA service
+func NewMfaService(
+	userRepo *repositories.UserRepository,
+	recoveryRepo *repositories.RecoveryCodeRepository,
+	throttle *LoginThrottleService,
+	roles *RoleService,
+	cfg *config.Config,
+) *MfaService {
+	mfaCfg := cfg.Internal.Mfa
+	if mfaCfg.Issuer == "" {
+		mfaCfg.Issuer = "ITV Movies"
+	}
+	if mfaCfg.ChallengeTTL < 1 {
+		mfaCfg.ChallengeTTL = 300
+	}
+	if mfaCfg.RecoveryCodes < 1 {
+		mfaCfg.RecoveryCodes = 10
+	}
+
+	return &MfaService{
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		throttle:     throttle,
+		roles:        roles,
+		cfg:          mfaCfg,
+	}
+}
+
+// ChallengeTTL is how long the MFA token of a login stays valid
+func (s *MfaService) ChallengeTTL() time.Duration {
+	return time.Duration(s.cfg.ChallengeTTL) * time.Second
+}
+
+// IsRequired reports whether the user must use 2FA, which the config switch turns on for privileged roles:
+// those granting any permission. A role whose permissions cannot be loaded counts as privileged
+func (s *MfaService) IsRequired(ctx context.Context, user *models.User) bool {
+	if !s.cfg.RequiredForPrivileged {
+		return false
+	}
+
+	permissions, err := s.roles.Permissions(ctx, user.Role)
+	return err != nil || len(permissions) > 0
+}
+
+// GetStatus returns the 2FA setup of a user
+func (s *MfaService) GetStatus(ctx context.Context, userID uuid.UUID) (*MfaStatus, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := s.recoveryRepo.CountUnused(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MfaStatus{
+		Enabled:           user.TotpEnabledAt != nil,
+		Required:          s.IsRequired(ctx, user),
+		RecoveryCodesLeft: left,
+	}, nil
+}
+
+// StartEnrollment generates a new TOTP secret, it only takes effect once confirmed with a code
+func (s *MfaService) StartEnrollment(ctx context.Context, userID uuid.UUID) (*MfaEnrollment, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TotpEnabledAt != nil {
+		return nil, ErrMfaAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.userRepo.SetTotpSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &MfaEnrollment{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(secret, s.cfg.Issuer, user.Username),
+	}, nil
+}
+
+// ConfirmEnrollment enables TOTP when the code matches the pending secret and returns the recovery codes,
+// they are shown this once
+func (s *MfaService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TotpEnabledAt != nil {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	if user.TotpSecret == "" {
+		return nil, ErrMfaEnrollmentMissing
+	}
+
+	step, ok := totp.Validate(user.TotpSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidMfaCode
+	}
+
+	if err = s.userRepo.EnableTotp(ctx, userID, step); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+// Verify accepts a TOTP code or an unused recovery code of a user with 2FA enabled, each works once
+func (s *MfaService) Verify(ctx context.Context, user *models.User, code string) error {
+	if user.TotpEnabledAt == nil {
+		return ErrMfaNotEnabled
+	}
+
+	if step, ok := totp.Validate(user.TotpSecret, code, time.Now(), totpSkew); ok {
+		accepted, err := s.userRepo.UseTotpStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return ErrInvalidMfaCode
+		}
+		return nil
+	}
+
+	used, err := s.recoveryRepo.Use(ctx, user.ID, securetoken.Hash(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMfaCode
+	}
+	return nil
+}
+
+// verifyThrottled is Verify for signed in users, wrong codes count against the username like failed logins
+// so a stolen session cannot guess its way to turning 2FA off
+func (s *MfaService) verifyThrottled(ctx context.Context, user *models.User, code string) error {
+	if err := s.throttle.Check(ctx, user.Username, ""); err != nil {
+		return err
+	}
+
+	err := s.Verify(ctx, user, code)
+	if errors.Is(err, ErrInvalidMfaCode) {
+		if err := s.throttle.Fail(ctx, user.Username, "", ""); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Disable turns TOTP off after checking a current code, roles that must use 2FA cannot turn it off
+func (s *MfaService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.IsRequired(ctx, user) {
+		return ErrMfaMandatory
+	}
+	if err = s.verifyThrottled(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err = s.userRepo.DisableTotp(ctx, userID); err != nil {
+		return err
+	}
+	return s.recoveryRepo.DeleteForUser(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a current code
+func (s *MfaService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.verifyThrottled(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+// issueRecoveryCodes stores hashes of new codes formatted as xxxx-xxxx-xxxx-xxxx (80 bits each)
+func (s *MfaService) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, 0, s.cfg.RecoveryCodes)
+	hashes := make([]string, 0, s.cfg.RecoveryCodes)
+	for i := 0; i < s.cfg.RecoveryCodes; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))
+		codes = append(codes, raw[0:4]+"-"+raw[4:8]+"-"+raw[8:12]+"-"+raw[12:16])
+		hashes = append(hashes, securetoken.Hash(raw))
+	}
+
+	if err := s.recoveryRepo.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -2,9 +2,52 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/enrichment"
+	"itv-movie/internal/pkg/utils/textutil"
+	"itv-movie/internal/pkg/video"
 	"itv-movie/internal/storage/database/repositories"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidTrailerURL = errors.New("invalid trailer URL")
+	ErrInvalidPosterURL  = errors.New("invalid poster URL")
+	ErrInvalidImdbID     = errors.New("invalid IMDb ID, expected format tt1234567")
+	ErrDuplicateExternal = errors.New("another movie already uses this IMDb or TMDB ID")
+	ErrInvalidMerge      = errors.New("survivor must not be among the duplicates and at least one duplicate is required")
+)
+
+// DuplicateMovie is the short form of a movie in a duplicate pair
+type DuplicateMovie struct {
+	ID       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	Year     int       `json:"year"`
+	Director string    `json:"director"`
+}
+
+// DuplicatePair is a pair of likely duplicates with the scores that matched them
+type DuplicatePair struct {
+	Movies        [2]DuplicateMovie `json:"movies"`
+	Score         float64           `json:"score"`
+	TitleScore    float64           `json:"titleScore"`
+	YearScore     float64           `json:"yearScore"`
+	DirectorScore float64           `json:"directorScore"`
+}
+
+// weights of the duplicate score components, they sum up to 1
+const (
+	duplicateTitleWeight    = 0.6
+	duplicateYearWeight     = 0.2
+	duplicateDirectorWeight = 0.2
+
+	// maxDuplicateCandidates bounds the candidate pairs pulled from the database
+	maxDuplicateCandidates = 5000
 )
 
 // MovieService handles business logic for movies
@@ -13,6 +56,9 @@ type MovieService struct {
 	languageRepo *repositories.LanguageRepository
 	countryRepo  *repositories.CountryRepository
 	genreRepo    *repositories.GenreRepository
+	trailers     *video.Resolver
+	slugService  *SlugService
+	similar      *SimilarService
 }
 
 // NewMovieService creates a new movie service
@@ -21,25 +67,51 @@ func NewMovieService(
 	languageRepo *repositories.LanguageRepository,
 	countryRepo *repositories.CountryRepository,
 	genreRepo *repositories.GenreRepository,
+	trailers *video.Resolver,
+	slugService *SlugService,
+	similar *SimilarService,
 ) *MovieService {
 	return &MovieService{
 		movieRepo:    movieRepo,
 		languageRepo: languageRepo,
 		countryRepo:  countryRepo,
 		genreRepo:    genreRepo,
+		trailers:     trailers,
+		slugService:  slugService,
+		similar:      similar,
 	}
 }
 
 func (s *MovieService) CreateMovie(ctx context.Context, movie *models.Movie) (*models.Movie, error) {
+	if err := s.validateLinks(ctx, movie); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateExternalIDs(ctx, movie); err != nil {
+		return nil, err
+	}
+
+	slug, err := s.slugService.Generate(ctx, models.MovieSlugEntity, MovieSlugBase(movie), movie.ID)
+	if err != nil {
+		return nil, err
+	}
+	movie.Slug = slug
+
 	createdMovie, err := s.movieRepo.Create(ctx, movie)
 	if err != nil {
+		// a concurrent request may have taken the ID since the check
+		if isDuplicateKey(err) {
+			return nil, ErrDuplicateExternal
+		}
 		return nil, err
 	}
+	s.similar.InvalidateAll()
 
 	return createdMovie, nil
 }
 
-func (s *MovieService) GetAllMovies(ctx context.Context, page, limit int) ([]*models.Movie, error) {
+// GetAllMovies returns a page of movies, drafts only when drafts is set
+func (s *MovieService) GetAllMovies(ctx context.Context, page, limit int, drafts bool) ([]*models.Movie, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -47,7 +119,7 @@ func (s *MovieService) GetAllMovies(ctx context.Context, page, limit int) ([]*mo
 		limit = 10 // Default limit
 	}
 
-	movies, err := s.movieRepo.GetAll(ctx, page, limit)
+	movies, err := s.movieRepo.GetAll(ctx, page, limit, drafts)
 	if err != nil {
 		return nil, err
 	}
@@ -64,29 +136,90 @@ func (s *MovieService) GetMovie(ctx context.Context, id uuid.UUID) (*models.Movi
 	return movie, nil
 }
 
+// GetMovieBySlug finds a movie by its current slug or an old one, currentSlug is set when the slug is outdated
+func (s *MovieService) GetMovieBySlug(ctx context.Context, slug string) (movie *models.Movie, currentSlug string, err error) {
+	movie, err = s.movieRepo.GetBySlug(ctx, slug)
+	if err == nil || !isNotFound(err) {
+		return movie, "", err
+	}
+
+	id, err := s.slugService.ResolveHistory(ctx, models.MovieSlugEntity, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	movie, err = s.movieRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return movie, movie.Slug, nil
+}
+
+// ResolveRedirect returns the surviving movie ID for an ID that was merged away
+func (s *MovieService) ResolveRedirect(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	return s.movieRepo.GetRedirect(ctx, id)
+}
+
 func (s *MovieService) UpdateMovie(ctx context.Context, movie *models.Movie) (*models.Movie, error) {
-	_, err := s.movieRepo.GetByID(ctx, movie.ID)
+	existing, err := s.movieRepo.GetByID(ctx, movie.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	// only re-check links that changed, so old data doesn't block unrelated edits
+	if existing.TrailerURL != movie.TrailerURL || existing.PosterURL != movie.PosterURL {
+		if err = s.validateLinks(ctx, movie); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = s.validateExternalIDs(ctx, movie); err != nil {
+		return nil, err
+	}
+
+	if existing.Title != movie.Title || existing.Year != movie.Year || movie.Slug == "" {
+		if movie.Slug, err = s.slugService.Rename(ctx, models.MovieSlugEntity, existing.Slug, MovieSlugBase(movie), movie.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	updatedMovie, err := s.movieRepo.Update(ctx, movie)
 	if err != nil {
+		if isDuplicateKey(err) {
+			return nil, ErrDuplicateExternal
+		}
 		return nil, err
 	}
+	s.similar.Invalidate(movie.ID)
 
 	return updatedMovie, nil
 }
 
 func (s *MovieService) DeleteMovie(ctx context.Context, id uuid.UUID) error {
-	return s.movieRepo.Delete(ctx, id)
+	if err := s.movieRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.similar.Invalidate(id)
+
+	return nil
+}
+
+// ReplaceCredits replaces the cast and crew of a movie
+func (s *MovieService) ReplaceCredits(ctx context.Context, id uuid.UUID, credits []models.MovieCredit) error {
+	if err := s.movieRepo.ReplaceCredits(ctx, id, credits); err != nil {
+		return err
+	}
+	s.similar.Invalidate(id)
+
+	return nil
 }
 
-func (s *MovieService) GetTotalMovieCount(ctx context.Context) (int, error) {
-	return s.movieRepo.Count(ctx)
+func (s *MovieService) GetTotalMovieCount(ctx context.Context, drafts bool) (int, error) {
+	return s.movieRepo.Count(ctx, drafts)
 }
 
-func (s *MovieService) SearchMovies(ctx context.Context, query string, page, limit int) ([]*models.Movie, int, error) {
+func (s *MovieService) SearchMovies(ctx context.Context, filter repositories.MovieFilter, page, limit int) ([]*models.Movie, int, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -94,12 +227,12 @@ func (s *MovieService) SearchMovies(ctx context.Context, query string, page, lim
 		limit = 10 // Default limit
 	}
 
-	movies, err := s.movieRepo.Search(ctx, query, page, limit)
+	movies, err := s.movieRepo.Search(ctx, filter, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := s.movieRepo.SearchCount(ctx, query)
+	total, err := s.movieRepo.SearchCount(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -118,3 +251,188 @@ func (s *MovieService) GetGenreByName(ctx context.Context, name string) (*models
 func (s *MovieService) GetCountryByCode(ctx context.Context, code string) (*models.Country, error) {
 	return s.countryRepo.GetByCode(ctx, code)
 }
+
+// GetTrailer returns the normalized trailer of a movie, with oEmbed metadata when enabled.
+// A draft movie is not found unless drafts is set
+func (s *MovieService) GetTrailer(ctx context.Context, id uuid.UUID, drafts bool) (*video.Video, *video.OEmbed, error) {
+	movie, err := s.movieRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if movie.PublishedAt == nil && !drafts {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	trailer, err := s.trailers.Resolve(movie.TrailerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidTrailerURL, err)
+	}
+
+	if !s.trailers.FetchOEmbedEnabled() {
+		return trailer, nil, nil
+	}
+
+	meta, err := s.trailers.FetchOEmbed(ctx, trailer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return trailer, meta, nil
+}
+
+// validateLinks checks poster and trailer URLs and stores the normalized trailer reference on the movie
+func (s *MovieService) validateLinks(ctx context.Context, movie *models.Movie) error {
+	// posters uploaded through the media endpoint are served by us under a relative path
+	if movie.PosterURL != "" && !strings.HasPrefix(movie.PosterURL, "/api/v1/media/") {
+		if _, err := video.ParseHTTPURL(movie.PosterURL); err != nil {
+			return ErrInvalidPosterURL
+		}
+	}
+
+	if movie.TrailerURL == "" {
+		movie.TrailerProvider = ""
+		movie.TrailerVideoID = ""
+		return nil
+	}
+
+	trailer, err := s.trailers.Resolve(movie.TrailerURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTrailerURL, err)
+	}
+
+	if s.trailers.FetchOEmbedEnabled() {
+		if _, err = s.trailers.FetchOEmbed(ctx, trailer); err != nil {
+			if errors.Is(err, video.ErrVideoNotFound) {
+				return fmt.Errorf("%w: %w", ErrInvalidTrailerURL, err)
+			}
+			// provider outages should not block editors, the link itself is well-formed
+		}
+	}
+
+	movie.TrailerURL = trailer.WatchURL
+	movie.TrailerProvider = trailer.Provider
+	movie.TrailerVideoID = trailer.ID
+
+	return nil
+}
+
+// validateExternalIDs checks the IMDb ID format and that no other movie uses the same external IDs
+func (s *MovieService) validateExternalIDs(ctx context.Context, movie *models.Movie) error {
+	if movie.ImdbID != nil && !enrichment.IsValidImdbID(*movie.ImdbID) {
+		return ErrInvalidImdbID
+	}
+
+	taken, err := s.movieRepo.ExternalIDTaken(ctx, movie.ImdbID, movie.TmdbID, movie.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrDuplicateExternal
+	}
+
+	return nil
+}
+
+// FindDuplicates scores candidate pairs by normalized title, year and director similarity
+func (s *MovieService) FindDuplicates(ctx context.Context, threshold float64, limit int) ([]DuplicatePair, error) {
+	if threshold <= 0 || threshold > 1 {
+		threshold = 0.8
+	}
+	if limit < 1 {
+		limit = 50
+	}
+
+	candidates, err := s.movieRepo.FindDuplicateCandidates(ctx, maxDuplicateCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]DuplicatePair, 0)
+	for _, candidate := range candidates {
+		pair := DuplicatePair{
+			Movies: [2]DuplicateMovie{
+				{ID: candidate.FirstID, Title: candidate.FirstTitle, Year: candidate.FirstYear, Director: candidate.FirstDirector},
+				{ID: candidate.SecondID, Title: candidate.SecondTitle, Year: candidate.SecondYear, Director: candidate.SecondDirector},
+			},
+			TitleScore:    textutil.Similarity(textutil.NormalizeTitle(candidate.FirstTitle), textutil.NormalizeTitle(candidate.SecondTitle)),
+			YearScore:     yearSimilarity(candidate.FirstYear, candidate.SecondYear),
+			DirectorScore: directorSimilarity(candidate.FirstDirector, candidate.SecondDirector),
+		}
+		pair.Score = duplicateTitleWeight*pair.TitleScore +
+			duplicateYearWeight*pair.YearScore +
+			duplicateDirectorWeight*pair.DirectorScore
+
+		if pair.Score >= threshold {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Score > pairs[j].Score
+	})
+
+	if len(pairs) > limit {
+		pairs = pairs[:limit]
+	}
+
+	return pairs, nil
+}
+
+// MergeMovies folds the duplicates into the survivor, see MovieRepository.Merge
+func (s *MovieService) MergeMovies(ctx context.Context, survivorID uuid.UUID, duplicateIDs []uuid.UUID) (*models.Movie, error) {
+	if len(duplicateIDs) == 0 {
+		return nil, ErrInvalidMerge
+	}
+
+	seen := make(map[uuid.UUID]bool, len(duplicateIDs))
+	unique := make([]uuid.UUID, 0, len(duplicateIDs))
+	for _, id := range duplicateIDs {
+		if id == survivorID {
+			return nil, ErrInvalidMerge
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if err := s.movieRepo.Merge(ctx, survivorID, unique); err != nil {
+		return nil, err
+	}
+	s.similar.Invalidate(append(unique, survivorID)...)
+
+	return s.movieRepo.GetByID(ctx, survivorID)
+}
+
+func yearSimilarity(a, b int) float64 {
+	if a == 0 || b == 0 {
+		return 0.5 // unknown year neither confirms nor rules out a duplicate
+	}
+
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+
+	switch diff {
+	case 0:
+		return 1
+	case 1:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+func directorSimilarity(a, b string) float64 {
+	a, b = strings.ToLower(strings.TrimSpace(a)), strings.ToLower(strings.TrimSpace(b))
+	if a == "" || b == "" {
+		return 0.5
+	}
+	return textutil.Similarity(a, b)
+}
+
+// isDuplicateKey reports whether err is a unique index violation, translated by gorm
+func isDuplicateKey(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/oidc"
+	"itv-movie/internal/pkg/utils/constants"
+	"itv-movie/internal/pkg/utils/securetoken"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidOidcState     = errors.New("login state is invalid, expired or already used")
+	ErrOidcEmailNotVerified = errors.New("the provider did not report a verified email address")
+	ErrOidcEmailConflict    = errors.New("an account with this email exists but its email is not verified")
+)
+
+// OidcLogin is where to send the browser to start a login at a provider. State has to be kept in the
+// browser (a cookie) until the callback, RedirectURL tells where the callback lands
+type OidcLogin struct {
+	AuthURL     string
+	State       string
+	RedirectURL string
+	ExpiresAt   time.Time
+}
+
+// OidcService signs users in through external OpenID Connect providers. A provider account is linked
+// to the user with the same verified email, or a new USER account is created for it
+type OidcService struct {
+	identityRepo *repositories.UserIdentityRepository
+	userRepo     *repositories.UserRepository
+	providers    *oidc.Registry
+	auth         *AuthService
+	stateTTL     time.Duration
+	log          *slog.Logger
+}
+
+// NewOidcService creates a new OIDC service
+func NewOidcService(
+	identityRepo *repositories.UserIdentityRepository,
+	userRepo *repositories.UserRepository,
+	providers *oidc.Registry,
+	auth *AuthService,
+	cfg *config.Config,
+	log *slog.Logger,
+) *OidcService {
+	stateTTL := time.Duration(cfg.Internal.Oidc.StateTTL) * time.Second
+	if stateTTL <= 0 {
+		stateTTL = 10 * time.Minute
+	}
+
+	return &OidcService{
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		providers:    providers,
+		auth:         auth,
+		stateTTL:     stateTTL,
+		log:          log,
+	}
+}
+
+// Providers lists the configured provider names
+func (s *OidcService) Providers() []string {
+	return s.providers.Names()
+}
+
+// RedirectURL returns the callback URL registered for the provider
+func (s *OidcService) RedirectURL(providerName string) (string, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return "", err
+	}
+	return provider.RedirectURL(), nil
+}
+
+// StartLogin stores a pending login with a fresh state, nonce and PKCE verifier and returns the
+// authorization URL of the provider
+func (s *OidcService) StartLogin(ctx context.Context, providerName string) (*OidcLogin, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := securetoken.Generate(48)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.stateTTL)
+	if err = s.identityRepo.SaveState(ctx, &models.OidcState{
+		StateHash:    securetoken.Hash(state),
+		Provider:     provider.Name(),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &OidcLogin{AuthURL: authURL, State: state, RedirectURL: provider.RedirectURL(), ExpiresAt: expiresAt}, nil
+}
+
+// Callback finishes a login coming back from the provider: it redeems the code, verifies the ID token
+// and signs in the linked user, the result may be an MFA challenge like a password login. browserState
+// is the state kept by the browser that started the login, it must equal the returned state so a
+// callback URL crafted by someone else cannot sign the browser into their account
+func (s *OidcService) Callback(ctx context.Context, providerName, state, browserState, code, locale, userAgent, ipAddress string) (*LoginResult, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, ErrInvalidOidcState
+	}
+
+	pending, err := s.identityRepo.ConsumeState(ctx, securetoken.Hash(state))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrInvalidOidcState
+		}
+		return nil, err
+	}
+	if pending.Provider != provider.Name() {
+		return nil, ErrInvalidOidcState
+	}
+
+	tokens, err := provider.Exchange(ctx, code, pending.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, pending.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(ctx, provider.Name(), claims, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.auth.LoginExternal(ctx, user, userAgent, ipAddress)
+}
+
+// resolveUser finds the user linked to the provider account, linking or creating one on the first login
+func (s *OidcService) resolveUser(ctx context.Context, providerName string, claims *oidc.IDTokenClaims, locale string) (*models.User, error) {
+	identity, err := s.identityRepo.GetByProviderSubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		if err = s.identityRepo.Touch(ctx, identity, claims.Email); err != nil {
+			return nil, err
+		}
+		return &identity.User, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return nil, ErrOidcEmailNotVerified
+	}
+
+	now := time.Now()
+	identity = &models.UserIdentity{
+		Provider:    providerName,
+		Subject:     claims.Subject,
+		Email:       email,
+		LastLoginAt: &now,
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err == nil {
+		// linking to an account whose address was never confirmed would hand it to whoever registered it
+		if user.EmailVerifiedAt == nil {
+			return nil, ErrOidcEmailConflict
+		}
+		identity.UserID = user.ID
+		if err = s.identityRepo.Create(ctx, identity); err != nil {
+			return nil, err
+		}
+		s.log.Info("linked OIDC identity", "provider", providerName, "user_id", user.ID)
+		return user, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+
+	user, err = s.newUser(ctx, claims, email, locale)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.identityRepo.CreateWithUser(ctx, user, identity); err != nil {
+		return nil, err
+	}
+	s.log.Info("created user from OIDC identity", "provider", providerName, "user_id", user.ID)
+
+	return user, nil
+}
+
+// newUser builds a USER account for a provider login. It gets a random password, a password can be
+// set later through the password reset
+func (s *OidcService) newUser(ctx context.Context, claims *oidc.IDTokenClaims, email, locale string) (*models.User, error) {
+	username, err := s.availableUsername(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+	if firstName == "" {
+		firstName = username
+	}
+
+	now := time.Now()
+	return &models.User{
+		FirstName:       firstName,
+		LastName:        strings.TrimSpace(lastName),
+		Username:        username,
+		Email:           email,
+		Password:        password,
+		Role:            constants.UserRole,
+		Active:          true,
+		Locale:          locale,
+		EmailVerifiedAt: &now,
+	}, nil
+}
+
+// availableUsername derives a free username from the local part of the email
+func (s *OidcService) availableUsername(ctx context.Context, email string) (string, error) {
+	local, _, _ := strings.Cut(email, "@")
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		default:
+			return -1
+		}
+	}, strings.ToLower(local))
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		_, err := s.userRepo.GetByUsername(ctx, candidate)
+		if isNotFound(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		suffix, err := securetoken.Generate(3)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "-" + strings.ToLower(suffix)
+	}
+
+	return "", fmt.Errorf("no free username for %q", base)
+}
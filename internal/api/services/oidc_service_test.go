@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"itv-movie/internal/config"
+	"itv-movie/internal/pkg/oidc"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// TestCallbackRequiresBrowserState checks that a callback is refused before the state is consumed
+// or the provider is contacted unless the state cookie of the browser matches
+func TestCallbackRequiresBrowserState(t *testing.T) {
+	var calls int32
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.NotFound(w, r)
+	}))
+	defer issuer.Close()
+
+	registry, err := oidc.NewRegistry(&config.Config{Internal: config.Internal{Oidc: config.Oidc{
+		Providers: []config.OidcProvider{{
+			Name:        "mock",
+			Issuer:      issuer.URL,
+			ClientID:    "movie-service",
+			RedirectURL: "http://localhost:8080/api/v1/auth/oidc/mock/callback",
+		}},
+	}}})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	// no repositories, the checks under test must fail before any of them is used
+	service := &OidcService{providers: registry}
+
+	tests := []struct {
+		name         string
+		provider     string
+		state        string
+		browserState string
+		want         error
+	}{
+		{name: "unknown provider", provider: "other", state: "s", browserState: "s", want: oidc.ErrUnknownProvider},
+		{name: "no state cookie", provider: "mock", state: "s", browserState: "", want: ErrInvalidOidcState},
+		{name: "state of another browser", provider: "mock", state: "attacker", browserState: "victim", want: ErrInvalidOidcState},
+		{name: "empty state and cookie", provider: "mock", state: "", browserState: "", want: ErrInvalidOidcState},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Callback(context.Background(), tt.provider, tt.state, tt.browserState, "code", "en", "test", "127.0.0.1")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if calls != 0 {
+		t.Errorf("the provider was contacted %d times", calls)
+	}
+}
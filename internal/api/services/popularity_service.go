@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/utils/securetoken"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownEventType = errors.New("unknown event type")
+	ErrUnknownCountry   = errors.New("country not found")
+	ErrTooManyEvents    = errors.New("too many events, slow down")
+)
+
+// PopularityService ingests engagement events and keeps the trending and popularity scores up to date
+type PopularityService struct {
+	popularityRepo *repositories.PopularityRepository
+	movieRepo      *repositories.MovieRepository
+	countryRepo    *repositories.CountryRepository
+	cfg            config.Popularity
+	visitorKey     []byte
+	log            *slog.Logger
+}
+
+// NewPopularityService creates a new popularity service
+func NewPopularityService(
+	popularityRepo *repositories.PopularityRepository,
+	movieRepo *repositories.MovieRepository,
+	countryRepo *repositories.CountryRepository,
+	cfg *config.Config,
+	log *slog.Logger,
+) (*PopularityService, error) {
+	popularityCfg := cfg.Internal.Popularity
+	if popularityCfg.Interval < 1 {
+		popularityCfg.Interval = 600
+	}
+	if popularityCfg.TrendingHalfLife < 1 {
+		popularityCfg.TrendingHalfLife = 24
+	}
+	if popularityCfg.PopularHalfLife < 1 {
+		popularityCfg.PopularHalfLife = 720
+	}
+	if popularityCfg.Window < 1 {
+		popularityCfg.Window = 90
+	}
+	if popularityCfg.ViewDedupe < 1 {
+		popularityCfg.ViewDedupe = 1800
+	}
+	if popularityCfg.EventRateLimit < 1 {
+		popularityCfg.EventRateLimit = 30
+	}
+	if len(popularityCfg.Weights) == 0 {
+		popularityCfg.Weights = map[string]float64{
+			models.ViewEvent:   1,
+			models.ReviewEvent: 5,
+		}
+	}
+
+	// a plain hash of an IPv4 address is reversed by trying them all, so addresses are keyed
+	visitorKey := []byte(popularityCfg.VisitorSecret)
+	if len(visitorKey) == 0 {
+		visitorKey = make([]byte, 32)
+		if _, err := rand.Read(visitorKey); err != nil {
+			return nil, err
+		}
+		log.Warn("popularity.visitor_secret is not set, anonymous views are deduplicated per instance until a restart")
+	}
+
+	return &PopularityService{
+		popularityRepo: popularityRepo,
+		movieRepo:      movieRepo,
+		countryRepo:    countryRepo,
+		cfg:            popularityCfg,
+		visitorKey:     visitorKey,
+		log:            log,
+	}, nil
+}
+
+// RecordView stores a view sent by a client. userID is nil for anonymous viewers, who are told apart by
+// their IP address, and countryCode may be empty when the viewer's country is unknown. A visitor sending
+// more than the rate limit gets ErrTooManyEvents, and a repeated view of the same movie within the dedupe
+// window is not stored: the returned event is nil then
+func (s *PopularityService) RecordView(ctx context.Context, movieID uuid.UUID, userID *uuid.UUID, ipAddress, countryCode string) (*models.MovieEvent, error) {
+	visitor := "ip:" + securetoken.HMAC(s.visitorKey, ipAddress)
+	if userID != nil {
+		visitor = "user:" + userID.String()
+	}
+
+	sent, err := s.popularityRepo.CountVisitorEvents(ctx, visitor, time.Now().Add(-time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	if sent >= int64(s.cfg.EventRateLimit) {
+		return nil, ErrTooManyEvents
+	}
+
+	return s.record(ctx, movieID, userID, visitor, models.ViewEvent, countryCode, time.Duration(s.cfg.ViewDedupe)*time.Second)
+}
+
+// RecordReview counts a user's review of a movie, once per user and movie within the scoring window so
+// editing a review does not push the movie again
+func (s *PopularityService) RecordReview(ctx context.Context, userID, movieID uuid.UUID) error {
+	window := time.Duration(s.cfg.Window) * 24 * time.Hour
+	_, err := s.record(ctx, movieID, &userID, "user:"+userID.String(), models.ReviewEvent, "", window)
+	return err
+}
+
+// record stores an event unless the visitor already has one of the type for the movie within dedupe,
+// the returned event is nil when it was a duplicate
+func (s *PopularityService) record(ctx context.Context, movieID uuid.UUID, userID *uuid.UUID, visitor, eventType, countryCode string, dedupe time.Duration) (*models.MovieEvent, error) {
+	if _, ok := s.cfg.Weights[eventType]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+
+	if _, err := s.movieRepo.GetByID(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	duplicate, err := s.popularityRepo.HasVisitorEvent(ctx, movieID, visitor, eventType, time.Now().Add(-dedupe))
+	if err != nil {
+		return nil, err
+	}
+	if duplicate {
+		return nil, nil
+	}
+
+	countryCode = strings.ToUpper(strings.TrimSpace(countryCode))
+	if countryCode != "" {
+		if _, err := s.countryRepo.GetByCode(ctx, countryCode); err != nil {
+			if isNotFound(err) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownCountry, countryCode)
+			}
+			return nil, err
+		}
+	}
+
+	event := &models.MovieEvent{
+		MovieID:     movieID,
+		UserID:      userID,
+		Type:        eventType,
+		CountryCode: countryCode,
+		Visitor:     visitor,
+	}
+	if err := s.popularityRepo.AddEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// Recompute rebuilds the trending and popularity scores now
+func (s *PopularityService) Recompute(ctx context.Context) error {
+	started := time.Now()
+
+	computed, rows, err := s.popularityRepo.Recompute(ctx, repositories.PopularityParams{
+		Weights:          s.cfg.Weights,
+		TrendingHalfLife: float64(s.cfg.TrendingHalfLife),
+		PopularHalfLife:  float64(s.cfg.PopularHalfLife),
+		WindowDays:       s.cfg.Window,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !computed {
+		s.log.Info("popularity is being recomputed by another instance, skipped")
+		return nil
+	}
+
+	s.log.Info("popularity recomputed", "scores", rows, "took", time.Since(started))
+	return nil
+}
+
+// Run recomputes the scores every configured interval until ctx is cancelled
+func (s *PopularityService) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Recompute(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error("failed to recompute popularity", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
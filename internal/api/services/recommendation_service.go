@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"time"
+)
+
+// Sources of a recommendation
+const (
+	CollaborativeSource = "collaborative" // liked by users who liked the same movies
+	PopularSource       = "popular"       // popular in the preferred genres, for users without enough history
+)
+
+// RecommendedMovie is a personal recommendation with its score and where it came from
+type RecommendedMovie struct {
+	Score  float64       `json:"score"`
+	Source string        `json:"source"`
+	Movie  *models.Movie `json:"movie"`
+}
+
+// RecommendationService serves personal recommendations from the item-item co-occurrence model
+// and recomputes the model in the background
+type RecommendationService struct {
+	recommendationRepo *repositories.RecommendationRepository
+	activityRepo       *repositories.ActivityRepository
+	movieRepo          *repositories.MovieRepository
+	cfg                config.Recommendations
+	log                *slog.Logger
+}
+
+// NewRecommendationService creates a new recommendation service
+func NewRecommendationService(
+	recommendationRepo *repositories.RecommendationRepository,
+	activityRepo *repositories.ActivityRepository,
+	movieRepo *repositories.MovieRepository,
+	cfg *config.Config,
+	log *slog.Logger,
+) *RecommendationService {
+	recommendationCfg := cfg.Internal.Recommendations
+	if recommendationCfg.Interval < 1 {
+		recommendationCfg.Interval = 3600
+	}
+	if recommendationCfg.MaxRelated < 1 {
+		recommendationCfg.MaxRelated = 50
+	}
+	if recommendationCfg.PopularWindow < 1 {
+		recommendationCfg.PopularWindow = 30
+	}
+	if recommendationCfg.Limit < 1 {
+		recommendationCfg.Limit = 20
+	}
+	if recommendationCfg.MaxLimit < recommendationCfg.Limit {
+		recommendationCfg.MaxLimit = recommendationCfg.Limit
+	}
+
+	return &RecommendationService{
+		recommendationRepo: recommendationRepo,
+		activityRepo:       activityRepo,
+		movieRepo:          movieRepo,
+		cfg:                recommendationCfg,
+		log:                log,
+	}
+}
+
+// GetRecommendations returns up to limit movies for the user, best first. Collaborative recommendations
+// come first, users without enough history get popular movies of their preferred genres to fill up
+func (s *RecommendationService) GetRecommendations(ctx context.Context, userID uuid.UUID, limit int) ([]RecommendedMovie, error) {
+	if limit < 1 {
+		limit = s.cfg.Limit
+	}
+	if limit > s.cfg.MaxLimit {
+		limit = s.cfg.MaxLimit
+	}
+
+	collaborative, err := s.recommendationRepo.ForUser(ctx, userID, s.cfg.MinRating, s.cfg.WatchedWeight, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]repositories.SimilarScore, 0, limit)
+	sources := make(map[uuid.UUID]string, limit)
+	for _, score := range collaborative {
+		scores = append(scores, score)
+		sources[score.ID] = CollaborativeSource
+	}
+
+	if len(scores) < limit {
+		popular, err := s.popular(ctx, userID, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, score := range popular {
+			if len(scores) == limit {
+				break
+			}
+			if _, ok := sources[score.ID]; !ok {
+				scores = append(scores, score)
+				sources[score.ID] = PopularSource
+			}
+		}
+	}
+
+	if len(scores) == 0 {
+		return []RecommendedMovie{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(scores))
+	for i, score := range scores {
+		ids[i] = score.ID
+	}
+	movies, err := s.movieRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Movie, len(movies))
+	for _, movie := range movies {
+		byID[movie.ID] = movie
+	}
+
+	recommended := make([]RecommendedMovie, 0, len(scores))
+	for _, score := range scores {
+		if movie, ok := byID[score.ID]; ok {
+			recommended = append(recommended, RecommendedMovie{Score: score.Score, Source: sources[score.ID], Movie: movie})
+		}
+	}
+
+	return recommended, nil
+}
+
+// popular returns popular movies of the user's preferred genres, or of all genres when none are set
+// or the preferred ones have nothing left to offer
+func (s *RecommendationService) popular(ctx context.Context, userID uuid.UUID, limit int) ([]repositories.SimilarScore, error) {
+	since := time.Now().AddDate(0, 0, -s.cfg.PopularWindow)
+
+	genres, err := s.activityRepo.GetPreferredGenres(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(genres) > 0 {
+		genreIDs := make([]uuid.UUID, len(genres))
+		for i, genre := range genres {
+			genreIDs[i] = genre.ID
+		}
+
+		scores, err := s.recommendationRepo.Popular(ctx, userID, genreIDs, since, s.cfg.MinRating, limit)
+		if err != nil || len(scores) > 0 {
+			return scores, err
+		}
+	}
+
+	return s.recommendationRepo.Popular(ctx, userID, nil, since, s.cfg.MinRating, limit)
+}
+
+// Recompute rebuilds the co-occurrence model now
+func (s *RecommendationService) Recompute(ctx context.Context) error {
+	started := time.Now()
+
+	computed, pairs, err := s.recommendationRepo.Recompute(ctx, s.cfg.MinRating, s.cfg.MaxRelated)
+	if err != nil {
+		return err
+	}
+
+	if !computed {
+		s.log.Info("recommendations are being recomputed by another instance, skipped")
+		return nil
+	}
+
+	s.log.Info("recommendations recomputed", "pairs", pairs, "took", time.Since(started))
+	return nil
+}
+
+// Run recomputes the model every configured interval until ctx is cancelled
+func (s *RecommendationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Recompute(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error("failed to recompute recommendations", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/utils/constants"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rolePermissionsTTL bounds how long a replica serves the permissions of a role edited on another one
+const rolePermissionsTTL = 30 * time.Second
+
+var roleNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,49}$`)
+
+var (
+	ErrRoleNotFound    = errors.New("role not found")
+	ErrRoleExists      = errors.New("role with this name already exists")
+	ErrRoleProtected   = errors.New("the ADMIN role always holds every permission and cannot be changed")
+	ErrRoleSystem      = errors.New("seeded roles cannot be deleted")
+	ErrRoleInUse       = errors.New("role is assigned to users")
+	ErrInvalidRoleData = errors.New("invalid role data")
+	ErrLastAdmin       = errors.New("the last admin cannot be given another role")
+	ErrUserNotFound    = errors.New("user not found")
+)
+
+// RoleRequest describes the role to create or the new state of a role
+type RoleRequest struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+type cachedPermissions struct {
+	permissions []string
+	expiresAt   time.Time
+}
+
+// RoleService manages the roles and resolves the permissions of a role for the middleware. The
+// permissions are cached per role for a short time since every authenticated request needs them
+type RoleService struct {
+	roleRepo    *repositories.RoleRepository
+	userRepo    *repositories.UserRepository
+	sessionRepo *repositories.SessionRepository
+	log         *slog.Logger
+
+	mu    sync.Mutex
+	cache map[string]cachedPermissions
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(
+	roleRepo *repositories.RoleRepository,
+	userRepo *repositories.UserRepository,
+	sessionRepo *repositories.SessionRepository,
+	log *slog.Logger,
+) *RoleService {
+	return &RoleService{
+		roleRepo:    roleRepo,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		log:         log,
+		cache:       make(map[string]cachedPermissions),
+	}
+}
+
+// Permissions returns the permission names the role grants, none for an unknown role
+func (s *RoleService) Permissions(ctx context.Context, roleName string) ([]string, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[roleName]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.permissions, nil
+	}
+
+	var permissions []string
+	if roleName == constants.AdminRole {
+		all, err := s.roleRepo.GetPermissions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, permission := range all {
+			permissions = append(permissions, permission.Name)
+		}
+	} else {
+		role, err := s.roleRepo.GetByName(ctx, roleName)
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+		if role != nil {
+			permissions = role.PermissionNames()
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[roleName] = cachedPermissions{permissions: permissions, expiresAt: time.Now().Add(rolePermissionsTTL)}
+	s.mu.Unlock()
+
+	return permissions, nil
+}
+
+func (s *RoleService) invalidate(roleName string) {
+	s.mu.Lock()
+	delete(s.cache, roleName)
+	s.mu.Unlock()
+}
+
+func (s *RoleService) GetPermissions(ctx context.Context) ([]*models.Permission, error) {
+	return s.roleRepo.GetPermissions(ctx)
+}
+
+func (s *RoleService) GetAll(ctx context.Context) ([]*models.Role, error) {
+	return s.roleRepo.GetAll(ctx)
+}
+
+func (s *RoleService) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// Create adds a role, its name is upper case like the seeded ones (e.g. CATALOG_EDITOR)
+func (s *RoleService) Create(ctx context.Context, request RoleRequest) (*models.Role, error) {
+	name := strings.ToUpper(strings.TrimSpace(request.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must be 2-50 upper case letters, digits or underscores", ErrInvalidRoleData)
+	}
+
+	if _, err := s.roleRepo.GetByName(ctx, name); err == nil {
+		return nil, ErrRoleExists
+	} else if !isNotFound(err) {
+		return nil, err
+	}
+
+	permissions, err := s.resolvePermissions(ctx, request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        name,
+		Description: strings.TrimSpace(request.Description),
+		Permissions: permissions,
+	}
+	if err = s.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(name)
+	s.log.Info("role created", "role", name, "permissions", role.PermissionNames())
+	return role, nil
+}
+
+// Update replaces the description and the permissions of a role
+func (s *RoleService) Update(ctx context.Context, name string, request RoleRequest) (*models.Role, error) {
+	if name == constants.AdminRole {
+		return nil, ErrRoleProtected
+	}
+
+	role, err := s.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.resolvePermissions(ctx, request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = strings.TrimSpace(request.Description)
+	role.Permissions = permissions
+	if err = s.roleRepo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(name)
+	s.log.Info("role updated", "role", name, "permissions", role.PermissionNames())
+	return role, nil
+}
+
+// resolvePermissions loads the named permissions, failing on names that do not exist
+func (s *RoleService) resolvePermissions(ctx context.Context, names []string) ([]models.Permission, error) {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+
+	permissions, err := s.roleRepo.FindPermissions(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(permissions) != len(unique) {
+		known := make(map[string]bool, len(permissions))
+		for _, permission := range permissions {
+			known[permission.Name] = true
+		}
+		for _, name := range unique {
+			if !known[name] {
+				return nil, fmt.Errorf("%w: unknown permission %q", ErrInvalidRoleData, name)
+			}
+		}
+	}
+
+	return permissions, nil
+}
+
+// Delete removes a role that is neither seeded nor assigned to anyone
+func (s *RoleService) Delete(ctx context.Context, name string) error {
+	role, err := s.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.System {
+		return ErrRoleSystem
+	}
+
+	users, err := s.roleRepo.CountUsers(ctx, name)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrRoleInUse
+	}
+
+	if err = s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return err
+	}
+
+	s.invalidate(name)
+	s.log.Info("role deleted", "role", name)
+	return nil
+}
+
+// AssignRole gives the user another role and signs them out, so no token carries the old role
+func (s *RoleService) AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	roleName = strings.ToUpper(strings.TrimSpace(roleName))
+	if _, err := s.GetByName(ctx, roleName); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if isNotFound(err) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if user.Role == roleName {
+		return nil
+	}
+
+	// the last admin check and the update run in one transaction, two admins demoting each other
+	// at the same time must not both succeed
+	if user.Role == constants.AdminRole {
+		demoted, err := s.userRepo.DemoteAdmin(ctx, userID, roleName)
+		if err != nil {
+			return err
+		}
+		if !demoted {
+			return ErrLastAdmin
+		}
+	} else if err = s.userRepo.UpdateRole(ctx, userID, roleName); err != nil {
+		return err
+	}
+	if err = s.sessionRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	s.log.Info("role assigned", "user_id", userID, "from", user.Role, "to", roleName)
+	return nil
+}
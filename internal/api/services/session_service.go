@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"itv-movie/internal/pkg/utils/useragent"
+	"itv-movie/internal/storage/database/repositories"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// DeviceSession is a signed in device as shown to its user
+type DeviceSession struct {
+	ID uuid.UUID `json:"id"`
+	useragent.Client
+	UserAgent string    `json:"userAgent"`
+	IPAddress string    `json:"ipAddress"`
+	CreatedAt time.Time `json:"createdAt"` // last sign in or token refresh
+	ExpiresAt time.Time `json:"expiresAt"` // when the refresh token runs out
+	Current   bool      `json:"current"`
+}
+
+// SessionService lets users see and revoke the devices they are signed in on
+type SessionService struct {
+	sessionRepo *repositories.SessionRepository
+	userRepo    *repositories.UserRepository
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(
+	sessionRepo *repositories.SessionRepository,
+	userRepo *repositories.UserRepository,
+) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// GetSessions lists the active sessions of a user, the one of the currentTokenID access token is marked as current
+func (s *SessionService) GetSessions(ctx context.Context, userID uuid.UUID, currentTokenID string) ([]DeviceSession, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.GetActiveSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]DeviceSession, 0, len(sessions))
+	for _, session := range sessions {
+		devices = append(devices, DeviceSession{
+			ID:        session.ID,
+			Client:    useragent.Parse(session.UserAgent),
+			UserAgent: session.UserAgent,
+			IPAddress: session.IPAddress,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.RefreshExpiry,
+			Current:   currentTokenID != "" && session.AccessJTI == currentTokenID,
+		})
+	}
+
+	return devices, nil
+}
+
+// RevokeSession signs the user out on one device
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if isNotFound(err) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if session.UserID != userID || session.IsRevoked {
+		return ErrSessionNotFound
+	}
+
+	return s.sessionRepo.RevokeByID(ctx, sessionID)
+}
+
+// RevokeOtherSessions signs the user out everywhere but the session of the currentTokenID access token
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentTokenID string) (int64, error) {
+	current, err := s.sessionRepo.GetByAccessJTI(ctx, currentTokenID)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, ErrSessionInvalid
+		}
+		return 0, err
+	}
+	if current.UserID != userID {
+		return 0, ErrSessionInvalid
+	}
+
+	return s.sessionRepo.RevokeOthersForUser(ctx, userID, current.ID)
+}
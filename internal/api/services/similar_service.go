@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/config"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database/repositories"
+	"sync"
+	"time"
+)
+
+// SimilarMovie is a movie of the "More like this" rail with its score
+type SimilarMovie struct {
+	Score float64       `json:"score"`
+	Movie *models.Movie `json:"movie"`
+}
+
+// SimilarService scores "More like this" movies and caches the scores per movie.
+// The cache lives in each instance and is only invalidated locally, CacheTTL bounds how long
+// another instance keeps serving scores from before a change
+type SimilarService struct {
+	movieRepo *repositories.MovieRepository
+	cfg       config.Similar
+	weights   repositories.SimilarityWeights
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]similarCacheEntry
+}
+
+type similarCacheEntry struct {
+	scores    []repositories.SimilarScore
+	expiresAt time.Time
+}
+
+// NewSimilarService creates a new similar movies service
+func NewSimilarService(movieRepo *repositories.MovieRepository, cfg *config.Config) *SimilarService {
+	similarCfg := cfg.Internal.Similar
+	if similarCfg.Limit < 1 {
+		similarCfg.Limit = 12
+	}
+	if similarCfg.MaxLimit < similarCfg.Limit {
+		similarCfg.MaxLimit = similarCfg.Limit
+	}
+	if similarCfg.YearWindow < 1 {
+		similarCfg.YearWindow = 10
+	}
+	if similarCfg.CacheTTL < 1 {
+		similarCfg.CacheTTL = 300
+	}
+
+	return &SimilarService{
+		movieRepo: movieRepo,
+		cfg:       similarCfg,
+		weights: repositories.SimilarityWeights{
+			Genres:     similarCfg.Weights.Genres,
+			Countries:  similarCfg.Weights.Countries,
+			Credits:    similarCfg.Weights.Credits,
+			Language:   similarCfg.Weights.Language,
+			Year:       similarCfg.Weights.Year,
+			YearWindow: similarCfg.YearWindow,
+		},
+		cache: make(map[uuid.UUID]similarCacheEntry),
+	}
+}
+
+// GetSimilar returns up to limit published movies most similar to the movie, best first.
+// limit < 1 uses the configured default. A draft movie is not found unless drafts is set
+func (s *SimilarService) GetSimilar(ctx context.Context, id uuid.UUID, limit int, drafts bool) ([]SimilarMovie, error) {
+	if limit < 1 {
+		limit = s.cfg.Limit
+	}
+	if limit > s.cfg.MaxLimit {
+		limit = s.cfg.MaxLimit
+	}
+
+	// 404 for unknown movies rather than an empty rail
+	movie, err := s.movieRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if movie.PublishedAt == nil && !drafts {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	scores, err := s.scores(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) > limit {
+		scores = scores[:limit]
+	}
+	if len(scores) == 0 {
+		return []SimilarMovie{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(scores))
+	for i, score := range scores {
+		ids[i] = score.ID
+	}
+
+	// the movies themselves are not cached, so edits and new images show up right away
+	movies, err := s.movieRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Movie, len(movies))
+	for _, movie := range movies {
+		byID[movie.ID] = movie
+	}
+
+	similar := make([]SimilarMovie, 0, len(scores))
+	for _, score := range scores {
+		// skip movies deleted or unpublished since the scores were cached
+		if movie, ok := byID[score.ID]; ok && movie.PublishedAt != nil {
+			similar = append(similar, SimilarMovie{Score: score.Score, Movie: movie})
+		}
+	}
+
+	return similar, nil
+}
+
+// Invalidate drops the cached scores of the movies and every cached list that contains one of them.
+// It is called whenever a movie changes in a way that may affect its similarity
+func (s *SimilarService) Invalidate(ids ...uuid.UUID) {
+	changed := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		changed[id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for movieID, entry := range s.cache {
+		if changed[movieID] {
+			delete(s.cache, movieID)
+			continue
+		}
+		for _, score := range entry.scores {
+			if changed[score.ID] {
+				delete(s.cache, movieID)
+				break
+			}
+		}
+	}
+}
+
+// InvalidateAll drops every cached list. A new movie, or a change shared by many movies,
+// can enter any list
+func (s *SimilarService) InvalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache = make(map[uuid.UUID]similarCacheEntry)
+}
+
+// scores returns the cached scores of a movie, computing them up to MaxLimit on a miss
+func (s *SimilarService) scores(ctx context.Context, id uuid.UUID) ([]repositories.SimilarScore, error) {
+	s.mu.Lock()
+	entry, ok := s.cache[id]
+	s.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.scores, nil
+	}
+
+	scores, err := s.movieRepo.FindSimilar(ctx, id, s.weights, s.cfg.MaxLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[id] = similarCacheEntry{
+		scores:    scores,
+		expiresAt: time.Now().Add(time.Duration(s.cfg.CacheTTL) * time.Second),
+	}
+	s.mu.Unlock()
+
+	return scores, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/pkg/utils/textutil"
+	"itv-movie/internal/storage/database/repositories"
+	"log/slog"
+	"strconv"
+)
+
+// slugBackfillBatch is how many rows without a slug are processed per query
+const slugBackfillBatch = 500
+
+// SlugService generates unique slugs and resolves old ones through the slug history
+type SlugService struct {
+	slugRepo *repositories.SlugRepository
+	log      *slog.Logger
+}
+
+// NewSlugService creates a new slug service
+func NewSlugService(slugRepo *repositories.SlugRepository, log *slog.Logger) *SlugService {
+	return &SlugService{
+		slugRepo: slugRepo,
+		log:      log,
+	}
+}
+
+// MovieSlugBase is the text a movie slug is built from, e.g. "inception-2010"
+func MovieSlugBase(movie *models.Movie) string {
+	if movie.Year > 0 {
+		return movie.Title + " " + strconv.Itoa(movie.Year)
+	}
+	return movie.Title
+}
+
+// Generate builds a slug from text that no other entity of the type uses, adding -2, -3... on clashes
+func (s *SlugService) Generate(ctx context.Context, entityType, text string, entityID uuid.UUID) (string, error) {
+	base := textutil.Slugify(text)
+	if base == "" {
+		base = entityType
+	}
+
+	model, err := slugModel(entityType)
+	if err != nil {
+		return "", err
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		taken, err := s.slugRepo.IsTaken(ctx, model, entityType, slug, entityID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// Rename generates the slug for new text and keeps the previous slug in history when it changes
+func (s *SlugService) Rename(ctx context.Context, entityType, oldSlug, text string, entityID uuid.UUID) (string, error) {
+	slug, err := s.Generate(ctx, entityType, text, entityID)
+	if err != nil {
+		return "", err
+	}
+
+	if oldSlug != "" && oldSlug != slug {
+		if err = s.slugRepo.AddHistory(ctx, entityType, entityID, oldSlug); err != nil {
+			return "", err
+		}
+	}
+
+	return slug, nil
+}
+
+// ResolveHistory returns the entity that owned the slug before it was renamed
+func (s *SlugService) ResolveHistory(ctx context.Context, entityType, slug string) (uuid.UUID, error) {
+	history, err := s.slugRepo.FindHistory(ctx, entityType, slug)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return history.EntityID, nil
+}
+
+// Backfill gives slugs to rows created before slugs existed. Only one replica backfills at a time,
+// the others skip it
+func (s *SlugService) Backfill(ctx context.Context) error {
+	locked, err := s.slugRepo.WithBackfillLock(ctx, func() error {
+		return s.backfill(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	if !locked {
+		s.log.Debug("slug backfill skipped, another instance is running it")
+	}
+
+	return nil
+}
+
+func (s *SlugService) backfill(ctx context.Context) error {
+	for {
+		var movies []*models.Movie
+		if err := s.slugRepo.FindWithoutSlug(ctx, &movies, slugBackfillBatch); err != nil {
+			return err
+		}
+		for _, movie := range movies {
+			if err := s.backfillOne(ctx, &models.Movie{}, models.MovieSlugEntity, MovieSlugBase(movie), movie.ID); err != nil {
+				return err
+			}
+		}
+		if len(movies) < slugBackfillBatch {
+			break
+		}
+	}
+
+	var genres []*models.Genre
+	if err := s.slugRepo.FindWithoutSlug(ctx, &genres, -1); err != nil {
+		return err
+	}
+	for _, genre := range genres {
+		if err := s.backfillOne(ctx, &models.Genre{}, models.GenreSlugEntity, genre.Name, genre.ID); err != nil {
+			return err
+		}
+	}
+
+	var countries []*models.Country
+	if err := s.slugRepo.FindWithoutSlug(ctx, &countries, -1); err != nil {
+		return err
+	}
+	for _, country := range countries {
+		if err := s.backfillOne(ctx, &models.Country{}, models.CountrySlugEntity, country.Name, country.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SlugService) backfillOne(ctx context.Context, model interface{}, entityType, text string, id uuid.UUID) error {
+	slug, err := s.Generate(ctx, entityType, text, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.slugRepo.SetSlug(ctx, model, id, slug); err != nil {
+		return err
+	}
+
+	s.log.Debug("slug backfilled", "entity", entityType, "id", id, "slug", slug)
+	return nil
+}
+
+func slugModel(entityType string) (interface{}, error) {
+	switch entityType {
+	case models.MovieSlugEntity:
+		return &models.Movie{}, nil
+	case models.GenreSlugEntity:
+		return &models.Genre{}, nil
+	case models.CountrySlugEntity:
+		return &models.Country{}, nil
+	default:
+		return nil, fmt.Errorf("unknown slug entity %q", entityType)
+	}
+}
+
+// isNotFound reports whether err means the record does not exist
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound)
+}
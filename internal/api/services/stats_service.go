@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"itv-movie/internal/storage/database/repositories"
+	"strconv"
+)
+
+var (
+	ErrUnknownReport = errors.New("unknown report, use genres, countries, languages, years, genre-ratings or monthly")
+	ErrInvalidRange  = errors.New("from must be before to")
+)
+
+// Report names accepted by StatsService.Report
+const (
+	GenreReport       = "genres"
+	CountryReport     = "countries"
+	LanguageReport    = "languages"
+	YearReport        = "years"
+	GenreRatingReport = "genre-ratings"
+	MonthlyReport     = "monthly"
+)
+
+// StatsReport is the result of a catalog report, Rows are StatsCount or StatsRating
+type StatsReport struct {
+	Report string      `json:"report"`
+	Rows   interface{} `json:"rows"`
+
+	columns []string
+	records [][]string
+}
+
+// WriteCSV writes the report as CSV with a header row
+func (r *StatsReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(r.columns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(r.records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// StatsService builds catalog reports for admins
+type StatsService struct {
+	statsRepo *repositories.StatsRepository
+}
+
+// NewStatsService creates a new stats service
+func NewStatsService(statsRepo *repositories.StatsRepository) *StatsService {
+	return &StatsService{
+		statsRepo: statsRepo,
+	}
+}
+
+// Report runs the named report over the movies added within rng
+func (s *StatsService) Report(ctx context.Context, name string, rng repositories.StatsRange) (*StatsReport, error) {
+	if rng.From != nil && rng.To != nil && !rng.From.Before(*rng.To) {
+		return nil, ErrInvalidRange
+	}
+
+	var countReport func(context.Context, repositories.StatsRange) ([]repositories.StatsCount, error)
+	keyColumn := "genre"
+
+	switch name {
+	case GenreReport:
+		countReport = s.statsRepo.MoviesPerGenre
+	case CountryReport:
+		countReport, keyColumn = s.statsRepo.MoviesPerCountry, "country"
+	case LanguageReport:
+		countReport, keyColumn = s.statsRepo.MoviesPerLanguage, "language"
+	case YearReport:
+		countReport, keyColumn = s.statsRepo.MoviesPerYear, "year"
+	case MonthlyReport:
+		countReport, keyColumn = s.statsRepo.MoviesAddedPerMonth, "month"
+	case GenreRatingReport:
+		ratings, err := s.statsRepo.AverageRatingPerGenre(ctx, rng)
+		if err != nil {
+			return nil, err
+		}
+
+		report := &StatsReport{Report: name, Rows: ratings, columns: []string{keyColumn, "movies", "average_rating"}}
+		for _, rating := range ratings {
+			report.records = append(report.records, []string{
+				rating.Key,
+				strconv.FormatInt(rating.Movies, 10),
+				strconv.FormatFloat(rating.AverageRating, 'f', 2, 64),
+			})
+		}
+		return report, nil
+	default:
+		return nil, ErrUnknownReport
+	}
+
+	counts, err := countReport(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StatsReport{Report: name, Rows: counts, columns: []string{keyColumn, "movies"}}
+	for _, count := range counts {
+		report.records = append(report.records, []string{count.Key, strconv.FormatInt(count.Count, 10)})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database/repositories"
+	"strings"
+)
+
+var (
+	ErrInvalidTagName  = fmt.Errorf("tag names must be 1 to %d characters without commas", models.MaxTagNameLength)
+	ErrTagNameTaken    = errors.New("another tag already has this name, merge them instead")
+	ErrInvalidTagMerge = errors.New("the survivor cannot be one of the duplicates")
+)
+
+// TagService handles business logic for free-form movie tags
+type TagService struct {
+	tagRepo *repositories.TagRepository
+	similar *SimilarService
+}
+
+// NewTagService creates a new tag service
+func NewTagService(tagRepo *repositories.TagRepository, similar *SimilarService) *TagService {
+	return &TagService{
+		tagRepo: tagRepo,
+		similar: similar,
+	}
+}
+
+// NormalizeTag lower-cases a tag name and collapses its whitespace, so "Time  Travel" and "time travel" are one tag
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// NormalizeTags normalizes the names and drops empty ones and duplicates
+func NormalizeTags(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = NormalizeTag(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+
+	return normalized
+}
+
+// validTagName checks a normalized name, commas separate tags in ?tags= filters
+func validTagName(name string) bool {
+	return name != "" && len([]rune(name)) <= models.MaxTagNameLength && !strings.Contains(name, ",")
+}
+
+// EnsureTags returns the tags with the given names, creating the ones that don't exist yet
+func (s *TagService) EnsureTags(ctx context.Context, names []string) ([]models.Tag, error) {
+	normalized := NormalizeTags(names)
+	for _, name := range normalized {
+		if !validTagName(name) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTagName, name)
+		}
+	}
+
+	return s.tagRepo.Ensure(ctx, normalized)
+}
+
+// Autocomplete suggests tags starting with the given text, the most used first
+func (s *TagService) Autocomplete(ctx context.Context, prefix string, limit int) ([]repositories.TagCount, error) {
+	prefix = NormalizeTag(prefix)
+	if prefix == "" {
+		return []repositories.TagCount{}, nil
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	return s.tagRepo.Autocomplete(ctx, prefix, limit)
+}
+
+// GetTagCloud returns the most used tags with their movie counts
+func (s *TagService) GetTagCloud(ctx context.Context, limit int) ([]repositories.TagCount, error) {
+	if limit < 1 {
+		limit = 50
+	}
+
+	return s.tagRepo.Cloud(ctx, limit)
+}
+
+// RenameTag renames a tag, keeping its movies. Renaming onto an existing tag is refused, use MergeTags
+func (s *TagService) RenameTag(ctx context.Context, id uuid.UUID, name string) (*models.Tag, error) {
+	name = NormalizeTag(name)
+	if !validTagName(name) {
+		return nil, ErrInvalidTagName
+	}
+
+	if _, err := s.tagRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.tagRepo.GetByName(ctx, name)
+	if err == nil && existing.ID != id {
+		return nil, ErrTagNameTaken
+	}
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	tag, err := s.tagRepo.Rename(ctx, id, name)
+	if err != nil {
+		return nil, err
+	}
+	// a tag is shared by many movies, so every list may hold one of them
+	s.similar.InvalidateAll()
+
+	return tag, nil
+}
+
+// MergeTags moves the movies of the duplicate tags to the survivor and deletes the duplicates
+func (s *TagService) MergeTags(ctx context.Context, survivorID uuid.UUID, duplicateIDs []uuid.UUID) (*models.Tag, error) {
+	for _, id := range duplicateIDs {
+		if id == survivorID {
+			return nil, ErrInvalidTagMerge
+		}
+	}
+
+	if _, err := s.tagRepo.GetByID(ctx, survivorID); err != nil {
+		return nil, err
+	}
+	for _, id := range duplicateIDs {
+		if _, err := s.tagRepo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.tagRepo.Merge(ctx, survivorID, duplicateIDs); err != nil {
+		return nil, err
+	}
+	s.similar.InvalidateAll()
+
+	return s.tagRepo.GetByID(ctx, survivorID)
+}
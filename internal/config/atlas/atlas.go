@@ -10,7 +10,7 @@ import (
 )
 
 func main() {
-	stmts, err := gormschema.New("postgres").Load(&models.Country{}, &models.Genre{}, &models.Language{}, &models.Movie{}, &models.Session{}, &models.User{})
+	stmts, err := gormschema.New("postgres").Load(&models.ApiKey{}, &models.Award{}, &models.AwardCategory{}, &models.Country{}, &models.Genre{}, &models.Language{}, &models.LoginThrottle{}, &models.Movie{}, &models.AlternateTitle{}, &models.MediaAsset{}, &models.MovieCredit{}, &models.MovieRedirect{}, &models.Nomination{}, &models.Permission{}, &models.RecoveryCode{}, &models.ReleaseDate{}, &models.Role{}, &models.MovieRating{}, &models.MovieCooccurrence{}, &models.MovieEvent{}, &models.MoviePopularity{}, &models.OidcState{}, &models.SecurityEvent{}, &models.Session{}, &models.SlugHistory{}, &models.Tag{}, &models.User{}, &models.UserIdentity{}, &models.UserToken{}, &models.WatchHistory{})
 	if err != nil {
 		msg := fmt.Sprintf("failed to load gorm schema: %v\n", err)
 		log.Print(msg)
@@ -5,6 +5,7 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"log"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -26,11 +27,30 @@ type Internal struct {
 	Server   Server   `yaml:"server"`
 	Database Database `yaml:"database"`
 	Jwt      Jwt      `yaml:"jwt"`
+
+	LoginProtection LoginProtection `yaml:"login_protection"`
+	Accounts        Accounts        `yaml:"accounts"`
+	Mfa             Mfa             `yaml:"mfa"`
+	Oidc            Oidc            `yaml:"oidc"`
+	Mail            Mail            `yaml:"mail"`
+
+	Storage Storage `yaml:"storage"`
+	Trailer Trailer `yaml:"trailer"`
+	Feed    Feed    `yaml:"feed"`
+	Similar Similar `yaml:"similar"`
+
+	Recommendations Recommendations `yaml:"recommendations"`
+	Popularity      Popularity      `yaml:"popularity"`
+
+	Enrichment Enrichment `yaml:"enrichment"`
 }
 
 type Server struct {
 	Port int    `yaml:"port"`
 	Host string `yaml:"host"`
+	// TrustedProxies are the IPs or CIDRs of the load balancers whose X-Forwarded-For is believed.
+	// Empty trusts nobody, the client IP is then the address of the connection
+	TrustedProxies []string `yaml:"trusted_proxies"`
 }
 
 type Database struct {
@@ -44,12 +64,143 @@ type Database struct {
 }
 
 type Jwt struct {
-	Audience        string `yaml:"audience"`
-	Domain          string `yaml:"domain"`
-	Realm           string `yaml:"realm"`
-	Secret          string `yaml:"secret"`
-	AccessTokenTTL  int    `yaml:"access_token_ttl"`
-	RefreshTokenTTL int    `yaml:"refresh_token_ttl"`
+	Audience        string   `yaml:"audience"`
+	Domain          string   `yaml:"domain"`
+	Realm           string   `yaml:"realm"`
+	Secret          string   `yaml:"secret"`
+	AccessTokenTTL  int      `yaml:"access_token_ttl"`
+	RefreshTokenTTL int      `yaml:"refresh_token_ttl"`
+	Algorithm       string   `yaml:"algorithm"` // HS256 | RS256 | EdDSA, HS256 signs with the secret
+	Keys            []JwtKey `yaml:"keys"`      // signing keys for RS256 and EdDSA
+}
+
+type JwtKey struct {
+	ID             string `yaml:"id"`               // published as kid
+	PrivateKeyFile string `yaml:"private_key_file"` // PEM, PKCS#8 or PKCS#1 for RSA
+	ActiveFrom     string `yaml:"active_from"`      // RFC 3339, the key signs from then on until a newer key becomes active
+}
+
+type LoginProtection struct {
+	FreeAttempts        int `yaml:"free_attempts"`         // failures in a row before the backoff starts
+	BaseDelay           int `yaml:"base_delay"`            // in seconds, the first backoff, doubled with every further failure
+	MaxDelay            int `yaml:"max_delay"`             // in seconds, upper bound of the backoff
+	MaxUsernameFailures int `yaml:"max_username_failures"` // failures of one username that lock it out
+	MaxIPFailures       int `yaml:"max_ip_failures"`       // failures from one IP address that lock it out
+	LockoutDuration     int `yaml:"lockout_duration"`      // in seconds
+	Window              int `yaml:"window"`                // in seconds, a failure after this much quiet starts counting from one again
+}
+
+type Accounts struct {
+	RequireVerifiedEmail bool   `yaml:"require_verified_email"` // refuse logins until the email address is verified
+	VerificationTTL      int    `yaml:"verification_ttl"`       // in seconds, lifetime of an email verification link
+	ResetTTL             int    `yaml:"reset_ttl"`              // in seconds, lifetime of a password reset link
+	VerifyURL            string `yaml:"verify_url"`             // frontend page that posts the token, it is appended as ?token=
+	ResetURL             string `yaml:"reset_url"`              // the same for password resets
+}
+
+type Mfa struct {
+	Issuer                string `yaml:"issuer"`                  // account name prefix shown in authenticator apps
+	RequiredForPrivileged bool   `yaml:"required_for_privileged"` // admins and directors must enroll TOTP before they can log in
+	ChallengeTTL          int    `yaml:"challenge_ttl"`           // in seconds, time to enter the code after the password
+	RecoveryCodes         int    `yaml:"recovery_codes"`          // one-time codes issued on enrollment
+}
+
+type Oidc struct {
+	StateTTL  int            `yaml:"state_ttl"` // in seconds, time to come back from the provider
+	Timeout   int            `yaml:"timeout"`   // in seconds, per request to a provider
+	Providers []OidcProvider `yaml:"providers"`
+}
+
+type OidcProvider struct {
+	Name         string   `yaml:"name"`   // used in the URLs, e.g. /auth/oidc/google/login
+	Issuer       string   `yaml:"issuer"` // discovery reads <issuer>/.well-known/openid-configuration
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"` // empty for public clients, PKCE is used either way
+	RedirectURL  string   `yaml:"redirect_url"`  // registered at the provider, it must reach the callback endpoint
+	Scopes       []string `yaml:"scopes"`        // openid is always requested, email and profile when empty
+}
+
+type Mail struct {
+	Driver   string `yaml:"driver"`    // smtp | file | log
+	From     string `yaml:"from"`      // sender address, may include a display name
+	FilePath string `yaml:"file_path"` // directory the file driver writes .eml files to
+	Smtp     Smtp   `yaml:"smtp"`
+}
+
+type Smtp struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type Storage struct {
+	Driver        string `yaml:"driver"`          // local | s3
+	LocalPath     string `yaml:"local_path"`      // root directory for the local driver
+	MaxUploadSize int64  `yaml:"max_upload_size"` // in bytes
+
+	MaxImagePixels int64 `yaml:"max_image_pixels"` // width × height limit checked before decoding an upload
+}
+
+type Trailer struct {
+	FetchOEmbed   bool `yaml:"fetch_oembed"`   // verify trailers exist through the provider oEmbed API
+	OEmbedTimeout int  `yaml:"oembed_timeout"` // in seconds
+}
+
+type Feed struct {
+	BaseURL         string `yaml:"base_url"`          // public URL of this service, for sitemap and feed self links
+	SiteURL         string `yaml:"site_url"`          // public web frontend, movie pages are <site_url>/movies/<slug>
+	Title           string `yaml:"title"`             // feed title and author
+	EntryLimit      int    `yaml:"entry_limit"`       // movies per Atom feed
+	SitemapPageSize int    `yaml:"sitemap_page_size"` // URLs per sitemap, at most 50000
+}
+
+type Similar struct {
+	Limit      int            `yaml:"limit"`       // default number of similar movies
+	MaxLimit   int            `yaml:"max_limit"`   // upper bound of ?limit=
+	CacheTTL   int            `yaml:"cache_ttl"`   // in seconds
+	YearWindow int            `yaml:"year_window"` // release years further apart than this score 0 on year proximity
+	Weights    SimilarWeights `yaml:"weights"`
+}
+
+type SimilarWeights struct {
+	Genres    float64 `yaml:"genres"`
+	Countries float64 `yaml:"countries"`
+	Credits   float64 `yaml:"credits"` // shared director or cast/crew
+	Language  float64 `yaml:"language"`
+	Year      float64 `yaml:"year"`
+}
+
+type Recommendations struct {
+	Interval      int     `yaml:"interval"`       // seconds between co-occurrence recomputations
+	MinRating     int     `yaml:"min_rating"`     // ratings from this score up count as liked
+	WatchedWeight float64 `yaml:"watched_weight"` // weight of a watched but unrated movie, ratings weigh score/10
+	MaxRelated    int     `yaml:"max_related"`    // related movies kept per movie
+	PopularWindow int     `yaml:"popular_window"` // in days, activity counted for the cold-start popular list
+	Limit         int     `yaml:"limit"`
+	MaxLimit      int     `yaml:"max_limit"`
+}
+
+type Popularity struct {
+	Interval         int                `yaml:"interval"`           // seconds between score recomputations
+	TrendingHalfLife int                `yaml:"trending_half_life"` // in hours, how fast an event stops counting for trending
+	PopularHalfLife  int                `yaml:"popular_half_life"`  // in hours, the same for popularity
+	Window           int                `yaml:"window"`             // in days, older events are ignored and pruned
+	Weights          map[string]float64 `yaml:"weights"`            // per event type
+	ViewDedupe       int                `yaml:"view_dedupe"`        // in seconds, repeated views of a movie by the same visitor count once
+	EventRateLimit   int                `yaml:"event_rate_limit"`   // events a visitor may send per minute
+	VisitorSecret    string             `yaml:"visitor_secret"`     // HMAC key for the IP addresses of anonymous visitors
+}
+
+type Enrichment struct {
+	Tmdb Tmdb `yaml:"tmdb"`
+}
+
+type Tmdb struct {
+	BaseURL      string `yaml:"base_url"`
+	ImageBaseURL string `yaml:"image_base_url"`
+	APIKey       string `yaml:"api_key"`
+	Timeout      int    `yaml:"timeout"` // in seconds
 }
 
 func MustLoad() *Config {
@@ -96,6 +247,10 @@ func MustLoad() *Config {
 			cfg.Internal = *envCfg.ProductionConfigs
 			updateDbCredentials(&cfg.Internal.Database)
 			updateJwtSecret(&cfg.Internal.Jwt)
+			updateTmdbApiKey(&cfg.Internal.Enrichment.Tmdb)
+			updateSmtpPassword(&cfg.Internal.Mail.Smtp)
+			updateOidcSecrets(cfg.Internal.Oidc.Providers)
+			updateVisitorSecret(&cfg.Internal.Popularity)
 		} else {
 			panic("production configs are not found")
 		}
@@ -136,3 +291,31 @@ func updateJwtSecret(currentSecret *Jwt) {
 		currentSecret.Secret = jwtSecret
 	}
 }
+
+func updateSmtpPassword(smtp *Smtp) {
+	if password := os.Getenv("SMTP_PASSWORD"); password != "" {
+		smtp.Password = password
+	}
+}
+
+// updateOidcSecrets reads client secrets from OIDC_<NAME>_CLIENT_SECRET, e.g. OIDC_GOOGLE_CLIENT_SECRET
+func updateOidcSecrets(providers []OidcProvider) {
+	for i := range providers {
+		name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(providers[i].Name))
+		if secret := os.Getenv("OIDC_" + name + "_CLIENT_SECRET"); secret != "" {
+			providers[i].ClientSecret = secret
+		}
+	}
+}
+
+func updateVisitorSecret(popularity *Popularity) {
+	if secret := os.Getenv("POPULARITY_VISITOR_SECRET"); secret != "" {
+		popularity.VisitorSecret = secret
+	}
+}
+
+func updateTmdbApiKey(tmdb *Tmdb) {
+	if apiKey := os.Getenv("TMDB_API_KEY"); apiKey != "" {
+		tmdb.APIKey = apiKey
+	}
+}
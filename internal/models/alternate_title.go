@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	AlternateTitleType      = "alternate"
+	OriginalScriptTitleType = "original"
+)
+
+type AlternateTitle struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	MovieID   uuid.UUID  `gorm:"column:movie_id;type:uuid;not null;index"`
+	CountryID *uuid.UUID `gorm:"column:country_id;type:uuid"`
+	Title     string     `gorm:"column:title;type:text;not null;index"`
+	Type      string     `gorm:"column:type;type:text;not null;default:'alternate';comment:'alternate | original (original script)'"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at"`
+
+	// relations
+	Country *Country `gorm:"foreignKey:CountryID" json:"country,omitempty"`
+}
+
+func (t *AlternateTitle) BeforeCreate(*gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
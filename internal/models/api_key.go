@@ -0,0 +1,54 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+const (
+	ReadScope  = "read"  // GET and HEAD requests
+	WriteScope = "write" // every other method
+)
+
+// ApiKey lets a service act as its owner without logging in. Only the hash of the key is stored,
+// the prefix identifies it in listings and logs
+type ApiKey struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Name        string     `gorm:"column:name;type:text;not null"`
+	Prefix      string     `gorm:"column:prefix;type:text;not null;uniqueIndex;comment:'Start of the key, shown to identify it'"`
+	KeyHash     string     `gorm:"column:key_hash;type:text;not null;uniqueIndex;comment:'SHA-256 of the key'" json:"-"`
+	Scopes      string     `gorm:"column:scopes;type:text;not null;comment:'Space separated: read | write'"`
+	UserID      uuid.UUID  `gorm:"column:user_id;type:uuid;not null;index;comment:'The key acts as this user, with its role'"`
+	CreatedByID uuid.UUID  `gorm:"column:created_by;type:uuid;not null"`
+	ExpiresAt   *time.Time `gorm:"column:expires_at;comment:'NULL for keys that do not expire'"`
+	LastUsedAt  *time.Time `gorm:"column:last_used_at"`
+	RevokedAt   *time.Time `gorm:"column:revoked_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+
+	User      User `gorm:"foreignKey:UserID" json:"user"`
+	CreatedBy User `gorm:"foreignKey:CreatedByID" json:"-"`
+}
+
+func (k *ApiKey) BeforeCreate(*gorm.DB) (err error) {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsUsable reports whether the key is neither revoked nor expired
+func (k *ApiKey) IsUsable() bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt))
+}
+
+// HasScope reports whether the key was granted scope
+func (k *ApiKey) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(k.Scopes) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Award is an award body, e.g. Academy Awards
+type Award struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name        string    `gorm:"column:name;type:text;not null;uniqueIndex"`
+	Description string    `gorm:"column:description;type:text"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+
+	// relations
+	Categories []AwardCategory `gorm:"foreignKey:AwardID" json:"categories,omitempty"`
+}
+
+func (a *Award) BeforeCreate(*gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
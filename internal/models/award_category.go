@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// AwardCategory is a category of an award, e.g. Best Picture
+type AwardCategory struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	AwardID   uuid.UUID `gorm:"column:award_id;type:uuid;not null;uniqueIndex:idx_award_categories_award_name"`
+	Name      string    `gorm:"column:name;type:text;not null;uniqueIndex:idx_award_categories_award_name"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	// relations
+	Award *Award `gorm:"foreignKey:AwardID" json:"award,omitempty"`
+}
+
+func (c *AwardCategory) BeforeCreate(*gorm.DB) (err error) {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
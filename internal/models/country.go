@@ -9,6 +9,7 @@ import (
 type Country struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey"`
 	Name      string         `gorm:"column:name;type:text;not null;uniqueIndex"`
+	Slug      string         `gorm:"column:slug;type:text;uniqueIndex"`
 	Code      string         `gorm:"column:code;type:varchar(2);not null;uniqueIndex;comment:'ISO 3166-1 alpha-2 code'"`
 	Continent string         `gorm:"column:continent;type:text"`
 	CreatedAt time.Time      `gorm:"column:created_at"`
@@ -9,13 +9,17 @@ import (
 type Genre struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primaryKey"`
 	Name        string         `gorm:"column:name;type:text;not null;uniqueIndex"`
+	Slug        string         `gorm:"column:slug;type:text;uniqueIndex"`
 	Description string         `gorm:"column:description;type:text"`
+	ParentID    *uuid.UUID     `gorm:"column:parent_id;type:uuid;index;comment:'NULL for top-level genres'"`
 	CreatedAt   time.Time      `gorm:"column:created_at"`
 	UpdatedAt   time.Time      `gorm:"column:updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at"`
 
 	// relations
-	Movies []Movie `gorm:"many2many:movie_genres;" json:"movies,omitempty"`
+	Movies   []Movie  `gorm:"many2many:movie_genres;" json:"movies,omitempty"`
+	Parent   *Genre   `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
+	Children []*Genre `gorm:"foreignKey:ParentID" json:"children,omitempty"`
 }
 
 func (g *Genre) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	UsernameThrottle = "username"
+	IPThrottle       = "ip"
+)
+
+// LoginThrottle counts the recent failed logins of a username or an IP address
+type LoginThrottle struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Scope         string     `gorm:"column:scope;type:text;not null;uniqueIndex:idx_login_throttles_scope_subject;comment:'username | ip'"`
+	Subject       string     `gorm:"column:subject;type:text;not null;uniqueIndex:idx_login_throttles_scope_subject;comment:'Lowercased username or IP address'"`
+	Failures      int        `gorm:"column:failures;type:integer;not null;default:0"`
+	LastFailureAt time.Time  `gorm:"column:last_failure_at;not null;index"`
+	BlockedUntil  *time.Time `gorm:"column:blocked_until;index;comment:'Logins are refused until then'"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at"`
+}
+
+func (t *LoginThrottle) BeforeCreate(*gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
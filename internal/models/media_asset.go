@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	PosterMedia   = "poster"
+	BackdropMedia = "backdrop"
+
+	OriginalVariant = "original"
+)
+
+// MediaVariantWidths holds the generated variants and their max width in pixels
+var MediaVariantWidths = map[string]int{
+	"thumbnail": 185,
+	"medium":    500,
+	"large":     1280,
+}
+
+type MediaAsset struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	MovieID     uuid.UUID `gorm:"column:movie_id;type:uuid;not null;index"`
+	Kind        string    `gorm:"column:kind;type:text;not null;comment:'poster | backdrop'"`
+	ContentType string    `gorm:"column:content_type;type:text;not null"`
+	Size        int64     `gorm:"column:size;not null;comment:'Original size in bytes'"`
+	Width       int       `gorm:"column:width;type:integer"`
+	Height      int       `gorm:"column:height;type:integer"`
+	StorageKey  string    `gorm:"column:storage_key;type:text;not null;comment:'Key of the original file'"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+func (a *MediaAsset) BeforeCreate(*gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// VariantKey returns the storage key of a resized variant
+func (a *MediaAsset) VariantKey(variant string) string {
+	if variant == OriginalVariant {
+		return a.StorageKey
+	}
+	return fmt.Sprintf("movies/%s/%s/%s.jpg", a.MovieID, a.ID, variant)
+}
+
+// VariantURLs returns the public URLs of the original and every resized variant
+func (a *MediaAsset) VariantURLs() map[string]string {
+	urls := make(map[string]string, len(MediaVariantWidths)+1)
+	urls[OriginalVariant] = fmt.Sprintf("/api/v1/media/%s/%s", a.ID, OriginalVariant)
+	for variant := range MediaVariantWidths {
+		urls[variant] = fmt.Sprintf("/api/v1/media/%s/%s", a.ID, variant)
+	}
+	return urls
+}
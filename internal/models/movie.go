@@ -7,25 +7,44 @@ import (
 )
 
 type Movie struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primaryKey"`
-	Title       string         `gorm:"column:title;type:text;not null;index"`
-	Director    string         `gorm:"column:director;type:text;index"`
-	Year        int            `gorm:"column:year;type:integer;index"`
-	Plot        string         `gorm:"column:plot;type:text"`
-	Runtime     int            `gorm:"column:runtime;type:integer;comment:'Duration in minutes'"`
-	Rating      float32        `gorm:"column:rating;type:decimal(3,1);default:0.0"`
-	PosterURL   string         `gorm:"column:poster_url;type:text"`
-	TrailerURL  string         `gorm:"column:trailer_url;type:text"`
-	ReleaseDate *time.Time     `gorm:"column:release_date;type:date"`
-	LanguageID  uuid.UUID      `gorm:"column:language;type:uuid;not null"`
-	CreatedAt   time.Time      `gorm:"column:created_at"`
-	UpdatedAt   time.Time      `gorm:"column:updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at"`
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	Title           string         `gorm:"column:title;type:text;not null;index"`
+	Slug            string         `gorm:"column:slug;type:text;uniqueIndex"`
+	Director        string         `gorm:"column:director;type:text;index"`
+	Year            int            `gorm:"column:year;type:integer;index"`
+	Plot            string         `gorm:"column:plot;type:text"`
+	Runtime         int            `gorm:"column:runtime;type:integer;comment:'Duration in minutes'"`
+	Rating          float32        `gorm:"column:rating;type:decimal(3,1);default:0.0"`
+	PosterURL       string         `gorm:"column:poster_url;type:text"`
+	TrailerURL      string         `gorm:"column:trailer_url;type:text"`
+	TrailerProvider string         `gorm:"column:trailer_provider;type:text;comment:'youtube | vimeo'"`
+	TrailerVideoID  string         `gorm:"column:trailer_video_id;type:text"`
+	ReleaseDate     *time.Time     `gorm:"column:release_date;type:date;comment:'Primary date, computed from release_dates when there are any'"`
+	LanguageID      uuid.UUID      `gorm:"column:language;type:uuid;not null;comment:'Original language'"`
+	ImdbID          *string        `gorm:"column:imdb_id;type:text;uniqueIndex;comment:'IMDb tt-ID'"`
+	TmdbID          *int64         `gorm:"column:tmdb_id;type:bigint;uniqueIndex"`
+	PublishedAt     *time.Time     `gorm:"column:published_at;index;comment:'NULL while the movie is a draft'"`
+	CreatedAt       time.Time      `gorm:"column:created_at;index"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at"`
 
 	// relations
-	Language  Language  `gorm:"foreignKey:LanguageID" json:"language"`
-	Countries []Country `gorm:"many2many:movie_countries;" json:"countries"`
-	Genres    []Genre   `gorm:"many2many:movie_genres;" json:"genres"`
+	Language          Language         `gorm:"foreignKey:LanguageID" json:"language"`
+	AudioLanguages    []Language       `gorm:"many2many:movie_audio_languages;" json:"audioLanguages"`
+	SubtitleLanguages []Language       `gorm:"many2many:movie_subtitle_languages;" json:"subtitleLanguages"`
+	AlternateTitles   []AlternateTitle `gorm:"foreignKey:MovieID" json:"alternateTitles"`
+	ReleaseDates      []ReleaseDate    `gorm:"foreignKey:MovieID" json:"releaseDates"` // ReleaseDate is computed from these; removing them all keeps the last computed date
+	Countries         []Country        `gorm:"many2many:movie_countries;" json:"countries"`
+	Genres            []Genre          `gorm:"many2many:movie_genres;" json:"genres"`
+	Tags              []Tag            `gorm:"many2many:movie_tags;" json:"tags"`
+	Credits           []MovieCredit    `gorm:"foreignKey:MovieID" json:"credits"`
+	MediaAssets       []MediaAsset     `gorm:"foreignKey:MovieID" json:"-"`
+
+	// Images maps media kind (poster, backdrop) to variant URLs, filled from MediaAssets
+	Images map[string]map[string]string `gorm:"-" json:"images"`
+
+	// Awards summarizes the nominations per award, filled for the movie detail only
+	Awards []AwardSummary `gorm:"-" json:"awards,omitempty"`
 }
 
 func (m *Movie) BeforeCreate(*gorm.DB) (err error) {
@@ -34,3 +53,12 @@ func (m *Movie) BeforeCreate(*gorm.DB) (err error) {
 	}
 	return nil
 }
+
+func (m *Movie) AfterFind(*gorm.DB) (err error) {
+	// assets are preloaded oldest first, so the latest upload of each kind wins
+	m.Images = make(map[string]map[string]string)
+	for i := range m.MediaAssets {
+		m.Images[m.MediaAssets[i].Kind] = m.MediaAssets[i].VariantURLs()
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// MovieCooccurrence is the item-item similarity of two movies liked by the same users,
+// recomputed periodically from watch history and ratings
+type MovieCooccurrence struct {
+	MovieID    uuid.UUID `gorm:"column:movie_id;type:uuid;primaryKey"`
+	RelatedID  uuid.UUID `gorm:"column:related_id;type:uuid;primaryKey"`
+	Users      int       `gorm:"column:users;type:integer;not null;comment:'Users who liked both movies'"`
+	Score      float64   `gorm:"column:score;type:double precision;not null;comment:'Cosine similarity of the two audiences'"`
+	ComputedAt time.Time `gorm:"column:computed_at;not null"`
+}
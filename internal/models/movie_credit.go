@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	DirectorCredit = "director"
+	WriterCredit   = "writer"
+	CastCredit     = "cast"
+)
+
+type MovieCredit struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	MovieID   uuid.UUID `gorm:"column:movie_id;type:uuid;not null;index"`
+	Name      string    `gorm:"column:name;type:text;not null;index"`
+	Role      string    `gorm:"column:role;type:text;not null;comment:'director | writer | cast'"`
+	Character string    `gorm:"column:character;type:text"`
+	Position  int       `gorm:"column:position;type:integer;default:0;comment:'Billing order'"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (c *MovieCredit) BeforeCreate(*gorm.DB) (err error) {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
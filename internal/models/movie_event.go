@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	ViewEvent   = "view"
+	ReviewEvent = "review"
+)
+
+// MovieEvent is a single engagement signal the trending and popularity scores are computed from
+type MovieEvent struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	MovieID     uuid.UUID  `gorm:"column:movie_id;type:uuid;not null;index"`
+	UserID      *uuid.UUID `gorm:"column:user_id;type:uuid;comment:'NULL for anonymous viewers'"`
+	Type        string     `gorm:"column:type;type:text;not null;comment:'view | review'"`
+	CountryCode string     `gorm:"column:country_code;type:varchar(2);not null;default:'';comment:'ISO 3166-1 alpha-2 code, empty when unknown'"`
+	Visitor     string     `gorm:"column:visitor;type:text;not null;default:'';index:idx_movie_events_visitor_created_at,priority:1;comment:'user:<id> or ip:<HMAC-SHA256 of the address>, for deduplication and rate limiting'" json:"-"`
+	CreatedAt   time.Time  `gorm:"column:created_at;index;index:idx_movie_events_visitor_created_at,priority:2"`
+}
+
+func (e *MovieEvent) BeforeCreate(*gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
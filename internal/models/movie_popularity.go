@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// MoviePopularity holds the time-decayed engagement scores of a movie in a country,
+// an empty CountryCode holds the worldwide scores
+type MoviePopularity struct {
+	MovieID         uuid.UUID `gorm:"column:movie_id;type:uuid;primaryKey"`
+	CountryCode     string    `gorm:"column:country_code;type:varchar(2);primaryKey"`
+	TrendingScore   float64   `gorm:"column:trending_score;type:double precision;not null;default:0"`
+	PopularityScore float64   `gorm:"column:popularity_score;type:double precision;not null;default:0"`
+	ComputedAt      time.Time `gorm:"column:computed_at;not null"`
+}
+
+// TableName keeps the singular name, "popularities" reads badly
+func (MoviePopularity) TableName() string {
+	return "movie_popularity"
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	MinRatingScore = 1
+	MaxRatingScore = 10
+)
+
+// MovieRating is a user's own score of a movie, one per user and movie
+type MovieRating struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"column:user_id;type:uuid;not null;uniqueIndex:idx_movie_ratings_user_movie"`
+	MovieID   uuid.UUID `gorm:"column:movie_id;type:uuid;not null;uniqueIndex:idx_movie_ratings_user_movie;index"`
+	Score     int       `gorm:"column:score;type:integer;not null;comment:'1 to 10'"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	Movie Movie `gorm:"foreignKey:MovieID" json:"movie"`
+}
+
+func (r *MovieRating) BeforeCreate(*gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// MovieRedirect points the ID of a merged away movie to the surviving record
+type MovieRedirect struct {
+	FromID    uuid.UUID `gorm:"column:from_id;type:uuid;primaryKey"`
+	ToID      uuid.UUID `gorm:"column:to_id;type:uuid;not null;index"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	To Movie `gorm:"foreignKey:ToID" json:"-"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Nomination is a movie nominated in an award category at a ceremony, optionally for one person
+type Nomination struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	MovieID    uuid.UUID `gorm:"column:movie_id;type:uuid;not null;uniqueIndex:idx_nominations_unique"`
+	CategoryID uuid.UUID `gorm:"column:category_id;type:uuid;not null;index;uniqueIndex:idx_nominations_unique"`
+	Year       int       `gorm:"column:year;type:integer;not null;uniqueIndex:idx_nominations_unique;comment:'Ceremony year'"`
+	Person     string    `gorm:"column:person;type:text;not null;default:'';uniqueIndex:idx_nominations_unique;comment:'Nominee name, empty when the movie itself is nominated'"`
+	Won        bool      `gorm:"column:won;not null;default:false"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+
+	// relations
+	Movie    *Movie        `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+	Category AwardCategory `gorm:"foreignKey:CategoryID" json:"category"`
+}
+
+// AwardSummary counts the wins and nominations of a movie at one award, e.g. "Won 3 Oscars"
+type AwardSummary struct {
+	AwardID     uuid.UUID `json:"awardId"`
+	Award       string    `json:"award"`
+	Wins        int       `json:"wins"`
+	Nominations int       `json:"nominations"`
+}
+
+func (n *Nomination) BeforeCreate(*gorm.DB) (err error) {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
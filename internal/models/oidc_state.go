@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// OidcState is a login that went to a provider and has not come back yet. It is stored rather than kept
+// in memory so the callback may reach any replica
+type OidcState struct {
+	StateHash    string    `gorm:"column:state_hash;type:text;primaryKey;comment:'SHA-256 of the state parameter'"`
+	Provider     string    `gorm:"column:provider;type:text;not null"`
+	CodeVerifier string    `gorm:"column:code_verifier;type:text;not null;comment:'PKCE verifier'" json:"-"`
+	Nonce        string    `gorm:"column:nonce;type:text;not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"column:expires_at;not null;index"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the authenticator is lost
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID  `gorm:"column:user_id;type:uuid;not null;index"`
+	CodeHash  string     `gorm:"column:code_hash;type:text;not null;comment:'SHA-256 of the normalized code'"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (r *RecoveryCode) BeforeCreate(*gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
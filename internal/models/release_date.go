@@ -0,0 +1,36 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	TheatricalRelease = "theatrical"
+	DigitalRelease    = "digital"
+	PhysicalRelease   = "physical"
+	TVRelease         = "tv"
+)
+
+// ReleaseDate is when a movie opened in a country in one format. The earliest theatrical
+// release, or else the earliest of any type, is copied to Movie.ReleaseDate
+type ReleaseDate struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	MovieID   uuid.UUID `gorm:"column:movie_id;type:uuid;not null;uniqueIndex:idx_release_dates_movie_country_type"`
+	CountryID uuid.UUID `gorm:"column:country_id;type:uuid;not null;uniqueIndex:idx_release_dates_movie_country_type"`
+	Type      string    `gorm:"column:type;type:text;not null;uniqueIndex:idx_release_dates_movie_country_type;comment:'theatrical | digital | physical | tv'"`
+	Date      time.Time `gorm:"column:date;type:date;not null;index"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	// relations
+	Country Country `gorm:"foreignKey:CountryID" json:"country"`
+}
+
+func (d *ReleaseDate) BeforeCreate(*gorm.DB) (err error) {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
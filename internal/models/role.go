@@ -0,0 +1,42 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Role is a named set of permissions, users reference it by name
+type Role struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name        string    `gorm:"column:name;type:text;not null;uniqueIndex;comment:'Stored in users.role and the role claim of tokens'"`
+	Description string    `gorm:"column:description;type:text"`
+	System      bool      `gorm:"column:system;not null;default:false;comment:'Seeded roles cannot be deleted'"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+
+	Permissions []Permission `gorm:"many2many:role_permissions;joinForeignKey:RoleID;joinReferences:PermissionName" json:"permissions"`
+}
+
+func (r *Role) BeforeCreate(*gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// PermissionNames returns the names of the granted permissions
+func (r *Role) PermissionNames() []string {
+	names := make([]string, len(r.Permissions))
+	for i := range r.Permissions {
+		names[i] = r.Permissions[i].Name
+	}
+	return names
+}
+
+// Permission is an action the routes check, e.g. movie:update. The set is fixed by the code and
+// seeded by migrations
+type Permission struct {
+	Name        string `gorm:"column:name;type:text;primaryKey"`
+	Description string `gorm:"column:description;type:text"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	RefreshTokenReuseEvent = "refresh_token_reuse"
+	LoginLockoutEvent      = "login_lockout"
+)
+
+// SecurityEvent records suspicious authentication activity for admins to review
+type SecurityEvent struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID    *uuid.UUID `gorm:"column:user_id;type:uuid;index;comment:'NULL when no user could be identified'"`
+	Type      string     `gorm:"column:type;type:text;not null;index;comment:'refresh_token_reuse | login_lockout'"`
+	IPAddress string     `gorm:"column:ip_address;type:text"`
+	UserAgent string     `gorm:"column:user_agent;type:text"`
+	Details   string     `gorm:"column:details;type:text"`
+	CreatedAt time.Time  `gorm:"column:created_at;index"`
+}
+
+func (e *SecurityEvent) BeforeCreate(*gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -7,25 +7,35 @@ import (
 )
 
 type Session struct {
-	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
-	UserID        uuid.UUID `gorm:"type:uuid;not null;index"`
-	AccessToken   string    `gorm:"column:access_token;type:text;not null;uniqueIndex"`
-	RefreshToken  string    `gorm:"column:refresh_token;type:text;not null;uniqueIndex"`
-	ExpiresAt     time.Time `gorm:"column:expires_at;not null"`
-	RefreshExpiry time.Time `gorm:"column:refresh_expiry;not null"`
-	UserAgent     string    `gorm:"column:user_agent;type:text"`
-	IPAddress     string    `gorm:"column:ip_address;type:text"`
-	IsRevoked     bool      `gorm:"column:is_revoked;default:false"`
-	CreatedAt     time.Time `gorm:"column:created_at"`
-	UpdatedAt     time.Time `gorm:"column:updated_at"`
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	AccessJTI     string     `gorm:"column:access_jti;type:text;not null;uniqueIndex;comment:'jti of the access token, the token itself is never stored'"`
+	RefreshJTI    string     `gorm:"column:refresh_jti;type:text;not null;uniqueIndex;comment:'jti of the refresh token'"`
+	ExpiresAt     time.Time  `gorm:"column:expires_at;not null"`
+	RefreshExpiry time.Time  `gorm:"column:refresh_expiry;not null"`
+	UserAgent     string     `gorm:"column:user_agent;type:text"`
+	IPAddress     string     `gorm:"column:ip_address;type:text"`
+	IsRevoked     bool       `gorm:"column:is_revoked;default:false"`
+	FamilyID      uuid.UUID  `gorm:"column:family_id;type:uuid;not null;index;comment:'First session of the refresh token rotation chain'"`
+	RotatedFromID *uuid.UUID `gorm:"column:rotated_from;type:uuid;index;comment:'Session whose refresh token was exchanged for this one'"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at"`
 
 	User User `gorm:"foreignKey:UserID"`
+
+	// AccessToken and RefreshToken are only set on a freshly generated session, to hand them to the client
+	AccessToken  string `gorm:"-"`
+	RefreshToken string `gorm:"-"`
 }
 
 func (s *Session) BeforeCreate(*gorm.DB) (err error) {
 	if s.ID == uuid.Nil {
 		s.ID = uuid.New()
 	}
+	// a sign in starts a new family, rotations inherit it
+	if s.FamilyID == uuid.Nil {
+		s.FamilyID = s.ID
+	}
 	return nil
 }
 
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	MovieSlugEntity   = "movie"
+	GenreSlugEntity   = "genre"
+	CountrySlugEntity = "country"
+)
+
+// SlugHistory keeps previous slugs of an entity so old URLs redirect to the current one
+type SlugHistory struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EntityType string    `gorm:"column:entity_type;type:text;not null;uniqueIndex:idx_slug_histories_entity_slug;comment:'movie | genre | country'"`
+	Slug       string    `gorm:"column:slug;type:text;not null;uniqueIndex:idx_slug_histories_entity_slug"`
+	EntityID   uuid.UUID `gorm:"column:entity_id;type:uuid;not null;index"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+func (h *SlugHistory) BeforeCreate(*gorm.DB) (err error) {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
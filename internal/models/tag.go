@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// MaxTagNameLength limits free-form tags to a short keyword or phrase
+const MaxTagNameLength = 50
+
+// Tag is a free-form keyword on movies, e.g. "time travel" or "heist"
+type Tag struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name      string    `gorm:"column:name;type:text;not null;uniqueIndex;comment:'lower case, single spaced'"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+
+	// relations
+	Movies []Movie `gorm:"many2many:movie_tags;" json:"movies,omitempty"`
+}
+
+func (t *Tag) BeforeCreate(*gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
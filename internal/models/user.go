@@ -11,20 +11,26 @@ import (
 )
 
 type User struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primaryKey"`
-	FirstName   string         `gorm:"column:first_name;type:text;not null"`
-	LastName    string         `gorm:"column:last_name;type:text;not null"`
-	Username    string         `gorm:"column:username;type:text;not null;uniqueIndex"`
-	Email       string         `gorm:"column:email;type:text;not null;uniqueIndex"`
-	Password    string         `gorm:"column:password;type:text;not null"`
-	Role        string         `gorm:"column:role;type:text;default:'user'"`
-	Active      bool           `gorm:"column:active;default:true"`
-	LastLoginAt *time.Time     `gorm:"column:last_login_at"`
-	CreatedAt   time.Time      `gorm:"column:created_at"`
-	UpdatedAt   time.Time      `gorm:"column:updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at"`
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	FirstName       string         `gorm:"column:first_name;type:text;not null"`
+	LastName        string         `gorm:"column:last_name;type:text;not null"`
+	Username        string         `gorm:"column:username;type:text;not null;uniqueIndex"`
+	Email           string         `gorm:"column:email;type:text;not null;uniqueIndex"`
+	Password        string         `gorm:"column:password;type:text;not null"`
+	Role            string         `gorm:"column:role;type:text;default:'USER';comment:'Name of a row in roles'"`
+	Active          bool           `gorm:"column:active;default:true"`
+	Locale          string         `gorm:"column:locale;type:text;not null;default:'en';comment:'uz | ru | en, language of the emails sent to the user'"`
+	LastLoginAt     *time.Time     `gorm:"column:last_login_at"`
+	EmailVerifiedAt *time.Time     `gorm:"column:email_verified_at;comment:'NULL until the email address is confirmed'"`
+	TotpSecret      string         `gorm:"column:totp_secret;type:text;comment:'Base32 TOTP secret, set while enrolling or enrolled'" json:"-"`
+	TotpEnabledAt   *time.Time     `gorm:"column:totp_enabled_at;comment:'NULL until TOTP enrollment is confirmed'"`
+	TotpLastStep    int64          `gorm:"column:totp_last_step;type:bigint;not null;default:0;comment:'Time step of the last accepted code, codes cannot be replayed'" json:"-"`
+	CreatedAt       time.Time      `gorm:"column:created_at"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at"`
 
-	Sessions []Session `gorm:"foreignKey:UserID"`
+	Sessions        []Session `gorm:"foreignKey:UserID"`
+	PreferredGenres []Genre   `gorm:"many2many:user_preferred_genres;" json:"preferredGenres,omitempty"`
 }
 
 func (u *User) BeforeCreate(*gorm.DB) (err error) {
@@ -32,13 +38,17 @@ func (u *User) BeforeCreate(*gorm.DB) (err error) {
 		u.ID = uuid.New()
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	u.Password, err = HashPassword(u.Password)
+	return err
+}
+
+// HashPassword returns the bcrypt hash stored in place of a password
+func HashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return err
+		return "", err
 	}
-	u.Password = string(hashedPassword)
-
-	return nil
+	return string(hashedPassword), nil
 }
 
 func (u *User) CheckPassword(password string) bool {
@@ -50,22 +60,24 @@ func (s *Session) IsSessionValid() bool {
 	return !s.IsRevoked && time.Now().Before(s.ExpiresAt)
 }
 
-func (u *User) GenerateTokens(jwtConf *config.Jwt, userAgent, ipAddress string) (*Session, error) {
+func (u *User) GenerateTokens(keys *jwt.KeySet, jwtConf *config.Jwt, userAgent, ipAddress string) (*Session, error) {
 	accessTokenDuration := time.Duration(jwtConf.AccessTokenTTL) * time.Second
 	refreshTokenDuration := time.Duration(jwtConf.RefreshTokenTTL) * time.Second
 
-	accessToken, accessTokenExpiry, err := jwt.GenerateToken(u.ID.String(), u.Username, u.Email, u.Role, jwtConf, jwt.AccessToken, accessTokenDuration)
+	accessToken, accessJTI, accessTokenExpiry, err := jwt.GenerateToken(u.ID.String(), u.Username, u.Email, u.Role, keys, jwtConf, jwt.AccessToken, accessTokenDuration)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, refreshTokenExpiry, err := jwt.GenerateToken(u.ID.String(), u.Username, u.Email, u.Role, jwtConf, jwt.RefreshToken, refreshTokenDuration)
+	refreshToken, refreshJTI, refreshTokenExpiry, err := jwt.GenerateToken(u.ID.String(), u.Username, u.Email, u.Role, keys, jwtConf, jwt.RefreshToken, refreshTokenDuration)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
 	session := Session{
 		UserID:        u.ID,
+		AccessJTI:     accessJTI,
+		RefreshJTI:    refreshJTI,
 		AccessToken:   accessToken,
 		RefreshToken:  refreshToken,
 		ExpiresAt:     accessTokenExpiry,
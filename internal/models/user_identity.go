@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// UserIdentity links a user to an account at an external OIDC provider
+type UserIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID  `gorm:"column:user_id;type:uuid;not null;index"`
+	Provider    string     `gorm:"column:provider;type:text;not null;uniqueIndex:idx_user_identities_provider_subject;comment:'Provider name from the oidc config'"`
+	Subject     string     `gorm:"column:subject;type:text;not null;uniqueIndex:idx_user_identities_provider_subject;comment:'sub claim, stable per provider'"`
+	Email       string     `gorm:"column:email;type:text;comment:'Email the provider reported at the last login'"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (i *UserIdentity) BeforeCreate(*gorm.DB) (err error) {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	EmailVerificationToken = "email_verification"
+	PasswordResetToken     = "password_reset"
+)
+
+// UserToken is a single use token mailed to a user, only its hash is stored
+type UserToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID  `gorm:"column:user_id;type:uuid;not null;index"`
+	Purpose   string     `gorm:"column:purpose;type:text;not null;comment:'email_verification | password_reset'"`
+	TokenHash string     `gorm:"column:token_hash;type:text;not null;uniqueIndex;comment:'SHA-256 of the token'"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at;comment:'Set when the token is redeemed or superseded'"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (t *UserToken) BeforeCreate(*gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// WatchHistory records that a user watched a movie, a movie watched again gets a new row
+type WatchHistory struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"column:user_id;type:uuid;not null;index"`
+	MovieID   uuid.UUID `gorm:"column:movie_id;type:uuid;not null;index"`
+	WatchedAt time.Time `gorm:"column:watched_at;not null;index"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	Movie Movie `gorm:"foreignKey:MovieID" json:"movie"`
+}
+
+func (h *WatchHistory) BeforeCreate(*gorm.DB) (err error) {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrMovieNotFound     = errors.New("movie not found on metadata provider")
+	ErrInvalidExternalID = errors.New("invalid external ID")
+)
+
+var imdbIDPattern = regexp.MustCompile(`^tt[0-9]{7,10}$`)
+
+// ExternalIDs identifies a movie on third party catalogs, at least one must be set
+type ExternalIDs struct {
+	ImdbID string
+	TmdbID int64
+}
+
+func (ids ExternalIDs) IsEmpty() bool {
+	return ids.ImdbID == "" && ids.TmdbID == 0
+}
+
+// Validate checks the format of the IDs that are set
+func (ids ExternalIDs) Validate() error {
+	if ids.IsEmpty() {
+		return ErrInvalidExternalID
+	}
+	if ids.ImdbID != "" && !IsValidImdbID(ids.ImdbID) {
+		return ErrInvalidExternalID
+	}
+	if ids.TmdbID < 0 {
+		return ErrInvalidExternalID
+	}
+	return nil
+}
+
+// IsValidImdbID reports whether the value looks like an IMDb title ID (tt0000000)
+func IsValidImdbID(id string) bool {
+	return imdbIDPattern.MatchString(id)
+}
+
+// Credit is a single cast or crew entry
+type Credit struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"` // director | writer | cast
+	Character string `json:"character,omitempty"`
+	Position  int    `json:"position"`
+}
+
+// Metadata is what a provider knows about a movie
+type Metadata struct {
+	Source      string     `json:"source"`
+	ImdbID      string     `json:"imdbId,omitempty"`
+	TmdbID      int64      `json:"tmdbId,omitempty"`
+	Title       string     `json:"title"`
+	Year        int        `json:"year"`
+	Plot        string     `json:"plot"`
+	Runtime     int        `json:"runtime"`
+	Rating      float32    `json:"rating"`
+	PosterURL   string     `json:"posterUrl"`
+	ReleaseDate *time.Time `json:"releaseDate"`
+	Credits     []Credit   `json:"credits"`
+}
+
+// Provider pulls movie metadata from an external catalog
+type Provider interface {
+	Name() string
+	FetchMovie(ctx context.Context, ids ExternalIDs) (*Metadata, error)
+}
@@ -0,0 +1,214 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"itv-movie/internal/config"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	TmdbSource = "tmdb"
+
+	defaultTmdbBaseURL      = "https://api.themoviedb.org/3"
+	defaultTmdbImageBaseURL = "https://image.tmdb.org/t/p/original"
+
+	// maxTmdbCast limits how many billed actors are kept
+	maxTmdbCast = 20
+)
+
+// HTTPClient is the subset of *http.Client used by providers
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TmdbClient is a Provider backed by The Movie Database API v3.
+// Pointing BaseURL at a local fixture server makes it usable in tests
+type TmdbClient struct {
+	baseURL      string
+	imageBaseURL string
+	apiKey       string
+	client       HTTPClient
+}
+
+// NewTmdbClient creates a TMDB client
+func NewTmdbClient(baseURL, imageBaseURL, apiKey string, client HTTPClient) *TmdbClient {
+	if baseURL == "" {
+		baseURL = defaultTmdbBaseURL
+	}
+	if imageBaseURL == "" {
+		imageBaseURL = defaultTmdbImageBaseURL
+	}
+
+	return &TmdbClient{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		imageBaseURL: strings.TrimRight(imageBaseURL, "/"),
+		apiKey:       apiKey,
+		client:       client,
+	}
+}
+
+// NewProvider creates the metadata provider configured in config
+func NewProvider(cfg *config.Config) Provider {
+	tmdbCfg := cfg.Internal.Enrichment.Tmdb
+
+	timeout := time.Duration(tmdbCfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return NewTmdbClient(tmdbCfg.BaseURL, tmdbCfg.ImageBaseURL, tmdbCfg.APIKey, &http.Client{Timeout: timeout})
+}
+
+func (c *TmdbClient) Name() string {
+	return TmdbSource
+}
+
+type tmdbMovie struct {
+	ID          int64   `json:"id"`
+	ImdbID      string  `json:"imdb_id"`
+	Title       string  `json:"title"`
+	Overview    string  `json:"overview"`
+	Runtime     int     `json:"runtime"`
+	ReleaseDate string  `json:"release_date"`
+	VoteAverage float32 `json:"vote_average"`
+	PosterPath  string  `json:"poster_path"`
+	Credits     struct {
+		Cast []struct {
+			Name      string `json:"name"`
+			Character string `json:"character"`
+			Order     int    `json:"order"`
+		} `json:"cast"`
+		Crew []struct {
+			Name       string `json:"name"`
+			Job        string `json:"job"`
+			Department string `json:"department"`
+		} `json:"crew"`
+	} `json:"credits"`
+}
+
+func (c *TmdbClient) FetchMovie(ctx context.Context, ids ExternalIDs) (*Metadata, error) {
+	if err := ids.Validate(); err != nil {
+		return nil, err
+	}
+
+	tmdbID := ids.TmdbID
+	if tmdbID == 0 {
+		var err error
+		if tmdbID, err = c.findByImdbID(ctx, ids.ImdbID); err != nil {
+			return nil, err
+		}
+	}
+
+	var movie tmdbMovie
+	query := url.Values{"append_to_response": {"credits"}}
+	if err := c.get(ctx, fmt.Sprintf("/movie/%d", tmdbID), query, &movie); err != nil {
+		return nil, err
+	}
+
+	return c.toMetadata(&movie), nil
+}
+
+func (c *TmdbClient) findByImdbID(ctx context.Context, imdbID string) (int64, error) {
+	var found struct {
+		MovieResults []struct {
+			ID int64 `json:"id"`
+		} `json:"movie_results"`
+	}
+
+	query := url.Values{"external_source": {"imdb_id"}}
+	if err := c.get(ctx, "/find/"+url.PathEscape(imdbID), query, &found); err != nil {
+		return 0, err
+	}
+
+	if len(found.MovieResults) == 0 {
+		return 0, ErrMovieNotFound
+	}
+
+	return found.MovieResults[0].ID, nil
+}
+
+func (c *TmdbClient) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	if c.apiKey != "" {
+		query.Set("api_key", c.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("tmdb request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrMovieNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tmdb request failed with status %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode tmdb response: %w", err)
+	}
+
+	return nil
+}
+
+func (c *TmdbClient) toMetadata(movie *tmdbMovie) *Metadata {
+	meta := &Metadata{
+		Source:  TmdbSource,
+		ImdbID:  movie.ImdbID,
+		TmdbID:  movie.ID,
+		Title:   movie.Title,
+		Plot:    movie.Overview,
+		Runtime: movie.Runtime,
+		Rating:  movie.VoteAverage,
+	}
+
+	if movie.PosterPath != "" {
+		meta.PosterURL = c.imageBaseURL + movie.PosterPath
+	}
+
+	if releaseDate, err := time.Parse("2006-01-02", movie.ReleaseDate); err == nil {
+		meta.ReleaseDate = &releaseDate
+		meta.Year = releaseDate.Year()
+	}
+
+	position := 0
+	for _, crew := range movie.Credits.Crew {
+		var role string
+		switch {
+		case crew.Job == "Director":
+			role = "director"
+		case crew.Department == "Writing" && (crew.Job == "Screenplay" || crew.Job == "Writer"):
+			role = "writer"
+		default:
+			continue
+		}
+		meta.Credits = append(meta.Credits, Credit{Name: crew.Name, Role: role, Position: position})
+		position++
+	}
+
+	for i, cast := range movie.Credits.Cast {
+		if i >= maxTmdbCast {
+			break
+		}
+		meta.Credits = append(meta.Credits, Credit{
+			Name:      cast.Name,
+			Role:      "cast",
+			Character: cast.Character,
+			Position:  position + cast.Order,
+		})
+	}
+
+	return meta
+}
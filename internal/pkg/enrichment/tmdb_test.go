@@ -0,0 +1,131 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const fixtureMovie = `{
+	"id": 603,
+	"imdb_id": "tt0133093",
+	"title": "The Matrix",
+	"overview": "A hacker learns the truth about his reality.",
+	"runtime": 136,
+	"release_date": "1999-03-30",
+	"vote_average": 8.2,
+	"poster_path": "/matrix.jpg",
+	"credits": {
+		"cast": [
+			{"name": "Keanu Reeves", "character": "Neo", "order": 0},
+			{"name": "Laurence Fishburne", "character": "Morpheus", "order": 1}
+		],
+		"crew": [
+			{"name": "Lana Wachowski", "job": "Director", "department": "Directing"},
+			{"name": "Lilly Wachowski", "job": "Screenplay", "department": "Writing"},
+			{"name": "Bill Pope", "job": "Director of Photography", "department": "Camera"}
+		]
+	}
+}`
+
+// newFixtureServer serves the TMDB endpoints the client uses, for The Matrix only
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/movie/603", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "secret" {
+			http.Error(w, `{"status_message": "Invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("append_to_response") != "credits" {
+			t.Errorf("credits were not requested: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(fixtureMovie))
+	})
+	mux.HandleFunc("/find/tt0133093", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("external_source") != "imdb_id" {
+			t.Errorf("unexpected external_source: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"movie_results": [{"id": 603}]}`))
+	})
+	mux.HandleFunc("/find/tt9999999", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"movie_results": []}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTmdbClientFetchMovie(t *testing.T) {
+	server := newFixtureServer(t)
+	client := NewTmdbClient(server.URL, "https://images.example/original", "secret", server.Client())
+
+	for name, ids := range map[string]ExternalIDs{
+		"by tmdb id": {TmdbID: 603},
+		"by imdb id": {ImdbID: "tt0133093"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			meta, err := client.FetchMovie(context.Background(), ids)
+			if err != nil {
+				t.Fatalf("FetchMovie: %v", err)
+			}
+
+			if meta.Source != TmdbSource || meta.TmdbID != 603 || meta.ImdbID != "tt0133093" {
+				t.Errorf("wrong identity: %+v", meta)
+			}
+			if meta.Title != "The Matrix" || meta.Runtime != 136 || meta.Rating != 8.2 || meta.Year != 1999 {
+				t.Errorf("wrong fields: %+v", meta)
+			}
+			if meta.ReleaseDate == nil || meta.ReleaseDate.Format("2006-01-02") != "1999-03-30" {
+				t.Errorf("wrong release date: %v", meta.ReleaseDate)
+			}
+			if meta.PosterURL != "https://images.example/original/matrix.jpg" {
+				t.Errorf("wrong poster URL: %s", meta.PosterURL)
+			}
+
+			want := []Credit{
+				{Name: "Lana Wachowski", Role: "director", Position: 0},
+				{Name: "Lilly Wachowski", Role: "writer", Position: 1},
+				{Name: "Keanu Reeves", Role: "cast", Character: "Neo", Position: 2},
+				{Name: "Laurence Fishburne", Role: "cast", Character: "Morpheus", Position: 3},
+			}
+			if len(meta.Credits) != len(want) {
+				t.Fatalf("got %d credits, want %d: %+v", len(meta.Credits), len(want), meta.Credits)
+			}
+			for i := range want {
+				if meta.Credits[i] != want[i] {
+					t.Errorf("credit %d = %+v, want %+v", i, meta.Credits[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTmdbClientNotFound(t *testing.T) {
+	server := newFixtureServer(t)
+	client := NewTmdbClient(server.URL, "", "secret", server.Client())
+
+	if _, err := client.FetchMovie(context.Background(), ExternalIDs{ImdbID: "tt9999999"}); !errors.Is(err, ErrMovieNotFound) {
+		t.Errorf("unknown IMDb ID: got %v, want ErrMovieNotFound", err)
+	}
+	if _, err := client.FetchMovie(context.Background(), ExternalIDs{TmdbID: 1}); !errors.Is(err, ErrMovieNotFound) {
+		t.Errorf("unknown TMDB ID: got %v, want ErrMovieNotFound", err)
+	}
+}
+
+func TestTmdbClientErrors(t *testing.T) {
+	server := newFixtureServer(t)
+
+	client := NewTmdbClient(server.URL, "", "wrong", server.Client())
+	if _, err := client.FetchMovie(context.Background(), ExternalIDs{TmdbID: 603}); err == nil || errors.Is(err, ErrMovieNotFound) {
+		t.Errorf("rejected API key: got %v, want a request error", err)
+	}
+
+	if _, err := client.FetchMovie(context.Background(), ExternalIDs{ImdbID: "nm0000206"}); !errors.Is(err, ErrInvalidExternalID) {
+		t.Errorf("malformed IMDb ID: got %v, want ErrInvalidExternalID", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package feed writes sitemaps and Atom feeds element by element, so large catalogs can be streamed
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const (
+	SitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	AtomNamespace    = "http://www.w3.org/2005/Atom"
+
+	// MaxSitemapURLs is the protocol limit of URLs in a single sitemap
+	MaxSitemapURLs = 50000
+)
+
+// SitemapURL is a <url> entry of a sitemap
+type SitemapURL struct {
+	XMLName xml.Name `xml:"url"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+// SitemapRef is a <sitemap> entry of a sitemap index
+type SitemapRef struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+// AtomHeader holds the feed level elements written before the entries
+type AtomHeader struct {
+	ID      string
+	Title   string
+	Author  string
+	Updated time.Time
+	Links   []AtomLink
+}
+
+// AtomLink is an Atom <link>
+type AtomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// AtomPerson is an Atom <author>
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+// AtomEntry is an Atom <entry>
+type AtomEntry struct {
+	XMLName   xml.Name    `xml:"entry"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published,omitempty"`
+	Links     []AtomLink  `xml:"link"`
+	Author    *AtomPerson `xml:"author,omitempty"`
+	Summary   string      `xml:"summary,omitempty"`
+}
+
+// Writer streams the children of a single root element
+type Writer struct {
+	enc  *xml.Encoder
+	root xml.StartElement
+}
+
+// NewSitemapWriter starts a <urlset>, write SitemapURL entries to it
+func NewSitemapWriter(w io.Writer) (*Writer, error) {
+	return newWriter(w, rootElement("urlset", SitemapNamespace))
+}
+
+// NewSitemapIndexWriter starts a <sitemapindex>, write SitemapRef entries to it
+func NewSitemapIndexWriter(w io.Writer) (*Writer, error) {
+	return newWriter(w, rootElement("sitemapindex", SitemapNamespace))
+}
+
+// NewAtomWriter starts a <feed> with the header elements, write AtomEntry entries to it
+func NewAtomWriter(w io.Writer, header AtomHeader) (*Writer, error) {
+	writer, err := newWriter(w, rootElement("feed", AtomNamespace))
+	if err != nil {
+		return nil, err
+	}
+
+	elements := []struct {
+		name  string
+		value interface{}
+	}{
+		{"id", header.ID},
+		{"title", header.Title},
+		{"updated", FormatTime(header.Updated)},
+		{"author", AtomPerson{Name: header.Author}},
+	}
+	for _, element := range elements {
+		if err = writer.enc.EncodeElement(element.value, xml.StartElement{Name: xml.Name{Local: element.name}}); err != nil {
+			return nil, err
+		}
+	}
+	for _, link := range header.Links {
+		if err = writer.enc.EncodeElement(link, xml.StartElement{Name: xml.Name{Local: "link"}}); err != nil {
+			return nil, err
+		}
+	}
+
+	return writer, nil
+}
+
+func newWriter(w io.Writer, root xml.StartElement) (*Writer, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+
+	enc := xml.NewEncoder(w)
+	if err := enc.EncodeToken(root); err != nil {
+		return nil, err
+	}
+
+	return &Writer{enc: enc, root: root}, nil
+}
+
+// Write encodes one entry
+func (w *Writer) Write(entry interface{}) error {
+	return w.enc.Encode(entry)
+}
+
+// Close ends the root element and flushes the output
+func (w *Writer) Close() error {
+	if err := w.enc.EncodeToken(w.root.End()); err != nil {
+		return err
+	}
+	return w.enc.Flush()
+}
+
+// FormatTime formats t the way both sitemaps and Atom expect (W3C datetime)
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func rootElement(name, namespace string) xml.StartElement {
+	return xml.StartElement{
+		Name: xml.Name{Local: name},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: namespace}},
+	}
+}
@@ -19,6 +19,7 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+	MfaToken     TokenType = "mfa" // proves the password was right, exchanged with a TOTP code for a session
 )
 
 // CustomClaims contains the claims we want in our JWT tokens
@@ -31,7 +32,8 @@ type CustomClaims struct {
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userId, username, email, role string, jwtConfig *config.Jwt, tokenType TokenType, duration time.Duration) (string, time.Time, error) {
+// GenerateToken signs a new token and returns it with its jti and expiry
+func GenerateToken(userId, username, email, role string, keys *KeySet, jwtConfig *config.Jwt, tokenType TokenType, duration time.Duration) (string, string, time.Time, error) {
 	now := time.Now()
 
 	if duration <= 0 {
@@ -66,26 +68,20 @@ func GenerateToken(userId, username, email, role string, jwtConfig *config.Jwt,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString([]byte(jwtConfig.Secret))
+	tokenString, err := keys.Sign(claims)
 	if err != nil {
-		return "", time.Time{}, err
+		return "", "", time.Time{}, err
 	}
 
-	return tokenString, expirationTime, nil
+	return tokenString, claims.ID, expirationTime, nil
 }
 
-func ValidateToken(tokenString, secretKey string) (*CustomClaims, error) {
+func ValidateToken(tokenString string, keys *KeySet) (*CustomClaims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&CustomClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(secretKey), nil
-		},
+		keys.keyFunc,
+		jwt.WithValidMethods([]string{keys.method.Alg()}),
 	)
 
 	if err != nil {
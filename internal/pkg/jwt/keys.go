@@ -0,0 +1,253 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"itv-movie/internal/config"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+var ErrNoSigningKey = errors.New("no active signing key")
+
+// KeySet signs and verifies tokens. In HS256 mode it uses the shared secret, in RS256 and EdDSA
+// mode a list of keys identified by kid: the newest key whose active_from has passed signs, and a
+// key keeps verifying until every token it could have signed has expired after its successor took over
+type KeySet struct {
+	method jwt.SigningMethod
+	secret []byte
+	keys   []*signingKey // oldest active_from first
+	maxTTL time.Duration
+	now    func() time.Time // decides which keys sign and verify, replaced in tests
+}
+
+type signingKey struct {
+	id         string
+	activeFrom time.Time
+	private    crypto.Signer
+	public     crypto.PublicKey
+}
+
+// JWK is a public key in the JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeySet loads the signing keys configured for the JWT algorithm
+func NewKeySet(cfg *config.Config) (*KeySet, error) {
+	jwtConfig := cfg.Internal.Jwt
+
+	ks := &KeySet{
+		maxTTL: time.Duration(max(jwtConfig.AccessTokenTTL, jwtConfig.RefreshTokenTTL)) * time.Second,
+		now:    time.Now,
+	}
+
+	switch jwtConfig.Algorithm {
+	case "", HS256:
+		if jwtConfig.Secret == "" {
+			return nil, errors.New("jwt: secret is required for HS256")
+		}
+		ks.method = jwt.SigningMethodHS256
+		ks.secret = []byte(jwtConfig.Secret)
+		return ks, nil
+	case RS256:
+		ks.method = jwt.SigningMethodRS256
+	case EdDSA:
+		ks.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %q", jwtConfig.Algorithm)
+	}
+
+	seen := make(map[string]bool)
+	for _, keyConfig := range jwtConfig.Keys {
+		if keyConfig.ID == "" {
+			return nil, errors.New("jwt: every key needs an id")
+		}
+		if seen[keyConfig.ID] {
+			return nil, fmt.Errorf("jwt: duplicate key id %q", keyConfig.ID)
+		}
+		seen[keyConfig.ID] = true
+
+		key, err := loadKey(keyConfig, ks.method)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %q: %w", keyConfig.ID, err)
+		}
+		ks.keys = append(ks.keys, key)
+	}
+
+	sort.SliceStable(ks.keys, func(i, j int) bool {
+		return ks.keys[i].activeFrom.Before(ks.keys[j].activeFrom)
+	})
+
+	if ks.signingKey(ks.now()) == nil {
+		return nil, fmt.Errorf("jwt: %w for %s", ErrNoSigningKey, ks.method.Alg())
+	}
+
+	return ks, nil
+}
+
+func loadKey(keyConfig config.JwtKey, method jwt.SigningMethod) (*signingKey, error) {
+	key := &signingKey{id: keyConfig.ID}
+
+	if keyConfig.ActiveFrom != "" {
+		activeFrom, err := time.Parse(time.RFC3339, keyConfig.ActiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("active_from must be an RFC 3339 timestamp: %w", err)
+		}
+		key.activeFrom = activeFrom
+	}
+
+	data, err := os.ReadFile(keyConfig.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var private any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		if method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("RSA key cannot sign %s", method.Alg())
+		}
+		if private.N.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits")
+		}
+		key.private, key.public = private, &private.PublicKey
+	case ed25519.PrivateKey:
+		if method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("Ed25519 key cannot sign %s", method.Alg())
+		}
+		key.private, key.public = private, private.Public()
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", private)
+	}
+
+	return key, nil
+}
+
+// signingKey returns the newest key already active at now
+func (ks *KeySet) signingKey(now time.Time) *signingKey {
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		if !ks.keys[i].activeFrom.After(now) {
+			return ks.keys[i]
+		}
+	}
+	return nil
+}
+
+// retired reports whether tokens signed by the i-th key have all expired, a key is retired once its
+// successor has been signing for longer than the longest token lifetime
+func (ks *KeySet) retired(i int, now time.Time) bool {
+	if i == len(ks.keys)-1 {
+		return false
+	}
+	return now.After(ks.keys[i+1].activeFrom.Add(ks.maxTTL))
+}
+
+// Sign signs the claims with the current key
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.secret != nil {
+		return jwt.NewWithClaims(ks.method, claims).SignedString(ks.secret)
+	}
+
+	key := ks.signingKey(ks.now())
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(ks.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// keyFunc resolves the verification key of a token by its kid
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != ks.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	if ks.secret != nil {
+		return ks.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	now := ks.now()
+	for i, key := range ks.keys {
+		if key.id == kid && !ks.retired(i, now) {
+			return key.public, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// JWKS returns the public keys that still verify tokens. Upcoming keys are published before they
+// start signing so consumers have them cached in time. It is empty in HS256 mode, the secret is never published
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+
+	now := ks.now()
+	for i, key := range ks.keys {
+		if ks.retired(i, now) {
+			continue
+		}
+
+		jwk := JWK{Use: "sig", Alg: ks.method.Alg(), Kid: key.id}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
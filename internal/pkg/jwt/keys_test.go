@@ -0,0 +1,254 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"itv-movie/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// the rotation used by the tests: old signs until current takes over, next is published ahead of time
+var (
+	oldFrom     = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	currentFrom = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	nextFrom    = time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+const testMaxTTL = 24 * time.Hour
+
+// writeKey stores a generated private key of the algorithm as PEM and returns the file
+func writeKey(t *testing.T, algorithm string) string {
+	t.Helper()
+
+	var block *pem.Block
+	switch algorithm {
+	case RS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("generating RSA key: %v", err)
+		}
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	case EdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("generating Ed25519 key: %v", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("encoding Ed25519 key: %v", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	file, err := os.CreateTemp(t.TempDir(), "key-*.pem")
+	if err != nil {
+		t.Fatalf("creating key file: %v", err)
+	}
+	defer file.Close()
+
+	if err = pem.Encode(file, block); err != nil {
+		t.Fatalf("writing key file: %v", err)
+	}
+	return file.Name()
+}
+
+func jwtConfig(algorithm string, keys ...config.JwtKey) *config.Config {
+	cfg := &config.Config{}
+	cfg.Internal.Jwt = config.Jwt{
+		Algorithm:       algorithm,
+		AccessTokenTTL:  int((15 * time.Minute).Seconds()),
+		RefreshTokenTTL: int(testMaxTTL.Seconds()),
+		Keys:            keys,
+	}
+	return cfg
+}
+
+func newRotatingKeySet(t *testing.T, algorithm string) *KeySet {
+	t.Helper()
+
+	// listed out of order, the key set sorts them by active_from
+	ks, err := NewKeySet(jwtConfig(algorithm,
+		config.JwtKey{ID: "next", PrivateKeyFile: writeKey(t, algorithm), ActiveFrom: nextFrom.Format(time.RFC3339)},
+		config.JwtKey{ID: "old", PrivateKeyFile: writeKey(t, algorithm), ActiveFrom: oldFrom.Format(time.RFC3339)},
+		config.JwtKey{ID: "current", PrivateKeyFile: writeKey(t, algorithm), ActiveFrom: currentFrom.Format(time.RFC3339)},
+	))
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	return ks
+}
+
+func TestKeyRotation(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		signer    string
+		verifying []string // also the keys published in the JWKS
+	}{
+		{"before the successor", currentFrom.Add(-time.Second), "old", []string{"old", "current", "next"}},
+		{"successor takes over", currentFrom, "current", []string{"old", "current", "next"}},
+		{"predecessor tokens may still live", currentFrom.Add(testMaxTTL), "current", []string{"old", "current", "next"}},
+		{"predecessor retired", currentFrom.Add(testMaxTTL + time.Second), "current", []string{"current", "next"}},
+		{"upcoming key takes over", nextFrom, "next", []string{"current", "next"}},
+		{"upcoming key alone", nextFrom.Add(testMaxTTL + time.Second), "next", []string{"next"}},
+	}
+
+	for _, algorithm := range []string{RS256, EdDSA} {
+		ks := newRotatingKeySet(t, algorithm)
+
+		for _, tt := range tests {
+			t.Run(algorithm+"/"+tt.name, func(t *testing.T) {
+				ks.now = func() time.Time { return tt.now }
+
+				if key := ks.signingKey(tt.now); key == nil || key.id != tt.signer {
+					t.Errorf("signing key: got %v, want %s", key, tt.signer)
+				}
+
+				verifying := make(map[string]bool)
+				for _, id := range tt.verifying {
+					verifying[id] = true
+				}
+
+				for _, key := range ks.keys {
+					token := &jwt.Token{Method: ks.method, Header: map[string]interface{}{"kid": key.id}}
+					public, err := ks.keyFunc(token)
+					if verifying[key.id] && (err != nil || !key.public.(interface{ Equal(crypto.PublicKey) bool }).Equal(public)) {
+						t.Errorf("key %s does not verify: %v", key.id, err)
+					}
+					if !verifying[key.id] && err == nil {
+						t.Errorf("retired key %s still verifies", key.id)
+					}
+				}
+
+				var published []string
+				for _, jwk := range ks.JWKS().Keys {
+					published = append(published, jwk.Kid)
+					if jwk.Alg != algorithm || jwk.Use != "sig" {
+						t.Errorf("key %s published as %s/%s", jwk.Kid, jwk.Alg, jwk.Use)
+					}
+				}
+				if len(published) != len(tt.verifying) {
+					t.Fatalf("published %v, want %v", published, tt.verifying)
+				}
+				for i := range published {
+					if published[i] != tt.verifying[i] {
+						t.Errorf("published %v, want %v", published, tt.verifying)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestKeyFuncRejects(t *testing.T) {
+	ks := newRotatingKeySet(t, RS256)
+	ks.now = func() time.Time { return currentFrom }
+
+	tests := []struct {
+		name  string
+		token *jwt.Token
+	}{
+		{"unknown kid", &jwt.Token{Method: jwt.SigningMethodRS256, Header: map[string]interface{}{"kid": "rotated-away"}}},
+		{"no kid", &jwt.Token{Method: jwt.SigningMethodRS256, Header: map[string]interface{}{}}},
+		{"another algorithm", &jwt.Token{Method: jwt.SigningMethodHS256, Header: map[string]interface{}{"kid": "current"}}},
+		{"alg none", &jwt.Token{Method: jwt.SigningMethodNone, Header: map[string]interface{}{"kid": "current"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ks.keyFunc(tt.token); err == nil {
+				t.Error("token was accepted")
+			}
+		})
+	}
+}
+
+func TestSignAndValidate(t *testing.T) {
+	for _, algorithm := range []string{RS256, EdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			ks := newRotatingKeySet(t, algorithm)
+			cfg := jwtConfig(algorithm)
+
+			token, _, _, err := GenerateToken("user-1", "neo", "neo@example.com", "user", ks, &cfg.Internal.Jwt, AccessToken, time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &CustomClaims{})
+			if err != nil || parsed.Header["kid"] != "current" {
+				t.Errorf("token is not signed by the current key: %v %v", parsed.Header, err)
+			}
+
+			claims, err := ValidateToken(token, ks)
+			if err != nil {
+				t.Fatalf("ValidateToken: %v", err)
+			}
+			if claims.UserID != "user-1" || claims.TokenType != AccessToken {
+				t.Errorf("wrong claims: %+v", claims)
+			}
+
+			// a token of a retired key no longer validates
+			ks.now = func() time.Time { return nextFrom.Add(testMaxTTL + time.Second) }
+			if _, err = ValidateToken(token, ks); err == nil || errors.Is(err, ErrExpiredToken) {
+				t.Errorf("got %v, want an unknown key error", err)
+			}
+		})
+	}
+}
+
+func TestNewKeySet(t *testing.T) {
+	rsaKey := writeKey(t, RS256)
+	edKey := writeKey(t, EdDSA)
+	future := nextFrom.Format(time.RFC3339)
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want error
+	}{
+		{"only upcoming keys", jwtConfig(RS256, config.JwtKey{ID: "next", PrivateKeyFile: rsaKey, ActiveFrom: future}), ErrNoSigningKey},
+		{"no keys", jwtConfig(EdDSA), ErrNoSigningKey},
+		{"RSA key for EdDSA", jwtConfig(EdDSA, config.JwtKey{ID: "a", PrivateKeyFile: rsaKey}), nil},
+		{"Ed25519 key for RS256", jwtConfig(RS256, config.JwtKey{ID: "a", PrivateKeyFile: edKey}), nil},
+		{"duplicate kid", jwtConfig(RS256, config.JwtKey{ID: "a", PrivateKeyFile: rsaKey}, config.JwtKey{ID: "a", PrivateKeyFile: rsaKey}), nil},
+		{"missing kid", jwtConfig(RS256, config.JwtKey{PrivateKeyFile: rsaKey}), nil},
+		{"bad active_from", jwtConfig(RS256, config.JwtKey{ID: "a", PrivateKeyFile: rsaKey, ActiveFrom: "tomorrow"}), nil},
+		{"missing file", jwtConfig(RS256, config.JwtKey{ID: "a", PrivateKeyFile: filepath.Join(t.TempDir(), "none.pem")}), nil},
+		{"HS256 without secret", jwtConfig(HS256), nil},
+		{"unknown algorithm", jwtConfig("ES256"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeySet(tt.cfg)
+			if err == nil {
+				t.Fatal("configuration was accepted")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHS256PublishesNothing(t *testing.T) {
+	cfg := jwtConfig(HS256)
+	cfg.Internal.Jwt.Secret = "secret"
+
+	ks, err := NewKeySet(cfg)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	if keys := ks.JWKS().Keys; len(keys) != 0 {
+		t.Errorf("published %v", keys)
+	}
+}
@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer writes every email as an .eml file for local development
+type FileMailer struct {
+	dir  string
+	from *mail.Address
+}
+
+// NewFileMailer creates a mailer writing to dir, the directory is created if needed
+func NewFileMailer(dir string, from *mail.Address) (*FileMailer, error) {
+	if dir == "" {
+		dir = "mail"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(_ context.Context, msg *Message) error {
+	data, err := compose(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_", " ", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), recipient)
+
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o644)
+}
@@ -0,0 +1,20 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogMailer only logs the emails, links included, so nothing leaves the machine
+type LogMailer struct {
+	log *slog.Logger
+}
+
+func NewLogMailer(log *slog.Logger) *LogMailer {
+	return &LogMailer{log: log}
+}
+
+func (m *LogMailer) Send(_ context.Context, msg *Message) error {
+	m.log.Info("email", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
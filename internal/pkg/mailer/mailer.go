@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"itv-movie/internal/config"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"time"
+)
+
+const (
+	SmtpDriver = "smtp"
+	FileDriver = "file"
+	LogDriver  = "log"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is the pluggable transport for outgoing emails
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewMailer creates the mailer implementation selected in config
+func NewMailer(cfg *config.Config, log *slog.Logger) (Mailer, error) {
+	mailCfg := cfg.Internal.Mail
+
+	from, err := mail.ParseAddress(mailCfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("mail: invalid from address %q: %w", mailCfg.From, err)
+	}
+
+	switch mailCfg.Driver {
+	case SmtpDriver:
+		return NewSmtpMailer(mailCfg.Smtp, from), nil
+	case FileDriver:
+		return NewFileMailer(mailCfg.FilePath, from)
+	case LogDriver, "":
+		return NewLogMailer(log), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", mailCfg.Driver)
+	}
+}
+
+// compose renders the message as RFC 5322 text with a quoted-printable UTF-8 body
+func compose(from *mail.Address, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"itv-movie/internal/config"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+// SmtpMailer delivers emails through an SMTP relay, STARTTLS is used when the server offers it
+type SmtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from *mail.Address
+}
+
+// NewSmtpMailer creates a mailer for the configured relay, authentication is skipped without a username
+func NewSmtpMailer(cfg config.Smtp, from *mail.Address) *SmtpMailer {
+	m := &SmtpMailer{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		from: from,
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m
+}
+
+func (m *SmtpMailer) Send(ctx context.Context, msg *Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+
+	data, err := compose(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	// net/smtp has no context support, run it aside so a slow relay does not outlive the request
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, m.auth, m.from.Address, []string{to.Address}, data)
+	}()
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
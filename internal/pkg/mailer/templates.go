@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	VerifyEmailTemplate   = "verify_email"
+	ResetPasswordTemplate = "reset_password"
+
+	DefaultLocale = "en"
+)
+
+// Locales are the languages every template is translated to
+var Locales = []string{"uz", "ru", "en"}
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates are named <name>.<locale>.tmpl, the first line is the subject and the rest the body
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// IsSupportedLocale reports whether the templates are translated to locale
+func IsSupportedLocale(locale string) bool {
+	for _, l := range Locales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Render fills the template in the given locale, falling back to English, and returns the message without a recipient
+func Render(name, locale string, data any) (*Message, error) {
+	if !IsSupportedLocale(locale) {
+		locale = DefaultLocale
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, fmt.Sprintf("%s.%s.tmpl", name, locale), data); err != nil {
+		return nil, err
+	}
+
+	subject, body, _ := strings.Cut(buf.String(), "\n")
+
+	return &Message{
+		Subject: strings.TrimSpace(subject),
+		Body:    strings.TrimLeft(body, "\n"),
+	}, nil
+}
@@ -0,0 +1,268 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"itv-movie/internal/config"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown OIDC provider")
+	ErrInvalidIDToken  = errors.New("invalid ID token")
+	ErrProviderFailed  = errors.New("OIDC provider request failed")
+)
+
+// HTTPClient is the subset of *http.Client used to talk to the providers
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Discovery is the part of the provider metadata (/.well-known/openid-configuration) the flow needs
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// Tokens is the token endpoint response
+type Tokens struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+// Provider is an OpenID Connect relying party for one identity provider, using the authorization
+// code flow with PKCE. Discovery runs on first use, so a provider being down does not stop the service.
+// Pointing the issuer at a local mock issuer makes it usable in tests, plain http is allowed for that
+type Provider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       HTTPClient
+
+	mu        sync.Mutex
+	discovery *Discovery
+	keys      *keySet
+}
+
+// NewProvider creates a relying party from its config, openid is always requested
+func NewProvider(cfg config.OidcProvider, client HTTPClient) *Provider {
+	scopes := []string{"openid"}
+	for _, scope := range cfg.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 1 {
+		scopes = append(scopes, "email", "profile")
+	}
+
+	return &Provider{
+		name:         cfg.Name,
+		issuer:       strings.TrimRight(cfg.Issuer, "/"),
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		scopes:       scopes,
+		client:       client,
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// RedirectURL is the callback URL registered at the provider
+func (p *Provider) RedirectURL() string {
+	return p.redirectURL
+}
+
+// Discover fetches and caches the provider metadata, the issuer it reports must be the configured one
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery Discovery
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("%w: discovery of %s: %v", ErrProviderFailed, p.name, err)
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("%w: discovery of %s: issuer %q does not match %q", ErrProviderFailed, p.name, discovery.Issuer, p.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return nil, fmt.Errorf("%w: discovery of %s: incomplete provider metadata", ErrProviderFailed, p.name)
+	}
+
+	p.discovery = &discovery
+	p.keys = newKeySet(discovery.JwksURI, p.client)
+	return p.discovery, nil
+}
+
+// AuthCodeURL returns the authorization endpoint URL the browser is sent to
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.clientID)
+	query.Set("redirect_uri", p.redirectURL)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange redeems the authorization code at the token endpoint
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Tokens, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("client_id", p.clientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	var tokens Tokens
+	if err = p.do(req, &tokens); err != nil {
+		return nil, fmt.Errorf("%w: token exchange with %s: %v", ErrProviderFailed, p.name, err)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: token exchange with %s: no id_token in the response", ErrProviderFailed, p.name)
+	}
+
+	return &tokens, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	return p.do(req, v)
+}
+
+func (p *Provider) do(req *http.Request, v interface{}) error {
+	return doJSON(p.client, req, v)
+}
+
+func doJSON(client HTTPClient, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return fmt.Errorf("%s: %s %s", resp.Status, oauthErr.Error, oauthErr.Description)
+		}
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// CodeChallenge derives the S256 PKCE challenge of a verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Registry holds the configured providers by name
+type Registry struct {
+	providers map[string]*Provider
+	names     []string
+}
+
+// NewRegistry creates the providers configured in config
+func NewRegistry(cfg *config.Config) (*Registry, error) {
+	oidcCfg := cfg.Internal.Oidc
+
+	timeout := time.Duration(oidcCfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	registry := &Registry{providers: make(map[string]*Provider)}
+	for _, providerCfg := range oidcCfg.Providers {
+		if providerCfg.Name == "" || providerCfg.Issuer == "" || providerCfg.ClientID == "" || providerCfg.RedirectURL == "" {
+			return nil, fmt.Errorf("oidc: provider %q needs name, issuer, client_id and redirect_url", providerCfg.Name)
+		}
+		if _, ok := registry.providers[providerCfg.Name]; ok {
+			return nil, fmt.Errorf("oidc: duplicate provider %q", providerCfg.Name)
+		}
+		registry.providers[providerCfg.Name] = NewProvider(providerCfg, client)
+		registry.names = append(registry.names, providerCfg.Name)
+	}
+
+	return registry, nil
+}
+
+// Get returns the provider with the given name
+func (r *Registry) Get(name string) (*Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// Names lists the configured providers in config order
+func (r *Registry) Names() []string {
+	return r.names
+}
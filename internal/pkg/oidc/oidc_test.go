@@ -0,0 +1,365 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"itv-movie/internal/config"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID     = "movie-service"
+	testClientSecret = "s3cret"
+	testKeyID        = "test-key"
+	testRedirectURL  = "http://localhost:8080/api/v1/auth/oidc/mock/callback"
+)
+
+// testIssuer is a minimal OpenID provider: discovery, JWKS and a token endpoint that checks PKCE
+type testIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]url.Values // authorization code -> query of the authorization request
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	issuer := &testIssuer{t: t, key: key, codes: make(map[string]url.Values)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": testKeyID,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", issuer.token)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) provider() *Provider {
+	return NewProvider(config.OidcProvider{
+		Name:         "mock",
+		Issuer:       i.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	}, i.server.Client())
+}
+
+// authorize plays the user signing in at the provider and returns the code the browser brings back
+func (i *testIssuer) authorize(query url.Values) string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	code := "code-" + query.Get("state")
+	i.codes[code] = query
+	return code
+}
+
+func (i *testIssuer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != testClientID || secret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	// codes are single use
+	i.mu.Lock()
+	request, ok := i.codes[r.PostForm.Get("code")]
+	delete(i.codes, r.PostForm.Get("code"))
+	i.mu.Unlock()
+
+	if !ok || r.PostForm.Get("redirect_uri") != request.Get("redirect_uri") ||
+		CodeChallenge(r.PostForm.Get("code_verifier")) != request.Get("code_challenge") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"id_token":     i.sign(i.claims(request.Get("nonce"))),
+	})
+}
+
+func (i *testIssuer) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            i.server.URL,
+		"aud":            testClientID,
+		"sub":            "subject-1",
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          "neo@example.com",
+		"email_verified": "true",
+		"name":           "Thomas Anderson",
+	}
+}
+
+func (i *testIssuer) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		i.t.Fatalf("signing: %v", err)
+	}
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token func(claims jwt.MapClaims) string
+		valid bool
+	}{
+		{
+			name:  "valid",
+			token: issuer.sign,
+			valid: true,
+		},
+		{
+			name: "alg none",
+			token: func(claims jwt.MapClaims) string {
+				signed, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+				return signed
+			},
+		},
+		{
+			// the public key used as an HMAC secret must not verify
+			name: "alg HS256",
+			token: func(claims jwt.MapClaims) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+				token.Header["kid"] = testKeyID
+				signed, _ := token.SignedString(x509.MarshalPKCS1PublicKey(&issuer.key.PublicKey))
+				return signed
+			},
+		},
+		{
+			name: "signed by another key",
+			token: func(claims jwt.MapClaims) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+				token.Header["kid"] = testKeyID
+				signed, _ := token.SignedString(otherKey)
+				return signed
+			},
+		},
+		{
+			name: "unknown kid",
+			token: func(claims jwt.MapClaims) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+				token.Header["kid"] = "rotated-away"
+				signed, _ := token.SignedString(issuer.key)
+				return signed
+			},
+		},
+		{
+			name: "wrong issuer",
+			token: func(claims jwt.MapClaims) string {
+				claims["iss"] = "https://evil.example"
+				return issuer.sign(claims)
+			},
+		},
+		{
+			name: "wrong audience",
+			token: func(claims jwt.MapClaims) string {
+				claims["aud"] = "another-client"
+				return issuer.sign(claims)
+			},
+		},
+		{
+			name: "nonce mismatch",
+			token: func(claims jwt.MapClaims) string {
+				claims["nonce"] = "replayed"
+				return issuer.sign(claims)
+			},
+		},
+		{
+			name: "expired beyond the leeway",
+			token: func(claims jwt.MapClaims) string {
+				claims["exp"] = time.Now().Add(-2 * time.Minute).Unix()
+				return issuer.sign(claims)
+			},
+		},
+		{
+			name: "no expiry",
+			token: func(claims jwt.MapClaims) string {
+				delete(claims, "exp")
+				return issuer.sign(claims)
+			},
+		},
+		{
+			name: "issued in the future",
+			token: func(claims jwt.MapClaims) string {
+				claims["iat"] = time.Now().Add(10 * time.Minute).Unix()
+				return issuer.sign(claims)
+			},
+		},
+		{
+			name: "no subject",
+			token: func(claims jwt.MapClaims) string {
+				delete(claims, "sub")
+				return issuer.sign(claims)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := issuer.provider().VerifyIDToken(context.Background(), tt.token(issuer.claims("nonce-1")), "nonce-1")
+
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidIDToken) {
+					t.Fatalf("got %v, want ErrInvalidIDToken", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VerifyIDToken: %v", err)
+			}
+			if claims.Subject != "subject-1" || claims.Email != "neo@example.com" || !bool(claims.EmailVerified) {
+				t.Errorf("wrong claims: %+v", claims)
+			}
+		})
+	}
+}
+
+// TestCallbackFlow runs what the callback does with the provider: the authorization request,
+// the code exchange with PKCE and the ID token verification
+func TestCallbackFlow(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := issuer.provider()
+	ctx := context.Background()
+
+	const (
+		state    = "state-1"
+		nonce    = "nonce-1"
+		verifier = "a-code-verifier-that-is-long-enough-for-pkce-0123456789"
+	)
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", authURL, err)
+	}
+	if !strings.HasPrefix(authURL, issuer.server.URL+"/authorize?") {
+		t.Errorf("authorization URL %s is not the discovered endpoint", authURL)
+	}
+
+	query := parsed.Query()
+	for param, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 state,
+		"nonce":                 nonce,
+		"code_challenge":        CodeChallenge(verifier),
+		"code_challenge_method": "S256",
+	} {
+		if got := query.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+
+	code := issuer.authorize(query)
+
+	if _, err = provider.Exchange(ctx, code, "another-verifier"); !errors.Is(err, ErrProviderFailed) {
+		t.Fatalf("exchange with a wrong verifier: got %v, want ErrProviderFailed", err)
+	}
+
+	code = issuer.authorize(query)
+	tokens, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, nonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Subject != "subject-1" || claims.Name != "Thomas Anderson" {
+		t.Errorf("wrong claims: %+v", claims)
+	}
+
+	if _, err = provider.Exchange(ctx, code, verifier); !errors.Is(err, ErrProviderFailed) {
+		t.Errorf("reused code: got %v, want ErrProviderFailed", err)
+	}
+}
+
+func TestDiscoverRejectsForeignIssuer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 "https://evil.example",
+			"authorization_endpoint": "https://evil.example/authorize",
+			"token_endpoint":         "https://evil.example/token",
+			"jwks_uri":               "https://evil.example/jwks",
+		})
+	}))
+	defer server.Close()
+
+	provider := NewProvider(config.OidcProvider{Name: "mock", Issuer: server.URL, ClientID: testClientID}, server.Client())
+	if _, err := provider.Discover(context.Background()); !errors.Is(err, ErrProviderFailed) {
+		t.Errorf("got %v, want ErrProviderFailed", err)
+	}
+}
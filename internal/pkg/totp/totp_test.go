@@ -0,0 +1,119 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 Appendix B SHA1 seed "12345678901234567890", base32 encoded
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// Appendix B lists 8 digit codes, these are their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		if code != tt.code {
+			t.Errorf("T=%d: got %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestCodeAcceptsLowerCaseSecret(t *testing.T) {
+	code, err := Code(strings.ToLower(rfcSecret), Step(time.Unix(59, 0)))
+	if err != nil || code != "287082" {
+		t.Errorf("got %s, %v, want 287082", code, err)
+	}
+
+	if _, err = Code("not base32!", 1); err == nil {
+		t.Error("an invalid secret was accepted")
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+
+	for offset := int64(-2); offset <= 2; offset++ {
+		code, err := Code(rfcSecret, current+offset)
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+
+		step, ok := Validate(rfcSecret, code, now, 1)
+		if offset >= -1 && offset <= 1 {
+			if !ok {
+				t.Errorf("offset %d: code of a neighbouring step was rejected", offset)
+			}
+			// the step of the code, not of now, is what UseTotpStep stores to block a replay
+			if step != current+offset {
+				t.Errorf("offset %d: got step %d, want %d", offset, step, current+offset)
+			}
+		} else if ok {
+			t.Errorf("offset %d: code outside the skew was accepted", offset)
+		}
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	if _, ok := Validate(rfcSecret, " 005 924 ", now, 0); !ok {
+		t.Error("code with spaces was rejected")
+	}
+	for _, code := range []string{"", "00592", "0059244", "abcdef", "005925"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("code %q was accepted", code)
+		}
+	}
+	if _, ok := Validate("not base32!", "005924", now, 1); ok {
+		t.Error("code was accepted for an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Errorf("secret %q does not decode to 160 bits: %v", secret, err)
+	}
+
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Error("two secrets are equal")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI(rfcSecret, "ITV Movie", "neo@example.com"))
+	if err != nil {
+		t.Fatalf("parsing: %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/ITV Movie:neo@example.com" {
+		t.Errorf("wrong URI: %s", uri)
+	}
+	query := uri.Query()
+	if query.Get("secret") != rfcSecret || query.Get("issuer") != "ITV Movie" || query.Get("digits") != "6" ||
+		query.Get("period") != "30" || query.Get("algorithm") != "SHA1" {
+		t.Errorf("wrong parameters: %s", uri.RawQuery)
+	}
+}
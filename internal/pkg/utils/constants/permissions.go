@@ -0,0 +1,36 @@
+package constants
+
+// Permissions checked by the routes. The permissions table holds the same names, roles are granted them
+// through role_permissions
+const (
+	MovieCreate = "movie:create"
+	MovieUpdate = "movie:update" // includes enrichment
+	MovieDelete = "movie:delete"
+	MovieMerge  = "movie:merge" // duplicate search and merge
+
+	MediaUpload = "media:upload"
+	MediaDelete = "media:delete"
+
+	GenreCreate = "genre:create"
+	GenreUpdate = "genre:update"
+	GenreDelete = "genre:delete"
+
+	LanguageCreate = "language:create"
+	LanguageUpdate = "language:update"
+	LanguageDelete = "language:delete"
+
+	CountryCreate = "country:create"
+	CountryUpdate = "country:update"
+	CountryDelete = "country:delete"
+
+	AwardCreate = "award:create" // awards, categories and nominations
+	AwardUpdate = "award:update"
+	AwardDelete = "award:delete"
+
+	TagManage    = "tag:manage"
+	StatsRead    = "stats:read"
+	JobsRun      = "jobs:run" // recompute recommendations and popularity
+	UserManage   = "user:manage"
+	ApiKeyManage = "apikey:manage"
+	RoleManage   = "role:manage" // edit roles and assign them to users
+)
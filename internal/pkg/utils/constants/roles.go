@@ -1,5 +1,6 @@
 package constants
 
+// Roles seeded in the roles table. ADMIN always holds every permission, the others are editable
 const (
 	AdminRole    = "ADMIN"
 	DirectorRole = "DIRECTOR"
@@ -0,0 +1,62 @@
+package textutil
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var trailingYearPattern = regexp.MustCompile(`\s*[(\[]\d{4}[)\]]\s*$`)
+
+// NormalizeTitle lowercases the title, drops a trailing "(2010)" year and keeps only letters and digits
+func NormalizeTitle(title string) string {
+	title = trailingYearPattern.ReplaceAllString(strings.TrimSpace(title), "")
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Similarity returns a 0..1 score based on the Levenshtein distance of two strings
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
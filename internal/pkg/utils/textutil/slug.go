@@ -0,0 +1,74 @@
+package textutil
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// maxSlugLength keeps slugs readable in URLs, longer input is cut at a word boundary
+const maxSlugLength = 80
+
+// transliterations covers Russian and Uzbek Cyrillic plus a few Latin letters without a decomposition
+var transliterations = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	// Uzbek Cyrillic
+	'ў': "o", 'қ': "q", 'ғ': "g", 'ҳ': "h",
+	// Ukrainian / Kazakh letters that show up in titles
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ә': "a", 'ө': "o", 'ү': "u", 'ұ': "u", 'ң': "ng",
+	// Latin letters NFD does not split
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+}
+
+// Uzbek Latin writes oʻ / gʻ and the tutuq belgisi with all kinds of apostrophes, they are dropped
+var apostrophes = map[rune]bool{
+	'\'': true, 'ʻ': true, 'ʼ': true, '‘': true, '’': true, '`': true, 'ʹ': true,
+}
+
+// Slugify turns text into a lowercase ASCII slug, transliterating Cyrillic and stripping diacritics
+func Slugify(text string) string {
+	var b strings.Builder
+	dash := false
+
+	write := func(s string) {
+		if s != "" {
+			b.WriteString(s)
+			dash = false
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		// transliterate before decomposing, NFD would turn й into и + breve
+		if t, ok := transliterations[r]; ok {
+			write(t)
+			continue
+		}
+
+		for _, d := range norm.NFD.String(string(r)) {
+			switch {
+			case unicode.Is(unicode.Mn, d) || apostrophes[d]:
+				continue
+			case d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)):
+				write(string(d))
+			case b.Len() > 0 && !dash:
+				b.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > maxSlugLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.Trim(slug, "-")
+	}
+
+	return slug
+}
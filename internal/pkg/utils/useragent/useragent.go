@@ -0,0 +1,88 @@
+package useragent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Client is what a User-Agent header tells about the device, unknown parts are empty
+type Client struct {
+	Browser string `json:"browser"`
+	OS      string `json:"os"`
+	Mobile  bool   `json:"mobile"`
+}
+
+// browsers are checked in order, Chromium based browsers and WebViews also send "Chrome" and "Safari"
+var browsers = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/(\d+)`)},
+	{"Yandex Browser", regexp.MustCompile(`YaBrowser/(\d+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/(\d+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`)},
+	{"Safari", regexp.MustCompile(`Version/(\d+)(?:\.\d+)*.*Safari/`)},
+	{"Postman", regexp.MustCompile(`PostmanRuntime/(\d+)`)},
+	{"curl", regexp.MustCompile(`curl/(\d+)`)},
+	{"okhttp", regexp.MustCompile(`okhttp/(\d+)`)},
+	{"Go HTTP client", regexp.MustCompile(`Go-http-client/(\d+)`)},
+}
+
+var (
+	windowsPattern = regexp.MustCompile(`Windows NT (\d+\.\d+)`)
+	iosPattern     = regexp.MustCompile(`(?:iPhone|CPU) OS (\d+)`)
+	macPattern     = regexp.MustCompile(`Mac OS X (\d+)[._](\d+)`)
+	androidPattern = regexp.MustCompile(`Android (\d+)`)
+)
+
+// windowsVersions maps NT versions to marketing names, Windows 11 still reports 10.0
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+}
+
+// Parse recognises the common browsers, operating systems and HTTP clients
+func Parse(userAgent string) Client {
+	client := Client{
+		Mobile: strings.Contains(userAgent, "Mobile") || strings.Contains(userAgent, "Android") ||
+			strings.Contains(userAgent, "iPhone"),
+	}
+
+	for _, browser := range browsers {
+		if match := browser.pattern.FindStringSubmatch(userAgent); match != nil {
+			client.Browser = browser.name + " " + match[1]
+			break
+		}
+	}
+
+	switch {
+	case windowsPattern.MatchString(userAgent):
+		version := windowsPattern.FindStringSubmatch(userAgent)[1]
+		if name, ok := windowsVersions[version]; ok {
+			version = name
+		}
+		client.OS = "Windows " + version
+	case strings.Contains(userAgent, "iPad"):
+		client.OS = "iPadOS"
+		if match := iosPattern.FindStringSubmatch(userAgent); match != nil {
+			client.OS += " " + match[1]
+		}
+	case iosPattern.MatchString(userAgent):
+		client.OS = "iOS " + iosPattern.FindStringSubmatch(userAgent)[1]
+	case macPattern.MatchString(userAgent):
+		match := macPattern.FindStringSubmatch(userAgent)
+		client.OS = "macOS " + match[1] + "." + match[2]
+	case androidPattern.MatchString(userAgent):
+		client.OS = "Android " + androidPattern.FindStringSubmatch(userAgent)[1]
+	case strings.Contains(userAgent, "CrOS"):
+		client.OS = "ChromeOS"
+	case strings.Contains(userAgent, "Linux"):
+		client.OS = "Linux"
+	}
+
+	return client
+}
@@ -0,0 +1,132 @@
+package video
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	YouTubeProvider = "youtube"
+	VimeoProvider   = "vimeo"
+
+	youTubeOEmbedEndpoint = "https://www.youtube.com/oembed"
+	vimeoOEmbedEndpoint   = "https://vimeo.com/api/oembed.json"
+)
+
+var (
+	youTubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoIDPattern   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// YouTube recognises youtube.com, youtu.be and youtube-nocookie.com links
+type YouTube struct {
+	// OEmbedEndpoint overrides the public oEmbed endpoint, used for local fixtures
+	OEmbedEndpoint string
+}
+
+func (p *YouTube) Name() string {
+	return YouTubeProvider
+}
+
+func (p *YouTube) ExtractID(u *url.URL) (string, bool) {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := pathSegments(u)
+
+	var id string
+	switch host {
+	case "youtu.be":
+		if len(segments) > 0 {
+			id = segments[0]
+		}
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		switch {
+		case len(segments) == 1 && segments[0] == "watch":
+			id = u.Query().Get("v")
+		case len(segments) >= 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "v" || segments[0] == "live"):
+			id = segments[1]
+		}
+	}
+
+	if !youTubeIDPattern.MatchString(id) {
+		return "", false
+	}
+	return id, true
+}
+
+func (p *YouTube) WatchURL(id string) string {
+	return "https://www.youtube.com/watch?v=" + id
+}
+
+func (p *YouTube) EmbedURL(id string) string {
+	return "https://www.youtube.com/embed/" + id
+}
+
+func (p *YouTube) OEmbedURL(watchURL string) string {
+	return oEmbedURL(p.OEmbedEndpoint, youTubeOEmbedEndpoint, watchURL)
+}
+
+// Vimeo recognises vimeo.com and player.vimeo.com links
+type Vimeo struct {
+	// OEmbedEndpoint overrides the public oEmbed endpoint, used for local fixtures
+	OEmbedEndpoint string
+}
+
+func (p *Vimeo) Name() string {
+	return VimeoProvider
+}
+
+func (p *Vimeo) ExtractID(u *url.URL) (string, bool) {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := pathSegments(u)
+
+	var id string
+	switch host {
+	case "player.vimeo.com":
+		if len(segments) >= 2 && segments[0] == "video" {
+			id = segments[1]
+		}
+	case "vimeo.com":
+		// vimeo.com/ID, vimeo.com/channels/name/ID, vimeo.com/groups/name/videos/ID
+		for i := len(segments) - 1; i >= 0; i-- {
+			if vimeoIDPattern.MatchString(segments[i]) {
+				id = segments[i]
+				break
+			}
+		}
+	}
+
+	if !vimeoIDPattern.MatchString(id) {
+		return "", false
+	}
+	return id, true
+}
+
+func (p *Vimeo) WatchURL(id string) string {
+	return "https://vimeo.com/" + id
+}
+
+func (p *Vimeo) EmbedURL(id string) string {
+	return "https://player.vimeo.com/video/" + id
+}
+
+func (p *Vimeo) OEmbedURL(watchURL string) string {
+	return oEmbedURL(p.OEmbedEndpoint, vimeoOEmbedEndpoint, watchURL)
+}
+
+func oEmbedURL(endpoint, fallback, watchURL string) string {
+	if endpoint == "" {
+		endpoint = fallback
+	}
+	return endpoint + "?format=json&url=" + url.QueryEscape(watchURL)
+}
+
+func pathSegments(u *url.URL) []string {
+	var segments []string
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
@@ -0,0 +1,164 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"itv-movie/internal/config"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidURL          = errors.New("invalid video URL")
+	ErrUnsupportedProvider = errors.New("unsupported video provider, only YouTube and Vimeo links are accepted")
+	ErrVideoNotFound       = errors.New("video not found on provider")
+)
+
+// HTTPClient is the subset of *http.Client used for oEmbed requests, it can be stubbed in tests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Provider recognises links of a single video platform
+type Provider interface {
+	Name() string
+	// ExtractID returns the video ID if the URL belongs to this provider
+	ExtractID(u *url.URL) (string, bool)
+	WatchURL(id string) string
+	EmbedURL(id string) string
+	OEmbedURL(watchURL string) string
+}
+
+// Video is a normalized reference to a hosted video
+type Video struct {
+	Provider string `json:"provider"`
+	ID       string `json:"id"`
+	WatchURL string `json:"watchUrl"`
+	EmbedURL string `json:"embedUrl"`
+}
+
+// OEmbed holds the oEmbed fields we care about
+type OEmbed struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	HTML         string `json:"html"`
+}
+
+// Resolver matches URLs against the registered providers
+type Resolver struct {
+	providers   []Provider
+	client      HTTPClient
+	fetchOEmbed bool
+}
+
+// NewResolver creates a resolver with the given client and providers
+func NewResolver(client HTTPClient, fetchOEmbed bool, providers ...Provider) *Resolver {
+	return &Resolver{
+		providers:   providers,
+		client:      client,
+		fetchOEmbed: fetchOEmbed,
+	}
+}
+
+// NewDefaultResolver creates a resolver for YouTube and Vimeo configured from config
+func NewDefaultResolver(cfg *config.Config) *Resolver {
+	timeout := time.Duration(cfg.Internal.Trailer.OEmbedTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return NewResolver(
+		&http.Client{Timeout: timeout},
+		cfg.Internal.Trailer.FetchOEmbed,
+		&YouTube{},
+		&Vimeo{},
+	)
+}
+
+// FetchOEmbedEnabled reports whether oEmbed lookups are switched on in config
+func (r *Resolver) FetchOEmbedEnabled() bool {
+	return r.fetchOEmbed
+}
+
+// Resolve validates the URL and returns the normalized video it points to
+func (r *Resolver) Resolve(rawURL string) (*Video, error) {
+	u, err := ParseHTTPURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, provider := range r.providers {
+		if id, ok := provider.ExtractID(u); ok {
+			return &Video{
+				Provider: provider.Name(),
+				ID:       id,
+				WatchURL: provider.WatchURL(id),
+				EmbedURL: provider.EmbedURL(id),
+			}, nil
+		}
+	}
+
+	return nil, ErrUnsupportedProvider
+}
+
+// FetchOEmbed loads oEmbed metadata for a resolved video
+func (r *Resolver) FetchOEmbed(ctx context.Context, v *Video) (*OEmbed, error) {
+	provider := r.provider(v.Provider)
+	if provider == nil {
+		return nil, ErrUnsupportedProvider
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.OEmbedURL(v.WatchURL), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oembed request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		// providers answer 401/403 for private videos and 404 for removed ones
+		return nil, ErrVideoNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("oembed request failed with status %d", resp.StatusCode)
+	}
+
+	var meta OEmbed
+	if err = json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to decode oembed response: %w", err)
+	}
+
+	return &meta, nil
+}
+
+func (r *Resolver) provider(name string) Provider {
+	for _, provider := range r.providers {
+		if provider.Name() == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+// ParseHTTPURL parses an absolute http(s) URL with a host
+func ParseHTTPURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	return u, nil
+}
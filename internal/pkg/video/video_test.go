@@ -0,0 +1,155 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	resolver := NewResolver(http.DefaultClient, false, &YouTube{}, &Vimeo{})
+
+	tests := []struct {
+		url      string
+		provider string
+		id       string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://youtube.com/watch?v=dQw4w9WgXcQ&t=42s&list=PL123", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"http://m.youtube.com/watch?v=dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://music.youtube.com/watch?v=dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ?si=share", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/embed/dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/v/dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"  https://WWW.YOUTUBE.COM/watch?v=dQw4w9WgXcQ  ", YouTubeProvider, "dQw4w9WgXcQ"},
+		{"https://vimeo.com/76979871", VimeoProvider, "76979871"},
+		{"https://www.vimeo.com/76979871#t=10s", VimeoProvider, "76979871"},
+		{"https://vimeo.com/channels/staffpicks/76979871", VimeoProvider, "76979871"},
+		{"https://vimeo.com/groups/shortfilms/videos/76979871", VimeoProvider, "76979871"},
+		{"https://player.vimeo.com/video/76979871", VimeoProvider, "76979871"},
+		{"https://player.vimeo.com/video/76979871?autoplay=1", VimeoProvider, "76979871"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			video, err := resolver.Resolve(tt.url)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if video.Provider != tt.provider || video.ID != tt.id {
+				t.Errorf("got %s/%s, want %s/%s", video.Provider, video.ID, tt.provider, tt.id)
+			}
+		})
+	}
+}
+
+func TestResolveNormalizesURLs(t *testing.T) {
+	resolver := NewResolver(http.DefaultClient, false, &YouTube{}, &Vimeo{})
+
+	youTube, err := resolver.Resolve("https://youtu.be/dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if youTube.WatchURL != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" || youTube.EmbedURL != "https://www.youtube.com/embed/dQw4w9WgXcQ" {
+		t.Errorf("wrong YouTube URLs: %+v", youTube)
+	}
+
+	vimeo, err := resolver.Resolve("https://vimeo.com/channels/staffpicks/76979871")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if vimeo.WatchURL != "https://vimeo.com/76979871" || vimeo.EmbedURL != "https://player.vimeo.com/video/76979871" {
+		t.Errorf("wrong Vimeo URLs: %+v", vimeo)
+	}
+}
+
+func TestResolveRejects(t *testing.T) {
+	resolver := NewResolver(http.DefaultClient, false, &YouTube{}, &Vimeo{})
+
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"", ErrInvalidURL},
+		{"not a url", ErrInvalidURL},
+		{"youtube.com/watch?v=dQw4w9WgXcQ", ErrInvalidURL},
+		{"ftp://youtube.com/watch?v=dQw4w9WgXcQ", ErrInvalidURL},
+		{"javascript:alert(1)", ErrInvalidURL},
+		{"https:///watch?v=dQw4w9WgXcQ", ErrInvalidURL},
+		{"https://example.com/watch?v=dQw4w9WgXcQ", ErrUnsupportedProvider},
+		{"https://youtube.com.evil.example/watch?v=dQw4w9WgXcQ", ErrUnsupportedProvider},
+		{"https://evilyoutube.com/watch?v=dQw4w9WgXcQ", ErrUnsupportedProvider},
+		{"https://notvimeo.com/76979871", ErrUnsupportedProvider},
+		{"https://www.youtube.com/watch?v=short", ErrUnsupportedProvider},
+		{"https://www.youtube.com/watch", ErrUnsupportedProvider},
+		{"https://www.youtube.com/channel/UC38IQsAvIsxxjztdMZQtwHA", ErrUnsupportedProvider},
+		{"https://youtu.be/", ErrUnsupportedProvider},
+		{"https://vimeo.com/staffpicks", ErrUnsupportedProvider},
+		{"https://player.vimeo.com/76979871", ErrUnsupportedProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if _, err := resolver.Resolve(tt.url); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchOEmbed(t *testing.T) {
+	var status int
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Query().Get("url")
+		if r.URL.Query().Get("format") != "json" {
+			t.Errorf("format = %q, want json", r.URL.Query().Get("format"))
+		}
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"type": "video", "title": "Trailer", "provider_name": "YouTube", "width": 200, "height": 113}`))
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(server.Client(), true, &YouTube{OEmbedEndpoint: server.URL}, &Vimeo{OEmbedEndpoint: server.URL})
+	video, err := resolver.Resolve("https://youtu.be/dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	status = http.StatusOK
+	meta, err := resolver.FetchOEmbed(context.Background(), video)
+	if err != nil {
+		t.Fatalf("FetchOEmbed: %v", err)
+	}
+	if meta.Title != "Trailer" || meta.ProviderName != "YouTube" || meta.Width != 200 {
+		t.Errorf("wrong metadata: %+v", meta)
+	}
+	if requested != video.WatchURL {
+		t.Errorf("oEmbed asked for %q, want the watch URL %q", requested, video.WatchURL)
+	}
+
+	for _, status = range []int{http.StatusNotFound, http.StatusUnauthorized, http.StatusForbidden} {
+		if _, err = resolver.FetchOEmbed(context.Background(), video); !errors.Is(err, ErrVideoNotFound) {
+			t.Errorf("status %d: got %v, want ErrVideoNotFound", status, err)
+		}
+	}
+
+	for _, status = range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		_, err = resolver.FetchOEmbed(context.Background(), video)
+		if err == nil || errors.Is(err, ErrVideoNotFound) {
+			t.Errorf("status %d: got %v, want a request error", status, err)
+		}
+	}
+
+	if _, err = resolver.FetchOEmbed(context.Background(), &Video{Provider: "dailymotion"}); !errors.Is(err, ErrUnsupportedProvider) {
+		t.Errorf("unknown provider: got %v, want ErrUnsupportedProvider", err)
+	}
+}
@@ -26,7 +26,8 @@ func MustLoadDB(cfg *config.Config, logger *slog.Logger) (*PostgresDB, error) {
 
 	var db *gorm.DB
 
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError turns unique violations into gorm.ErrDuplicatedKey, services answer them with 409
+	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err.Error())
 	}
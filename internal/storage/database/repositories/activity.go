@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+	"time"
+)
+
+// ActivityRepository handles the watch history, ratings and genre preferences of users
+type ActivityRepository struct {
+	db *gorm.DB
+}
+
+// NewActivityRepository creates a new activity repository
+func NewActivityRepository(postgres *database.PostgresDB) *ActivityRepository {
+	return &ActivityRepository{db: postgres.DB}
+}
+
+func (r *ActivityRepository) AddWatch(ctx context.Context, userID, movieID uuid.UUID, watchedAt time.Time) (*models.WatchHistory, error) {
+	entry := models.WatchHistory{UserID: userID, MovieID: movieID, WatchedAt: watchedAt}
+
+	if err := r.db.WithContext(ctx).Omit("Movie").Create(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// GetHistory returns the user's watch history, latest first
+func (r *ActivityRepository) GetHistory(ctx context.Context, userID uuid.UUID, page, limit int) ([]*models.WatchHistory, int64, error) {
+	var history []*models.WatchHistory
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&models.WatchHistory{}).
+		Where("user_id = ?", userID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.WithContext(ctx).
+		Preload("Movie").
+		Where("user_id = ?", userID).
+		Order("watched_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&history).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return history, total, nil
+}
+
+// RateMovie creates or replaces the user's rating of a movie
+func (r *ActivityRepository) RateMovie(ctx context.Context, userID, movieID uuid.UUID, score int) (*models.MovieRating, error) {
+	rating := models.MovieRating{UserID: userID, MovieID: movieID, Score: score}
+
+	if err := r.db.WithContext(ctx).Omit("Movie").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "movie_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"score", "updated_at"}),
+	}).Create(&rating).Error; err != nil {
+		return nil, err
+	}
+
+	return &rating, nil
+}
+
+func (r *ActivityRepository) DeleteRating(ctx context.Context, userID, movieID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND movie_id = ?", userID, movieID).
+		Delete(&models.MovieRating{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetRatings returns the user's ratings, latest first
+func (r *ActivityRepository) GetRatings(ctx context.Context, userID uuid.UUID, page, limit int) ([]*models.MovieRating, int64, error) {
+	var ratings []*models.MovieRating
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&models.MovieRating{}).
+		Where("user_id = ?", userID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.WithContext(ctx).
+		Preload("Movie").
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&ratings).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ratings, total, nil
+}
+
+func (r *ActivityRepository) GetPreferredGenres(ctx context.Context, userID uuid.UUID) ([]models.Genre, error) {
+	var genres []models.Genre
+
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN user_preferred_genres upg ON upg.genre_id = genres.id").
+		Where("upg.user_id = ?", userID).
+		Order("genres.name").
+		Find(&genres).Error; err != nil {
+		return nil, err
+	}
+
+	return genres, nil
+}
+
+// SetPreferredGenres replaces the user's preferred genres
+func (r *ActivityRepository) SetPreferredGenres(ctx context.Context, userID uuid.UUID, genreIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM user_preferred_genres WHERE user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		for _, genreID := range genreIDs {
+			if err := tx.Exec(
+				"INSERT INTO user_preferred_genres (user_id, genre_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+				userID, genreID,
+			).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+	"time"
+)
+
+// ApiKeyRepository stores the hashed API keys
+type ApiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewApiKeyRepository creates a new API key repository
+func NewApiKeyRepository(postgres *database.PostgresDB) *ApiKeyRepository {
+	return &ApiKeyRepository{db: postgres.DB}
+}
+
+func (r *ApiKeyRepository) Create(ctx context.Context, key *models.ApiKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetByHash returns the key with its owner
+func (r *ApiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.ApiKey, error) {
+	var key models.ApiKey
+	if err := r.db.WithContext(ctx).Preload("User").Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAll returns a page of keys, newest first, and the number of keys
+func (r *ApiKeyRepository) GetAll(ctx context.Context, page, limit int) ([]*models.ApiKey, int64, error) {
+	var keys []*models.ApiKey
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&models.ApiKey{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&keys).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return keys, total, nil
+}
+
+// Revoke revokes a key that is not revoked yet, reporting whether it did
+func (r *ApiKeyRepository) Revoke(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.ApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// Exists reports whether a key with the id exists
+func (r *ApiKeyRepository) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ApiKey{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// TouchLastUsed records a use of the key. It writes at most once per interval so busy keys do not
+// turn every request into an update
+func (r *ApiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, interval time.Duration) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&models.ApiKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		Update("last_used_at", now).Error
+}
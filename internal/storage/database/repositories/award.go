@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+)
+
+// AwardRepository handles database operations for awards, their categories and nominations
+type AwardRepository struct {
+	db *gorm.DB
+}
+
+// NewAwardRepository creates a new award repository
+func NewAwardRepository(postgres *database.PostgresDB) *AwardRepository {
+	return &AwardRepository{db: postgres.DB}
+}
+
+func (r *AwardRepository) Create(ctx context.Context, award *models.Award) (*models.Award, error) {
+	if err := r.db.WithContext(ctx).Omit("Categories").Create(award).Error; err != nil {
+		return nil, err
+	}
+	return award, nil
+}
+
+// GetAll returns every award with its categories, by name
+func (r *AwardRepository) GetAll(ctx context.Context) ([]*models.Award, error) {
+	var awards []*models.Award
+
+	if err := r.db.WithContext(ctx).
+		Preload("Categories", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name ASC")
+		}).
+		Order("name").
+		Find(&awards).Error; err != nil {
+		return nil, err
+	}
+
+	return awards, nil
+}
+
+func (r *AwardRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Award, error) {
+	var award models.Award
+
+	if err := r.db.WithContext(ctx).
+		Preload("Categories", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name ASC")
+		}).
+		Where("id = ?", id).
+		First(&award).Error; err != nil {
+		return nil, err
+	}
+
+	return &award, nil
+}
+
+func (r *AwardRepository) Update(ctx context.Context, award *models.Award) (*models.Award, error) {
+	if err := r.db.WithContext(ctx).Model(award).Updates(map[string]interface{}{
+		"name":        award.Name,
+		"description": award.Description,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, award.ID)
+}
+
+func (r *AwardRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Award{}, id).Error
+}
+
+// CountCategories counts the categories of an award
+func (r *AwardRepository) CountCategories(ctx context.Context, awardID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.AwardCategory{}).Where("award_id = ?", awardID).Count(&count).Error
+	return count, err
+}
+
+func (r *AwardRepository) CreateCategory(ctx context.Context, category *models.AwardCategory) (*models.AwardCategory, error) {
+	if err := r.db.WithContext(ctx).Omit("Award").Create(category).Error; err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (r *AwardRepository) GetCategory(ctx context.Context, id uuid.UUID) (*models.AwardCategory, error) {
+	var category models.AwardCategory
+
+	if err := r.db.WithContext(ctx).Preload("Award").Where("id = ?", id).First(&category).Error; err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (r *AwardRepository) UpdateCategory(ctx context.Context, category *models.AwardCategory) (*models.AwardCategory, error) {
+	if err := r.db.WithContext(ctx).Model(category).Update("name", category.Name).Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetCategory(ctx, category.ID)
+}
+
+func (r *AwardRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.AwardCategory{}, id).Error
+}
+
+// CountNominations counts the nominations in a category
+func (r *AwardRepository) CountNominations(ctx context.Context, categoryID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Nomination{}).Where("category_id = ?", categoryID).Count(&count).Error
+	return count, err
+}
+
+func (r *AwardRepository) CreateNomination(ctx context.Context, nomination *models.Nomination) (*models.Nomination, error) {
+	if err := r.db.WithContext(ctx).Omit("Movie", "Category").Create(nomination).Error; err != nil {
+		return nil, err
+	}
+	return r.GetNomination(ctx, nomination.ID)
+}
+
+func (r *AwardRepository) GetNomination(ctx context.Context, id uuid.UUID) (*models.Nomination, error) {
+	var nomination models.Nomination
+
+	if err := r.db.WithContext(ctx).
+		Preload("Category").
+		Preload("Category.Award").
+		Where("id = ?", id).
+		First(&nomination).Error; err != nil {
+		return nil, err
+	}
+
+	return &nomination, nil
+}
+
+// GetMovieNominations returns the nominations of a movie, latest ceremony first
+func (r *AwardRepository) GetMovieNominations(ctx context.Context, movieID uuid.UUID) ([]*models.Nomination, error) {
+	var nominations []*models.Nomination
+
+	if err := r.db.WithContext(ctx).
+		Preload("Category").
+		Preload("Category.Award").
+		Where("movie_id = ?", movieID).
+		Order("year DESC, won DESC, created_at").
+		Find(&nominations).Error; err != nil {
+		return nil, err
+	}
+
+	return nominations, nil
+}
+
+func (r *AwardRepository) UpdateNomination(ctx context.Context, nomination *models.Nomination) (*models.Nomination, error) {
+	if err := r.db.WithContext(ctx).Model(nomination).Updates(map[string]interface{}{
+		"category_id": nomination.CategoryID,
+		"year":        nomination.Year,
+		"person":      nomination.Person,
+		"won":         nomination.Won,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetNomination(ctx, nomination.ID)
+}
+
+func (r *AwardRepository) DeleteNomination(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Nomination{}, id).Error
+}
+
+// Summary counts the wins and nominations of a movie per award, the most decorated first
+func (r *AwardRepository) Summary(ctx context.Context, movieID uuid.UUID) ([]models.AwardSummary, error) {
+	var summary []models.AwardSummary
+
+	if err := r.db.WithContext(ctx).
+		Table("nominations n").
+		Select("a.id AS award_id, a.name AS award, COUNT(*) FILTER (WHERE n.won) AS wins, COUNT(*) AS nominations").
+		Joins("JOIN award_categories ac ON ac.id = n.category_id").
+		Joins("JOIN awards a ON a.id = ac.award_id").
+		Where("n.movie_id = ?", movieID).
+		Group("a.id, a.name").
+		Order("wins DESC, nominations DESC, a.name").
+		Scan(&summary).Error; err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
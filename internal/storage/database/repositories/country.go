@@ -75,3 +75,13 @@ func (r *CountryRepository) GetByCode(ctx context.Context, code string) (*models
 
 	return &country, nil
 }
+
+func (r *CountryRepository) GetBySlug(ctx context.Context, slug string) (*models.Country, error) {
+	var country models.Country
+
+	if err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&country).Error; err != nil {
+		return nil, err
+	}
+
+	return &country, nil
+}
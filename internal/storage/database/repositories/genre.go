@@ -77,3 +77,84 @@ func (r *GenreRepository) GetByName(ctx context.Context, name string) (*models.G
 
 	return &genre, nil
 }
+
+func (r *GenreRepository) GetBySlug(ctx context.Context, slug string) (*models.Genre, error) {
+	var genre models.Genre
+
+	if err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&genre).Error; err != nil {
+		return nil, err
+	}
+
+	return &genre, nil
+}
+
+// genreSubtree selects the IDs of a genre and all of its descendants
+const genreSubtree = `WITH RECURSIVE tree AS (
+		SELECT id FROM genres WHERE id = ?
+		UNION
+		SELECT g.id FROM genres g JOIN tree ON g.parent_id = tree.id WHERE g.deleted_at IS NULL
+	) SELECT id FROM tree`
+
+// GetAllOrdered returns every genre by name, for building the tree
+func (r *GenreRepository) GetAllOrdered(ctx context.Context) ([]*models.Genre, error) {
+	var genres []*models.Genre
+
+	if err := r.db.WithContext(ctx).Order("name").Find(&genres).Error; err != nil {
+		return nil, err
+	}
+
+	return genres, nil
+}
+
+// InSubtree reports whether candidate is the genre itself or one of its descendants
+func (r *GenreRepository) InSubtree(ctx context.Context, id, candidate uuid.UUID) (bool, error) {
+	var found bool
+
+	if err := r.db.WithContext(ctx).
+		Raw("SELECT EXISTS (SELECT 1 FROM ("+genreSubtree+") t WHERE t.id = ?)", id, candidate).
+		Scan(&found).Error; err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// CountUsage counts the direct children of a genre and the movies attached to it
+func (r *GenreRepository) CountUsage(ctx context.Context, id uuid.UUID) (children, movies int64, err error) {
+	if err = r.db.WithContext(ctx).Model(&models.Genre{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		return 0, 0, err
+	}
+
+	if err = r.db.WithContext(ctx).Table("movie_genres").
+		Joins("JOIN movies ON movies.id = movie_genres.movie_id AND movies.deleted_at IS NULL").
+		Where("movie_genres.genre_id = ?", id).
+		Count(&movies).Error; err != nil {
+		return 0, 0, err
+	}
+
+	return children, movies, nil
+}
+
+// DeleteAndReassign moves the children, movies and user preferences of a genre to target, then deletes it
+func (r *GenreRepository) DeleteAndReassign(ctx context.Context, id, target uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Genre{}).Where("parent_id = ?", id).Update("parent_id", target).Error; err != nil {
+			return err
+		}
+
+		for _, link := range []struct{ table, owner string }{
+			{"movie_genres", "movie_id"},
+			{"user_preferred_genres", "user_id"},
+		} {
+			if err := tx.Exec("INSERT INTO "+link.table+" ("+link.owner+", genre_id) "+
+				"SELECT "+link.owner+", ? FROM "+link.table+" WHERE genre_id = ? ON CONFLICT DO NOTHING", target, id).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM "+link.table+" WHERE genre_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&models.Genre{}, id).Error
+	})
+}
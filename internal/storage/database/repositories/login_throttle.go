@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+	"time"
+)
+
+// LoginThrottleRepository keeps the failed login counters in Postgres so every replica sees the same lockouts
+type LoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginThrottleRepository creates a new login throttle repository
+func NewLoginThrottleRepository(postgres *database.PostgresDB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: postgres.DB}
+}
+
+// BlockedUntil returns the latest time until which the username or the IP address is blocked, nil when neither is
+func (r *LoginThrottleRepository) BlockedUntil(ctx context.Context, username, ipAddress string, now time.Time) (*time.Time, error) {
+	var until *time.Time
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT MAX(blocked_until)
+		FROM login_throttles
+		WHERE ((scope = ? AND subject = ?) OR (scope = ? AND subject = ?))
+		  AND blocked_until > ?`,
+		models.UsernameThrottle, username, models.IPThrottle, ipAddress, now,
+	).Scan(&until).Error
+	return until, err
+}
+
+// RecordFailure counts a failed login and returns the number of failures in a row,
+// the count restarts when the previous failure happened before windowStart
+func (r *LoginThrottleRepository) RecordFailure(ctx context.Context, scope, subject string, now, windowStart time.Time) (int, error) {
+	var failures int
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO login_throttles (id, scope, subject, failures, last_failure_at, created_at, updated_at)
+		VALUES (?, ?, ?, 1, ?, ?, ?)
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING failures`,
+		uuid.New(), scope, subject, now, now, now, windowStart,
+	).Scan(&failures).Error
+	return failures, err
+}
+
+// Block refuses logins of the username or IP address until the given time
+func (r *LoginThrottleRepository) Block(ctx context.Context, scope, subject string, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.LoginThrottle{}).
+		Where("scope = ? AND subject = ?", scope, subject).
+		Update("blocked_until", until).Error
+}
+
+// Reset forgets the failures of the username or IP address and lifts its block
+func (r *LoginThrottleRepository) Reset(ctx context.Context, scope, subject string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("scope = ? AND subject = ?", scope, subject).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
+
+// GetBlocked returns a page of the usernames and IP addresses blocked at now, longest block first
+func (r *LoginThrottleRepository) GetBlocked(ctx context.Context, now time.Time, page, limit int) ([]*models.LoginThrottle, int64, error) {
+	var throttles []*models.LoginThrottle
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&models.LoginThrottle{}).
+		Where("blocked_until > ?", now).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.WithContext(ctx).
+		Where("blocked_until > ?", now).
+		Order("blocked_until DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&throttles).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return throttles, total, nil
+}
+
+// DeleteStale removes counters whose last failure is older than before and that no longer block
+func (r *LoginThrottleRepository) DeleteStale(ctx context.Context, before, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("last_failure_at < ? AND (blocked_until IS NULL OR blocked_until <= ?)", before, now).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
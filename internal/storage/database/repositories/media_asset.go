@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+)
+
+// MediaAssetRepository handles database operations for uploaded movie media
+type MediaAssetRepository struct {
+	db *gorm.DB
+}
+
+// NewMediaAssetRepository creates a new media asset repository
+func NewMediaAssetRepository(postgres *database.PostgresDB) *MediaAssetRepository {
+	return &MediaAssetRepository{db: postgres.DB}
+}
+
+func (r *MediaAssetRepository) Create(ctx context.Context, asset *models.MediaAsset) (*models.MediaAsset, error) {
+	if err := r.db.WithContext(ctx).Create(asset).Error; err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+func (r *MediaAssetRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MediaAsset, error) {
+	var asset models.MediaAsset
+
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&asset).Error; err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+func (r *MediaAssetRepository) GetByMovieID(ctx context.Context, movieID uuid.UUID) ([]*models.MediaAsset, error) {
+	var assets []*models.MediaAsset
+
+	if err := r.db.WithContext(ctx).
+		Where("movie_id = ?", movieID).
+		Order("created_at ASC").
+		Find(&assets).Error; err != nil {
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+func (r *MediaAssetRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.MediaAsset{}, id).Error
+}
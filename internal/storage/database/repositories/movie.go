@@ -2,12 +2,15 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"itv-movie/internal/models"
 	"itv-movie/internal/storage/database"
 	"strings"
+	"time"
 )
 
 // MovieRepository handles database operations for movies
@@ -15,59 +18,119 @@ type MovieRepository struct {
 	db *gorm.DB
 }
 
-// NewMovieRepository creates a new movie repository
-func NewMovieRepository(postgres *database.PostgresDB) *MovieRepository {
-	return &MovieRepository{
-		db: postgres.DB,
-	}
+// MovieFilter holds the optional list endpoint filters, empty fields are ignored
+type MovieFilter struct {
+	Query            string
+	AudioLanguage    string     // language code, e.g. "ru"
+	SubtitleLanguage string     // language code, e.g. "uz"
+	GenreID          uuid.UUID  // includes the descendants of the genre
+	Tags             []string   // normalized tag names
+	AllTags          bool       // match movies carrying all Tags instead of any of them
+	Awards           string     // WonAwards or NominatedAwards
+	AwardID          uuid.UUID  // narrows Awards to one award
+	ReleasedIn       string     // country code, the release date bounds then apply to its releases
+	ReleasedAfter    *time.Time // inclusive
+	ReleasedBefore   *time.Time // inclusive
+	Sort             string     // TrendingSort or PopularSort, empty keeps the default order
+	Region           string     // country code whose trending/popularity scores Sort uses, empty for worldwide
+	Drafts           bool       // include unpublished movies, does not count as a filter in IsEmpty
 }
 
-func (r *MovieRepository) Create(ctx context.Context, movie *models.Movie) (*models.Movie, error) {
-	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(movie).Error; err != nil {
-			return err
-		}
+// inGenreTree matches movies of a genre or any of its descendants
+const inGenreTree = "EXISTS (SELECT 1 FROM movie_genres mg WHERE mg.movie_id = movies.id AND mg.genre_id IN (" + genreSubtree + "))"
 
-		if len(movie.Genres) > 0 {
-			// Build values string for PostgreSQL batch insert
-			var genreValueStrings []string
-			var genreValueArgs []interface{}
+// Award filters
+const (
+	WonAwards       = "won"
+	NominatedAwards = "nominated" // won or not
+)
 
-			for i, genre := range movie.Genres {
-				genreValueStrings = append(genreValueStrings, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
-				genreValueArgs = append(genreValueArgs, movie.ID, genre.ID)
-			}
+// List orders backed by the movie_popularity scores
+const (
+	TrendingSort = "trending"
+	PopularSort  = "popular"
+)
 
-			genreQuery := fmt.Sprintf(
-				"INSERT INTO movie_genres (movie_id, genre_id) VALUES %s ON CONFLICT DO NOTHING",
-				strings.Join(genreValueStrings, ","),
-			)
+// IsEmpty reports whether no filter is set
+func (f MovieFilter) IsEmpty() bool {
+	return f.Query == "" && f.AudioLanguage == "" && f.SubtitleLanguage == "" && f.GenreID == uuid.Nil &&
+		len(f.Tags) == 0 && f.Awards == "" && f.ReleasedIn == "" && f.ReleasedAfter == nil && f.ReleasedBefore == nil &&
+		f.Sort == "" && f.Region == ""
+}
 
-			if err := tx.Exec(genreQuery, genreValueArgs...).Error; err != nil {
-				return err
-			}
-		}
+// DuplicateCandidate is a pair of movies with a similar normalized title
+type DuplicateCandidate struct {
+	FirstID        uuid.UUID
+	FirstTitle     string
+	FirstYear      int
+	FirstDirector  string
+	SecondID       uuid.UUID
+	SecondTitle    string
+	SecondYear     int
+	SecondDirector string
+}
 
-		if len(movie.Countries) > 0 {
-			var countryValueStrings []string
-			var countryValueArgs []interface{}
+// FeedStats describes the movies listed in a sitemap or feed
+type FeedStats struct {
+	Count        int64
+	LastModified time.Time // latest update or deletion
+}
 
-			for i, country := range movie.Countries {
-				countryValueStrings = append(countryValueStrings, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
-				countryValueArgs = append(countryValueArgs, movie.ID, country.ID)
-			}
+// SitemapEntry is the part of a movie a sitemap needs
+type SitemapEntry struct {
+	ID        uuid.UUID
+	Slug      string
+	UpdatedAt time.Time
+}
 
-			countryQuery := fmt.Sprintf(
-				"INSERT INTO movie_countries (movie_id, country_id) VALUES %s ON CONFLICT DO NOTHING",
-				strings.Join(countryValueStrings, ","),
-			)
+// SimilarityWeights weigh the components of the similar movies score, every component is in [0, 1]
+type SimilarityWeights struct {
+	Genres     float64
+	Countries  float64
+	Credits    float64
+	Language   float64
+	Year       float64
+	YearWindow int // years apart at which year proximity reaches 0
+}
 
-			if err := tx.Exec(countryQuery, countryValueArgs...).Error; err != nil {
-				return err
-			}
+// SimilarScore is a movie scored by FindSimilar
+type SimilarScore struct {
+	ID    uuid.UUID
+	Score float64
+}
+
+// movieLinkTables are the many2many tables keyed by movie_id, moved on merge
+var movieLinkTables = []struct{ table, column string }{
+	{"movie_genres", "genre_id"},
+	{"movie_tags", "tag_id"},
+	{"movie_countries", "country_id"},
+	{"movie_audio_languages", "language_id"},
+	{"movie_subtitle_languages", "language_id"},
+}
+
+// movieOwnedTables are the tables whose rows belong to a single movie, re-pointed on merge
+var movieOwnedTables = []string{
+	"alternate_titles",
+	"media_assets",
+	"movie_credits",
+	"watch_histories",
+	"movie_events",
+}
+
+// NewMovieRepository creates a new movie repository
+func NewMovieRepository(postgres *database.PostgresDB) *MovieRepository {
+	return &MovieRepository{
+		db: postgres.DB,
+	}
+}
+
+func (r *MovieRepository) Create(ctx context.Context, movie *models.Movie) (*models.Movie, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(movie).Error; err != nil {
+			return err
 		}
 
-		return nil
+		return r.saveRelations(tx, movie)
 	})
 
 	if err != nil {
@@ -80,10 +143,7 @@ func (r *MovieRepository) Create(ctx context.Context, movie *models.Movie) (*mod
 func (r *MovieRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Movie, error) {
 	var movie models.Movie
 
-	if err := r.db.WithContext(ctx).
-		Preload("Language").
-		Preload("Countries").
-		Preload("Genres").
+	if err := r.preload(r.db.WithContext(ctx)).
 		Where("id = ?", id).
 		First(&movie).Error; err != nil {
 		return nil, err
@@ -92,14 +152,37 @@ func (r *MovieRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Mo
 	return &movie, nil
 }
 
-func (r *MovieRepository) GetAll(ctx context.Context, page, limit int) ([]*models.Movie, error) {
+func (r *MovieRepository) GetBySlug(ctx context.Context, slug string) (*models.Movie, error) {
+	var movie models.Movie
+
+	if err := r.preload(r.db.WithContext(ctx)).
+		Where("slug = ?", slug).
+		First(&movie).Error; err != nil {
+		return nil, err
+	}
+
+	return &movie, nil
+}
+
+// GetByIDs loads the movies with the given IDs, in no particular order
+func (r *MovieRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Movie, error) {
+	var movies []*models.Movie
+
+	if err := r.preload(r.db.WithContext(ctx)).
+		Where("id IN ?", ids).
+		Find(&movies).Error; err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+// GetAll returns a page of movies, unpublished ones only when drafts is set
+func (r *MovieRepository) GetAll(ctx context.Context, page, limit int, drafts bool) ([]*models.Movie, error) {
 	var movies []*models.Movie
 	offset := (page - 1) * limit
 
-	if err := r.db.WithContext(ctx).
-		Preload("Language").
-		Preload("Countries").
-		Preload("Genres").
+	if err := r.published(r.preload(r.db.WithContext(ctx)), drafts).
 		Offset(offset).
 		Limit(limit).
 		Find(&movies).Error; err != nil {
@@ -113,106 +196,464 @@ func (r *MovieRepository) Update(ctx context.Context, movie *models.Movie) (*mod
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Update the movie's basic fields
 		if err := tx.Model(movie).Updates(map[string]interface{}{
-			"title":        movie.Title,
-			"director":     movie.Director,
-			"year":         movie.Year,
-			"plot":         movie.Plot,
-			"runtime":      movie.Runtime,
-			"rating":       movie.Rating,
-			"poster_url":   movie.PosterURL,
-			"trailer_url":  movie.TrailerURL,
-			"release_date": movie.ReleaseDate,
-			"language":     movie.LanguageID, // Using "language" for the column name
+			"title":            movie.Title,
+			"slug":             movie.Slug,
+			"published_at":     movie.PublishedAt,
+			"director":         movie.Director,
+			"year":             movie.Year,
+			"plot":             movie.Plot,
+			"runtime":          movie.Runtime,
+			"rating":           movie.Rating,
+			"poster_url":       movie.PosterURL,
+			"trailer_url":      movie.TrailerURL,
+			"trailer_provider": movie.TrailerProvider,
+			"trailer_video_id": movie.TrailerVideoID,
+			"imdb_id":          movie.ImdbID,
+			"tmdb_id":          movie.TmdbID,
+			"release_date":     movie.ReleaseDate,
+			"language":         movie.LanguageID, // Using "language" for the column name
 		}).Error; err != nil {
 			return err
 		}
 
-		// Clear existing genre relationships
-		if err := tx.Exec("DELETE FROM movie_genres WHERE movie_id = ?", movie.ID).Error; err != nil {
+		// Clear existing relationships, they are re-created from the movie below
+		for _, table := range []string{"movie_genres", "movie_tags", "movie_countries", "movie_audio_languages", "movie_subtitle_languages", "alternate_titles", "release_dates"} {
+			if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE movie_id = ?", table), movie.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		return r.saveRelations(tx, movie)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, movie.ID)
+}
+
+func (r *MovieRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Movie{}, id).Error
+}
+
+// ExternalIDTaken reports whether a movie other than excludeID uses the IMDb ID or the TMDB ID,
+// nil values are skipped. Each ID is checked on its own so the excluded movie holding one of them
+// cannot hide another movie holding the other
+func (r *MovieRepository) ExternalIDTaken(ctx context.Context, imdbID *string, tmdbID *int64, excludeID uuid.UUID) (bool, error) {
+	if imdbID != nil {
+		if taken, err := r.externalIDTaken(ctx, "imdb_id", *imdbID, excludeID); err != nil || taken {
+			return taken, err
+		}
+	}
+	if tmdbID != nil {
+		return r.externalIDTaken(ctx, "tmdb_id", *tmdbID, excludeID)
+	}
+	return false, nil
+}
+
+func (r *MovieRepository) externalIDTaken(ctx context.Context, column string, value interface{}, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	// unscoped, the unique indexes also cover soft deleted movies
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Movie{}).
+		Where(column+" = ? AND id <> ?", value, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ReplaceCredits swaps all credits of the movie with the given list
+func (r *MovieRepository) ReplaceCredits(ctx context.Context, movieID uuid.UUID, credits []models.MovieCredit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("movie_id = ?", movieID).Delete(&models.MovieCredit{}).Error; err != nil {
 			return err
 		}
 
-		// Add new genre relationships if provided
-		if len(movie.Genres) > 0 {
-			var genreValueStrings []string
-			var genreValueArgs []interface{}
+		if len(credits) == 0 {
+			return nil
+		}
 
-			for i, genre := range movie.Genres {
-				genreValueStrings = append(genreValueStrings, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
-				genreValueArgs = append(genreValueArgs, movie.ID, genre.ID)
-			}
+		for i := range credits {
+			credits[i].ID = uuid.Nil
+			credits[i].MovieID = movieID
+		}
 
-			genreQuery := fmt.Sprintf(
-				"INSERT INTO movie_genres (movie_id, genre_id) VALUES %s ON CONFLICT DO NOTHING",
-				strings.Join(genreValueStrings, ","),
-			)
+		return tx.Create(&credits).Error
+	})
+}
+
+// UpdatePosterURL sets only the poster_url column of the movie
+func (r *MovieRepository) UpdatePosterURL(ctx context.Context, id uuid.UUID, posterURL string) error {
+	return r.db.WithContext(ctx).Model(&models.Movie{}).
+		Where("id = ?", id).
+		Update("poster_url", posterURL).Error
+}
+
+// FindDuplicateCandidates returns pairs of movies whose normalized titles match, or share a prefix within a year
+func (r *MovieRepository) FindDuplicateCandidates(ctx context.Context, limit int) ([]DuplicateCandidate, error) {
+	var candidates []DuplicateCandidate
+
+	err := r.db.WithContext(ctx).Raw(`
+		WITH m AS (
+			SELECT id, title, COALESCE(year, 0) AS year, COALESCE(director, '') AS director,
+				regexp_replace(
+					regexp_replace(lower(title), '\s*[(\[][0-9]{4}[)\]]\s*$', ''),
+					'[^[:alnum:]]+', '', 'g'
+				) AS norm
+			FROM movies
+			WHERE deleted_at IS NULL
+		)
+		SELECT a.id AS first_id, a.title AS first_title, a.year AS first_year, a.director AS first_director,
+			b.id AS second_id, b.title AS second_title, b.year AS second_year, b.director AS second_director
+		FROM m a
+		JOIN m b ON a.id < b.id
+			AND (a.norm = b.norm OR (left(a.norm, 4) = left(b.norm, 4) AND abs(a.year - b.year) <= 1))
+		ORDER BY a.norm
+		LIMIT ?`, limit).
+		Scan(&candidates).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
 
-			if err := tx.Exec(genreQuery, genreValueArgs...).Error; err != nil {
+// Merge moves every relation of the duplicates onto the survivor, soft deletes
+// the duplicates and leaves redirects so their IDs keep resolving
+func (r *MovieRepository) Merge(ctx context.Context, survivorID uuid.UUID, duplicateIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var survivor models.Movie
+		if err := tx.Where("id = ?", survivorID).First(&survivor).Error; err != nil {
+			return err
+		}
+
+		var duplicates []models.Movie
+		if err := tx.Where("id IN ?", duplicateIDs).Find(&duplicates).Error; err != nil {
+			return err
+		}
+		if len(duplicates) != len(duplicateIDs) {
+			return gorm.ErrRecordNotFound
+		}
+
+		for _, link := range movieLinkTables {
+			if err := tx.Exec(fmt.Sprintf(
+				"INSERT INTO %[1]s (movie_id, %[2]s) SELECT ?, %[2]s FROM %[1]s WHERE movie_id IN ? ON CONFLICT DO NOTHING",
+				link.table, link.column,
+			), survivorID, duplicateIDs).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE movie_id IN ?", link.table), duplicateIDs).Error; err != nil {
 				return err
 			}
 		}
 
-		// Clear existing country relationships
-		if err := tx.Exec("DELETE FROM movie_countries WHERE movie_id = ?", movie.ID).Error; err != nil {
+		// ratings are unique per user, keep the survivor's rating or else the latest one of the duplicates
+		if err := tx.Exec(`
+			DELETE FROM movie_ratings d USING movie_ratings k
+			WHERE d.movie_id IN ? AND k.user_id = d.user_id AND k.id <> d.id
+				AND (k.movie_id = ? OR (k.movie_id IN ? AND (k.updated_at, k.id) > (d.updated_at, d.id)))`,
+			duplicateIDs, survivorID, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE movie_ratings SET movie_id = ? WHERE movie_id IN ?", survivorID, duplicateIDs).Error; err != nil {
+			return err
+		}
+		// the same nomination may be recorded on several of the movies. A win on any copy carries over
+		// to all of them first, then the survivor's copy or else the oldest one is kept
+		if err := tx.Exec(`
+			UPDATE nominations k SET won = TRUE
+			WHERE (k.movie_id = ? OR k.movie_id IN ?) AND NOT k.won AND EXISTS (
+				SELECT 1 FROM nominations d
+				WHERE d.movie_id IN ? AND d.won AND d.category_id = k.category_id AND d.year = k.year AND d.person = k.person)`,
+			survivorID, duplicateIDs, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			DELETE FROM nominations d USING nominations k
+			WHERE d.movie_id IN ? AND k.category_id = d.category_id AND k.year = d.year AND k.person = d.person AND k.id <> d.id
+				AND (k.movie_id = ? OR (k.movie_id IN ? AND (k.created_at, k.id) < (d.created_at, d.id)))`,
+			duplicateIDs, survivorID, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE nominations SET movie_id = ? WHERE movie_id IN ?", survivorID, duplicateIDs).Error; err != nil {
+			return err
+		}
+		// one release per country and type, keep the survivor's or else the earliest
+		if err := tx.Exec(`
+			DELETE FROM release_dates d USING release_dates k
+			WHERE d.movie_id IN ? AND k.country_id = d.country_id AND k.type = d.type AND k.id <> d.id
+				AND (k.movie_id = ? OR (k.movie_id IN ? AND (k.date, k.id) < (d.date, d.id)))`,
+			duplicateIDs, survivorID, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE release_dates SET movie_id = ? WHERE movie_id IN ?", survivorID, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if err := syncReleaseDate(tx, survivorID); err != nil {
+			return err
+		}
+		// the co-occurrences and scores of the duplicates are rebuilt for the survivor by the next recomputation
+		if err := tx.Exec("DELETE FROM movie_cooccurrences WHERE movie_id IN ? OR related_id IN ?", duplicateIDs, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM movie_popularity WHERE movie_id IN ?", duplicateIDs).Error; err != nil {
 			return err
 		}
 
-		// Add new country relationships if provided
-		if len(movie.Countries) > 0 {
-			var countryValueStrings []string
-			var countryValueArgs []interface{}
+		for _, table := range movieOwnedTables {
+			if err := tx.Exec(fmt.Sprintf("UPDATE %s SET movie_id = ? WHERE movie_id IN ?", table), survivorID, duplicateIDs).Error; err != nil {
+				return err
+			}
+		}
 
-			for i, country := range movie.Countries {
-				countryValueStrings = append(countryValueStrings, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
-				countryValueArgs = append(countryValueArgs, movie.ID, country.ID)
+		// keep the duplicate titles searchable and take over missing external IDs
+		externalIDs := map[string]interface{}{}
+		for _, duplicate := range duplicates {
+			if duplicate.Title != survivor.Title {
+				title := models.AlternateTitle{MovieID: survivorID, Title: duplicate.Title, Type: models.AlternateTitleType}
+				if err := tx.Omit("Country").Create(&title).Error; err != nil {
+					return err
+				}
 			}
+			if survivor.ImdbID == nil && duplicate.ImdbID != nil {
+				survivor.ImdbID = duplicate.ImdbID
+				externalIDs["imdb_id"] = *duplicate.ImdbID
+			}
+			if survivor.TmdbID == nil && duplicate.TmdbID != nil {
+				survivor.TmdbID = duplicate.TmdbID
+				externalIDs["tmdb_id"] = *duplicate.TmdbID
+			}
+		}
 
-			countryQuery := fmt.Sprintf(
-				"INSERT INTO movie_countries (movie_id, country_id) VALUES %s ON CONFLICT DO NOTHING",
-				strings.Join(countryValueStrings, ","),
-			)
+		// old slugs of the duplicates keep resolving, now to the survivor
+		if err := tx.Model(&models.SlugHistory{}).
+			Where("entity_type = ? AND entity_id IN ?", models.MovieSlugEntity, duplicateIDs).
+			Update("entity_id", survivorID).Error; err != nil {
+			return err
+		}
+		for _, duplicate := range duplicates {
+			if duplicate.Slug == "" {
+				continue
+			}
+			// the slug may already be in the history of a movie merged earlier, re-point it like AddHistory does
+			history := models.SlugHistory{EntityType: models.MovieSlugEntity, EntityID: survivorID, Slug: duplicate.Slug}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "entity_type"}, {Name: "slug"}},
+				DoUpdates: clause.AssignmentColumns([]string{"entity_id"}),
+			}).Create(&history).Error; err != nil {
+				return err
+			}
+		}
 
-			if err := tx.Exec(countryQuery, countryValueArgs...).Error; err != nil {
+		// external IDs and slugs are unique, so release them on the duplicates before moving them
+		if err := tx.Model(&models.Movie{}).Where("id IN ?", duplicateIDs).
+			Updates(map[string]interface{}{"imdb_id": nil, "tmdb_id": nil, "slug": nil}).Error; err != nil {
+			return err
+		}
+		if len(externalIDs) > 0 {
+			if err := tx.Model(&models.Movie{}).Where("id = ?", survivorID).Updates(externalIDs).Error; err != nil {
 				return err
 			}
 		}
 
-		return nil
+		// flatten redirect chains that pointed at a merged movie
+		if err := tx.Model(&models.MovieRedirect{}).Where("to_id IN ?", duplicateIDs).
+			Update("to_id", survivorID).Error; err != nil {
+			return err
+		}
+
+		redirects := make([]models.MovieRedirect, 0, len(duplicateIDs))
+		for _, id := range duplicateIDs {
+			redirects = append(redirects, models.MovieRedirect{FromID: id, ToID: survivorID})
+		}
+		if err := tx.Omit("To").Create(&redirects).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Movie{}, duplicateIDs).Error
 	})
+}
+
+// GetRedirect returns the ID a merged movie now resolves to
+func (r *MovieRepository) GetRedirect(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	var redirect models.MovieRedirect
+
+	if err := r.db.WithContext(ctx).Where("from_id = ?", id).First(&redirect).Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	return redirect.ToID, nil
+}
+
+// FindSimilar scores the published movies that share a genre, country, credit or director with the movie
+// and returns the best limit of them. Language and year proximity only add to the score of those candidates
+func (r *MovieRepository) FindSimilar(ctx context.Context, id uuid.UUID, weights SimilarityWeights, limit int) ([]SimilarScore, error) {
+	var scores []SimilarScore
+
+	err := r.db.WithContext(ctx).Raw(`
+		WITH target AS (
+			SELECT id, language, COALESCE(year, 0) AS year, lower(trim(COALESCE(director, ''))) AS director
+			FROM movies WHERE id = @id
+		),
+		target_genres AS (SELECT genre_id FROM movie_genres WHERE movie_id = @id),
+		target_countries AS (SELECT country_id FROM movie_countries WHERE movie_id = @id),
+		target_credits AS (SELECT DISTINCT lower(name) AS name FROM movie_credits WHERE movie_id = @id),
+		g AS (
+			SELECT movie_id, COUNT(*)::float / GREATEST((SELECT COUNT(*) FROM target_genres), 1) AS share
+			FROM movie_genres
+			WHERE genre_id IN (SELECT genre_id FROM target_genres) AND movie_id <> @id
+			GROUP BY movie_id
+		),
+		c AS (
+			SELECT movie_id, COUNT(*)::float / GREATEST((SELECT COUNT(*) FROM target_countries), 1) AS share
+			FROM movie_countries
+			WHERE country_id IN (SELECT country_id FROM target_countries) AND movie_id <> @id
+			GROUP BY movie_id
+		),
+		p AS (
+			SELECT movie_id, LEAST(COUNT(DISTINCT lower(name)), 3)::float / 3 AS share
+			FROM movie_credits
+			WHERE lower(name) IN (SELECT name FROM target_credits) AND movie_id <> @id
+			GROUP BY movie_id
+		),
+		d AS (
+			SELECT m.id AS movie_id
+			FROM movies m
+			JOIN target t ON t.director <> '' AND lower(trim(m.director)) = t.director AND m.id <> t.id
+		),
+		candidates AS (
+			SELECT movie_id FROM g
+			UNION SELECT movie_id FROM c
+			UNION SELECT movie_id FROM p
+			UNION SELECT movie_id FROM d
+		)
+		SELECT m.id,
+			@genres * COALESCE(g.share, 0)
+			+ @countries * COALESCE(c.share, 0)
+			+ @credits * GREATEST(COALESCE(p.share, 0), CASE WHEN d.movie_id IS NULL THEN 0 ELSE 1 END)
+			+ @language * CASE WHEN m.language = t.language THEN 1 ELSE 0 END
+			+ @year * CASE WHEN m.year > 0 AND t.year > 0
+				THEN GREATEST(0, 1 - abs(m.year - t.year)::float / @window) ELSE 0 END
+			AS score
+		FROM candidates x
+		JOIN movies m ON m.id = x.movie_id
+		CROSS JOIN target t
+		LEFT JOIN g ON g.movie_id = m.id
+		LEFT JOIN c ON c.movie_id = m.id
+		LEFT JOIN p ON p.movie_id = m.id
+		LEFT JOIN d ON d.movie_id = m.id
+		WHERE m.deleted_at IS NULL AND m.published_at IS NOT NULL
+		ORDER BY score DESC, m.id
+		LIMIT @limit`,
+		map[string]interface{}{
+			"id":        id,
+			"genres":    weights.Genres,
+			"countries": weights.Countries,
+			"credits":   weights.Credits,
+			"language":  weights.Language,
+			"year":      weights.Year,
+			"window":    float64(weights.YearWindow),
+			"limit":     limit,
+		}).
+		Scan(&scores).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	return r.GetByID(ctx, movie.ID)
+	return scores, nil
 }
 
-func (r *MovieRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Movie{}, id).Error
+// FeedStats counts the published movies, optionally of one genre and its descendants, and finds when they last changed.
+// Deleted movies count as changes so that sitemaps and feeds are refetched
+func (r *MovieRepository) FeedStats(ctx context.Context, genreID uuid.UUID) (*FeedStats, error) {
+	var row struct {
+		Count        int64
+		LastModified sql.NullTime
+	}
+
+	query := r.db.WithContext(ctx).Unscoped().Table("movies").
+		Select("COUNT(*) FILTER (WHERE deleted_at IS NULL AND published_at IS NOT NULL) AS count, " +
+			"MAX(GREATEST(updated_at, COALESCE(deleted_at, updated_at))) AS last_modified")
+	if genreID != uuid.Nil {
+		query = query.Where(inGenreTree, genreID)
+	}
+	if err := query.Scan(&row).Error; err != nil {
+		return nil, err
+	}
+
+	return &FeedStats{Count: row.Count, LastModified: row.LastModified.Time}, nil
 }
 
-func (r *MovieRepository) Count(ctx context.Context) (int, error) {
+// EachForSitemap streams the sitemap entries of published movies [offset, offset+limit) in a stable order
+func (r *MovieRepository) EachForSitemap(ctx context.Context, offset, limit int, fn func(SitemapEntry) error) error {
+	rows, err := r.db.WithContext(ctx).Model(&models.Movie{}).
+		Select("id, COALESCE(slug, '') AS slug, updated_at").
+		Where("published_at IS NOT NULL").
+		Order("created_at, id").
+		Offset(offset).
+		Limit(limit).
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry SitemapEntry
+		if err = rows.Scan(&entry.ID, &entry.Slug, &entry.UpdatedAt); err != nil {
+			return err
+		}
+		if err = fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// EachLatest streams the most recently published movies, optionally of one genre and its descendants, without their relations
+func (r *MovieRepository) EachLatest(ctx context.Context, genreID uuid.UUID, limit int, fn func(*models.Movie) error) error {
+	query := r.db.WithContext(ctx).Model(&models.Movie{}).
+		Where("published_at IS NOT NULL").
+		Order("published_at DESC, id").
+		Limit(limit)
+	if genreID != uuid.Nil {
+		query = query.Where(inGenreTree, genreID)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Movie
+		if err = r.db.ScanRows(rows, &movie); err != nil {
+			return err
+		}
+		if err = fn(&movie); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *MovieRepository) Count(ctx context.Context, drafts bool) (int, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&models.Movie{}).Count(&count).Error; err != nil {
+	if err := r.published(r.db.WithContext(ctx).Model(&models.Movie{}), drafts).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return int(count), nil
 }
 
-func (r *MovieRepository) Search(ctx context.Context, query string, page, limit int) ([]*models.Movie, error) {
+func (r *MovieRepository) Search(ctx context.Context, filter MovieFilter, page, limit int) ([]*models.Movie, error) {
 	var movies []*models.Movie
 	offset := (page - 1) * limit
 
-	db := r.db.WithContext(ctx).
-		Preload("Language").
-		Preload("Countries").
-		Preload("Genres")
-
-	if query != "" {
-		db = db.Where("title ILIKE ?", "%"+query+"%").
-			Or("plot ILIKE ?", "%"+query+"%").
-			Or("director ILIKE ?", "%"+query+"%")
-	}
+	db := r.applySort(r.applyFilter(r.preload(r.db.WithContext(ctx)), filter), filter)
 
 	if err := db.Offset(offset).Limit(limit).Find(&movies).Error; err != nil {
 		return nil, err
@@ -221,14 +662,9 @@ func (r *MovieRepository) Search(ctx context.Context, query string, page, limit
 	return movies, nil
 }
 
-func (r *MovieRepository) SearchCount(ctx context.Context, query string) (int, error) {
+func (r *MovieRepository) SearchCount(ctx context.Context, filter MovieFilter) (int, error) {
 	var count int64
-	db := r.db.WithContext(ctx).Model(&models.Movie{})
-
-	if query != "" {
-		db = db.Where("title ILIKE ?", "%"+query+"%").
-			Or("director ILIKE ?", "%"+query+"%")
-	}
+	db := r.applyFilter(r.db.WithContext(ctx).Model(&models.Movie{}), filter)
 
 	if err := db.Count(&count).Error; err != nil {
 		return 0, err
@@ -236,3 +672,228 @@ func (r *MovieRepository) SearchCount(ctx context.Context, query string) (int, e
 
 	return int(count), nil
 }
+
+func (r *MovieRepository) preload(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Language").
+		Preload("AudioLanguages").
+		Preload("SubtitleLanguages").
+		Preload("AlternateTitles").
+		Preload("AlternateTitles.Country").
+		Preload("ReleaseDates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("date ASC")
+		}).
+		Preload("ReleaseDates.Country").
+		Preload("Countries").
+		Preload("Genres").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name ASC")
+		}).
+		Preload("Credits", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("MediaAssets", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		})
+}
+
+// published leaves out unpublished movies unless drafts is set
+func (r *MovieRepository) published(db *gorm.DB, drafts bool) *gorm.DB {
+	if drafts {
+		return db
+	}
+	return db.Where("movies.published_at IS NOT NULL")
+}
+
+func (r *MovieRepository) applyFilter(db *gorm.DB, filter MovieFilter) *gorm.DB {
+	db = r.published(db, filter.Drafts)
+
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		db = db.Where(
+			"(movies.title ILIKE ? OR movies.plot ILIKE ? OR movies.director ILIKE ? OR EXISTS "+
+				"(SELECT 1 FROM alternate_titles at WHERE at.movie_id = movies.id AND at.title ILIKE ?))",
+			like, like, like, like,
+		)
+	}
+
+	if filter.AudioLanguage != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM movie_audio_languages mal JOIN languages l ON l.id = mal.language_id "+
+			"WHERE mal.movie_id = movies.id AND LOWER(l.code) = LOWER(?))", filter.AudioLanguage)
+	}
+
+	if filter.SubtitleLanguage != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM movie_subtitle_languages msl JOIN languages l ON l.id = msl.language_id "+
+			"WHERE msl.movie_id = movies.id AND LOWER(l.code) = LOWER(?))", filter.SubtitleLanguage)
+	}
+
+	if filter.GenreID != uuid.Nil {
+		db = db.Where(inGenreTree, filter.GenreID)
+	}
+
+	if len(filter.Tags) > 0 {
+		if filter.AllTags {
+			db = db.Where("(SELECT COUNT(DISTINCT t.name) FROM movie_tags mt JOIN tags t ON t.id = mt.tag_id "+
+				"WHERE mt.movie_id = movies.id AND t.name IN ?) = ?", filter.Tags, len(filter.Tags))
+		} else {
+			db = db.Where("EXISTS (SELECT 1 FROM movie_tags mt JOIN tags t ON t.id = mt.tag_id "+
+				"WHERE mt.movie_id = movies.id AND t.name IN ?)", filter.Tags)
+		}
+	}
+
+	if filter.ReleasedIn != "" {
+		condition := "EXISTS (SELECT 1 FROM release_dates rd JOIN countries c ON c.id = rd.country_id " +
+			"WHERE rd.movie_id = movies.id AND UPPER(c.code) = UPPER(?)"
+		args := []interface{}{filter.ReleasedIn}
+		if filter.ReleasedAfter != nil {
+			condition += " AND rd.date >= ?"
+			args = append(args, *filter.ReleasedAfter)
+		}
+		if filter.ReleasedBefore != nil {
+			condition += " AND rd.date <= ?"
+			args = append(args, *filter.ReleasedBefore)
+		}
+		db = db.Where(condition+")", args...)
+	} else {
+		if filter.ReleasedAfter != nil {
+			db = db.Where("movies.release_date >= ?", *filter.ReleasedAfter)
+		}
+		if filter.ReleasedBefore != nil {
+			db = db.Where("movies.release_date <= ?", *filter.ReleasedBefore)
+		}
+	}
+
+	if filter.Awards != "" {
+		condition := "EXISTS (SELECT 1 FROM nominations n JOIN award_categories ac ON ac.id = n.category_id WHERE n.movie_id = movies.id"
+		if filter.Awards == WonAwards {
+			condition += " AND n.won"
+		}
+		if filter.AwardID != uuid.Nil {
+			db = db.Where(condition+" AND ac.award_id = ?)", filter.AwardID)
+		} else {
+			db = db.Where(condition + ")")
+		}
+	}
+
+	return db
+}
+
+// applySort orders by the trending or popularity score of the region, unscored movies come last
+func (r *MovieRepository) applySort(db *gorm.DB, filter MovieFilter) *gorm.DB {
+	var column string
+	switch filter.Sort {
+	case TrendingSort:
+		column = "trending_score"
+	case PopularSort:
+		column = "popularity_score"
+	default:
+		return db
+	}
+
+	return db.
+		Joins("LEFT JOIN movie_popularity mp ON mp.movie_id = movies.id AND mp.country_code = ?", strings.ToUpper(filter.Region)).
+		Order("mp." + column + " DESC NULLS LAST").
+		Order("movies.created_at DESC")
+}
+
+// saveRelations inserts the join table rows and alternate titles of the movie
+func (r *MovieRepository) saveRelations(tx *gorm.DB, movie *models.Movie) error {
+	genreIDs := make([]uuid.UUID, 0, len(movie.Genres))
+	for _, genre := range movie.Genres {
+		genreIDs = append(genreIDs, genre.ID)
+	}
+	if err := insertLinks(tx, "movie_genres", "genre_id", movie.ID, genreIDs); err != nil {
+		return err
+	}
+
+	tagIDs := make([]uuid.UUID, 0, len(movie.Tags))
+	for _, tag := range movie.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	if err := insertLinks(tx, "movie_tags", "tag_id", movie.ID, tagIDs); err != nil {
+		return err
+	}
+
+	countryIDs := make([]uuid.UUID, 0, len(movie.Countries))
+	for _, country := range movie.Countries {
+		countryIDs = append(countryIDs, country.ID)
+	}
+	if err := insertLinks(tx, "movie_countries", "country_id", movie.ID, countryIDs); err != nil {
+		return err
+	}
+
+	audioIDs := make([]uuid.UUID, 0, len(movie.AudioLanguages))
+	for _, language := range movie.AudioLanguages {
+		audioIDs = append(audioIDs, language.ID)
+	}
+	if err := insertLinks(tx, "movie_audio_languages", "language_id", movie.ID, audioIDs); err != nil {
+		return err
+	}
+
+	subtitleIDs := make([]uuid.UUID, 0, len(movie.SubtitleLanguages))
+	for _, language := range movie.SubtitleLanguages {
+		subtitleIDs = append(subtitleIDs, language.ID)
+	}
+	if err := insertLinks(tx, "movie_subtitle_languages", "language_id", movie.ID, subtitleIDs); err != nil {
+		return err
+	}
+
+	if len(movie.AlternateTitles) > 0 {
+		for i := range movie.AlternateTitles {
+			movie.AlternateTitles[i].ID = uuid.Nil
+			movie.AlternateTitles[i].MovieID = movie.ID
+		}
+		if err := tx.Omit("Country").Create(&movie.AlternateTitles).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(movie.ReleaseDates) > 0 {
+		for i := range movie.ReleaseDates {
+			movie.ReleaseDates[i].ID = uuid.Nil
+			movie.ReleaseDates[i].MovieID = movie.ID
+		}
+		if err := tx.Omit("Country").Create(&movie.ReleaseDates).Error; err != nil {
+			return err
+		}
+	}
+
+	return syncReleaseDate(tx, movie.ID)
+}
+
+// syncReleaseDate sets the primary release date of a movie to its earliest theatrical release,
+// or else its earliest release of any type. Movies without release dates keep theirs, also when an update
+// removed the last one: the date computed from them stays until releaseDate is set again
+func syncReleaseDate(tx *gorm.DB, movieID uuid.UUID) error {
+	return tx.Exec(`
+		UPDATE movies SET release_date = primary_release.date
+		FROM (
+			SELECT date FROM release_dates WHERE movie_id = ?
+			ORDER BY type = ? DESC, date ASC
+			LIMIT 1
+		) primary_release
+		WHERE movies.id = ?`, movieID, models.TheatricalRelease, movieID).Error
+}
+
+// insertLinks batch inserts (movie_id, <column>) pairs into a movie join table
+func insertLinks(tx *gorm.DB, table, column string, movieID uuid.UUID, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Build values string for PostgreSQL batch insert
+	var valueStrings []string
+	var valueArgs []interface{}
+
+	for i, id := range ids {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
+		valueArgs = append(valueArgs, movieID, id)
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (movie_id, %s) VALUES %s ON CONFLICT DO NOTHING",
+		table, column, strings.Join(valueStrings, ","),
+	)
+
+	return tx.Exec(query, valueArgs...).Error
+}
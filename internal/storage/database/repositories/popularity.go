@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PopularityRepository stores engagement events and the trending and popularity scores computed from them
+type PopularityRepository struct {
+	db *gorm.DB
+}
+
+// PopularityParams configures a score recomputation
+type PopularityParams struct {
+	Weights          map[string]float64 // per event type, other types are ignored
+	TrendingHalfLife float64            // in hours
+	PopularHalfLife  float64            // in hours
+	WindowDays       int
+}
+
+// NewPopularityRepository creates a new popularity repository
+func NewPopularityRepository(postgres *database.PostgresDB) *PopularityRepository {
+	return &PopularityRepository{db: postgres.DB}
+}
+
+func (r *PopularityRepository) AddEvent(ctx context.Context, event *models.MovieEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// CountVisitorEvents returns how many events the visitor sent since the given time
+func (r *PopularityRepository) CountVisitorEvents(ctx context.Context, visitor string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.MovieEvent{}).
+		Where("visitor = ? AND created_at > ?", visitor, since).
+		Count(&count).Error
+	return count, err
+}
+
+// HasVisitorEvent reports whether the visitor already has an event of the type for the movie since the given time
+func (r *PopularityRepository) HasVisitorEvent(ctx context.Context, movieID uuid.UUID, visitor, eventType string, since time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.MovieEvent{}).
+		Where("visitor = ? AND created_at > ? AND movie_id = ? AND type = ?", visitor, since, movieID, eventType).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Recompute prunes events older than the window and rebuilds movie_popularity per country and worldwide.
+// Every event counts weight * 0.5^(age / half-life). Only one replica recomputes at a time,
+// computed is false when another one holds the lock
+func (r *PopularityRepository) Recompute(ctx context.Context, params PopularityParams) (computed bool, rows int64, err error) {
+	if len(params.Weights) == 0 {
+		return false, 0, nil
+	}
+
+	types := make([]string, 0, len(params.Weights))
+	for eventType := range params.Weights {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+
+	values := make([]string, 0, len(types))
+	args := make([]interface{}, 0, 2*len(types)+3)
+	for _, eventType := range types {
+		values = append(values, "(?, ?::double precision)")
+		args = append(args, eventType, params.Weights[eventType])
+	}
+	args = append(args, params.TrendingHalfLife, params.PopularHalfLife, params.WindowDays)
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext('movie_popularity'))").
+			Scan(&computed).Error; err != nil || !computed {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM movie_events WHERE created_at < now() - make_interval(days => ?)", params.WindowDays).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM movie_popularity").Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			WITH weights (type, weight) AS (VALUES `+strings.Join(values, ", ")+`),
+			decayed AS (
+				SELECT e.movie_id, e.country_code,
+					w.weight * power(0.5, extract(epoch FROM now() - e.created_at) / 3600 / ?) AS trending,
+					w.weight * power(0.5, extract(epoch FROM now() - e.created_at) / 3600 / ?) AS popular
+				FROM movie_events e
+				JOIN weights w ON w.type = e.type
+				JOIN movies m ON m.id = e.movie_id AND m.deleted_at IS NULL
+				WHERE e.created_at >= now() - make_interval(days => ?)
+			)
+			INSERT INTO movie_popularity (movie_id, country_code, trending_score, popularity_score, computed_at)
+			SELECT movie_id, CASE WHEN GROUPING(country_code) = 1 THEN '' ELSE country_code END,
+				SUM(trending), SUM(popular), now()
+			FROM decayed
+			GROUP BY GROUPING SETS ((movie_id, country_code), (movie_id))
+			HAVING GROUPING(country_code) = 1 OR country_code <> ''`, args...)
+		if result.Error != nil {
+			return result.Error
+		}
+		rows = result.RowsAffected
+
+		return nil
+	})
+
+	return computed, rows, err
+}
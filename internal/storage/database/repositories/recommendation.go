@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/storage/database"
+	"time"
+)
+
+// RecommendationRepository computes and reads the item-item co-occurrence model
+type RecommendationRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendationRepository creates a new recommendation repository
+func NewRecommendationRepository(postgres *database.PostgresDB) *RecommendationRepository {
+	return &RecommendationRepository{db: postgres.DB}
+}
+
+// likedMoviesSQL selects the (user_id, movie_id) pairs a user liked: watched or rated at least
+// @min_rating, unless the user rated the movie lower
+const likedMoviesSQL = `
+	SELECT l.user_id, l.movie_id
+	FROM (
+		SELECT user_id, movie_id FROM watch_histories
+		UNION
+		SELECT user_id, movie_id FROM movie_ratings WHERE score >= @min_rating
+	) l
+	JOIN movies m ON m.id = l.movie_id AND m.deleted_at IS NULL
+	WHERE NOT EXISTS (
+		SELECT 1 FROM movie_ratings r
+		WHERE r.user_id = l.user_id AND r.movie_id = l.movie_id AND r.score < @min_rating
+	)`
+
+// Recompute rebuilds movie_cooccurrences, keeping the maxRelated most similar movies per movie.
+// Only one replica recomputes at a time, computed is false when another one holds the lock
+func (r *RecommendationRepository) Recompute(ctx context.Context, minRating, maxRelated int) (computed bool, pairs int64, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext('movie_cooccurrences'))").
+			Scan(&computed).Error; err != nil || !computed {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM movie_cooccurrences").Error; err != nil {
+			return err
+		}
+
+		// cosine similarity of the two audiences: users who liked both / sqrt(users of a * users of b)
+		result := tx.Exec(`
+			WITH liked AS (`+likedMoviesSQL+`),
+			audience AS (
+				SELECT movie_id, COUNT(*) AS users FROM liked GROUP BY movie_id
+			),
+			pairs AS (
+				SELECT a.movie_id, b.movie_id AS related_id, COUNT(*) AS users
+				FROM liked a
+				JOIN liked b ON b.user_id = a.user_id AND b.movie_id <> a.movie_id
+				GROUP BY a.movie_id, b.movie_id
+			),
+			scored AS (
+				SELECT p.movie_id, p.related_id, p.users, p.users / sqrt(ma.users * mb.users) AS score
+				FROM pairs p
+				JOIN audience ma ON ma.movie_id = p.movie_id
+				JOIN audience mb ON mb.movie_id = p.related_id
+			),
+			ranked AS (
+				SELECT *, row_number() OVER (PARTITION BY movie_id ORDER BY score DESC, related_id) AS rank
+				FROM scored
+			)
+			INSERT INTO movie_cooccurrences (movie_id, related_id, users, score, computed_at)
+			SELECT movie_id, related_id, users, score, now()
+			FROM ranked
+			WHERE rank <= @max_related`,
+			map[string]interface{}{"min_rating": minRating, "max_related": maxRelated})
+		if result.Error != nil {
+			return result.Error
+		}
+		pairs = result.RowsAffected
+
+		return nil
+	})
+
+	return computed, pairs, err
+}
+
+// ForUser scores the published movies related to what the user liked and has not seen yet.
+// A liked movie weighs rating/10, or watchedWeight when it was only watched
+func (r *RecommendationRepository) ForUser(ctx context.Context, userID uuid.UUID, minRating int, watchedWeight float64, limit int) ([]SimilarScore, error) {
+	var scores []SimilarScore
+
+	err := r.db.WithContext(ctx).Raw(`
+		WITH seen AS (
+			SELECT movie_id FROM watch_histories WHERE user_id = @user
+			UNION
+			SELECT movie_id FROM movie_ratings WHERE user_id = @user
+		),
+		seeds AS (
+			SELECT s.movie_id, COALESCE(r.score / 10.0, @watched_weight) AS weight
+			FROM seen s
+			LEFT JOIN movie_ratings r ON r.user_id = @user AND r.movie_id = s.movie_id
+			WHERE r.score IS NULL OR r.score >= @min_rating
+		)
+		SELECT c.related_id AS id, SUM(c.score * s.weight) AS score
+		FROM seeds s
+		JOIN movie_cooccurrences c ON c.movie_id = s.movie_id
+		JOIN movies m ON m.id = c.related_id AND m.deleted_at IS NULL AND m.published_at IS NOT NULL
+		WHERE c.related_id NOT IN (SELECT movie_id FROM seen)
+		GROUP BY c.related_id
+		ORDER BY score DESC, c.related_id
+		LIMIT @limit`,
+		map[string]interface{}{
+			"user":           userID,
+			"min_rating":     minRating,
+			"watched_weight": watchedWeight,
+			"limit":          limit,
+		}).
+		Scan(&scores).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+// Popular ranks published movies by how many users liked them since the given time, falling back to
+// the editorial rating. genreIDs narrows it to those genres, movies the user has seen are left out
+func (r *RecommendationRepository) Popular(ctx context.Context, userID uuid.UUID, genreIDs []uuid.UUID, since time.Time, minRating, limit int) ([]SimilarScore, error) {
+	var scores []SimilarScore
+
+	genreCondition := ""
+	args := map[string]interface{}{
+		"user":       userID,
+		"since":      since,
+		"min_rating": minRating,
+		"limit":      limit,
+	}
+	if len(genreIDs) > 0 {
+		genreCondition = "AND EXISTS (SELECT 1 FROM movie_genres mg WHERE mg.movie_id = m.id AND mg.genre_id IN @genres)"
+		args["genres"] = genreIDs
+	}
+
+	err := r.db.WithContext(ctx).Raw(`
+		WITH recent AS (
+			SELECT user_id, movie_id FROM watch_histories WHERE watched_at >= @since
+			UNION
+			SELECT user_id, movie_id FROM movie_ratings WHERE updated_at >= @since AND score >= @min_rating
+		)
+		SELECT m.id, COUNT(recent.user_id)::float AS score
+		FROM movies m
+		LEFT JOIN recent ON recent.movie_id = m.id
+		WHERE m.deleted_at IS NULL AND m.published_at IS NOT NULL `+genreCondition+`
+			AND m.id NOT IN (
+				SELECT movie_id FROM watch_histories WHERE user_id = @user
+				UNION
+				SELECT movie_id FROM movie_ratings WHERE user_id = @user
+			)
+		GROUP BY m.id
+		ORDER BY score DESC, m.rating DESC, m.id
+		LIMIT @limit`, args).
+		Scan(&scores).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
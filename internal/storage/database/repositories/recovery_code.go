@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+	"time"
+)
+
+// RecoveryCodeRepository stores the hashed one-time 2FA recovery codes
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepository creates a new recovery code repository
+func NewRecoveryCodeRepository(postgres *database.PostgresDB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: postgres.DB}
+}
+
+// Replace swaps every recovery code of the user for a new set
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	codes := make([]models.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, models.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Use marks an unused code of the user as used, used is false when there is no such code
+func (r *RecoveryCodeRepository) Use(ctx context.Context, userID uuid.UUID, hash string) (used bool, err error) {
+	result := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Limit(1).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountUnused returns how many recovery codes the user has left
+func (r *RecoveryCodeRepository) CountUnused(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteForUser removes every recovery code of the user
+func (r *RecoveryCodeRepository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
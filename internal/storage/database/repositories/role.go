@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+)
+
+// RoleRepository stores the roles and the permissions they grant
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(postgres *database.PostgresDB) *RoleRepository {
+	return &RoleRepository{db: postgres.DB}
+}
+
+// GetPermissions returns every known permission
+func (r *RoleRepository) GetPermissions(ctx context.Context) ([]*models.Permission, error) {
+	var permissions []*models.Permission
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// FindPermissions returns the permissions with the given names, unknown names are left out
+func (r *RoleRepository) FindPermissions(ctx context.Context, names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	if err := r.db.WithContext(ctx).Where("name IN ?", names).Order("name ASC").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// GetAll returns the roles with their permissions
+func (r *RoleRepository) GetAll(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetByName returns the role with its permissions
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// Create stores the role and links its permissions
+func (r *RoleRepository) Create(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+// Update saves the description and replaces the granted permissions
+func (r *RoleRepository) Update(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Update("description", role.Description).Error; err != nil {
+			return err
+		}
+		return tx.Model(role).Association("Permissions").Replace(role.Permissions)
+	})
+}
+
+// Delete removes the role and its permission links
+func (r *RoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Role{}, "id = ?", id).Error
+	})
+}
+
+// CountUsers returns how many users have the role
+func (r *RoleRepository) CountUsers(ctx context.Context, name string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+)
+
+// SecurityEventRepository stores the audit trail of suspicious authentication activity
+type SecurityEventRepository struct {
+	db *gorm.DB
+}
+
+// SecurityEventFilter narrows the events to a user and/or a type, zero values match everything
+type SecurityEventFilter struct {
+	UserID uuid.UUID
+	Type   string
+}
+
+// NewSecurityEventRepository creates a new security event repository
+func NewSecurityEventRepository(postgres *database.PostgresDB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: postgres.DB}
+}
+
+func (r *SecurityEventRepository) Create(ctx context.Context, event *models.SecurityEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// GetAll returns a page of events, newest first, and the number of matching events
+func (r *SecurityEventRepository) GetAll(ctx context.Context, filter SecurityEventFilter, page, limit int) ([]*models.SecurityEvent, int64, error) {
+	var events []*models.SecurityEvent
+	var total int64
+
+	if err := r.filter(ctx, filter).Model(&models.SecurityEvent{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.filter(ctx, filter).
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (r *SecurityEventRepository) filter(ctx context.Context, filter SecurityEventFilter) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	if filter.UserID != uuid.Nil {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Type != "" {
+		db = db.Where("type = ?", filter.Type)
+	}
+	return db
+}
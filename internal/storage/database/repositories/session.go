@@ -26,52 +26,83 @@ func (r *SessionRepository) Create(ctx context.Context, session *models.Session)
 	return session, nil
 }
 
-func (r *SessionRepository) GetByToken(ctx context.Context, token string) (*models.Session, error) {
-	var session models.Session
-	if err := r.db.WithContext(ctx).Where("token = ? AND is_revoked = false", token).First(&session).Error; err != nil {
-		return nil, err
-	}
-	return &session, nil
-}
-
+// GetActiveSessionsByUserID returns the sessions that can still be used or refreshed, newest first
 func (r *SessionRepository) GetActiveSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
 	var sessions []*models.Session
 	if err := r.db.WithContext(ctx).
-		Where("user_id = ? AND is_revoked = false AND expires_at > ?", userID, time.Now()).
+		Where("user_id = ? AND is_revoked = false AND refresh_expiry > ?", userID, time.Now()).
+		Order("created_at DESC").
 		Find(&sessions).Error; err != nil {
 		return nil, err
 	}
 	return sessions, nil
 }
 
-func (r *SessionRepository) RevokeByToken(ctx context.Context, token string) error {
-	return r.db.WithContext(ctx).Model(&models.Session{}).
-		Where("token = ?", token).
-		Update("is_revoked", true).Error
-}
-
 func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&models.Session{}).
 		Where("user_id = ? AND is_revoked = false", userID).
 		Update("is_revoked", true).Error
 }
 
-func (r *SessionRepository) GetByAccessToken(ctx context.Context, accessToken string) (*models.Session, error) {
+// GetByAccessJTI finds the active session of an access token by the token's jti
+func (r *SessionRepository) GetByAccessJTI(ctx context.Context, jti string) (*models.Session, error) {
 	var session models.Session
-	if err := r.db.WithContext(ctx).Where("access_token = ? AND is_revoked = false", accessToken).First(&session).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("access_jti = ? AND is_revoked = false", jti).First(&session).Error; err != nil {
 		return nil, err
 	}
 	return &session, nil
 }
 
-func (r *SessionRepository) GetByRefreshToken(ctx context.Context, refreshToken string) (*models.Session, error) {
+// RevokeOthersForUser revokes every session of the user except keepID
+func (r *SessionRepository) RevokeOthersForUser(ctx context.Context, userID, keepID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND is_revoked = false", userID, keepID).
+		Update("is_revoked", true)
+	return result.RowsAffected, result.Error
+}
+
+func (r *SessionRepository) GetByID(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
 	var session models.Session
-	if err := r.db.WithContext(ctx).Where("refresh_token = ? AND is_revoked = false", refreshToken).First(&session).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", sessionID).First(&session).Error; err != nil {
 		return nil, err
 	}
 	return &session, nil
 }
 
+// FindByRefreshJTI finds a session by the jti of its refresh token, revoked ones included
+func (r *SessionRepository) FindByRefreshJTI(ctx context.Context, jti string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.WithContext(ctx).Where("refresh_jti = ?", jti).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// IsRotated reports whether the refresh token of the session was already exchanged for a new session
+func (r *SessionRepository) IsRotated(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Session{}).Where("rotated_from = ?", sessionID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// RevokeIfActive revokes the session unless it already is, revoked is false when another request got there first
+func (r *SessionRepository) RevokeIfActive(ctx context.Context, sessionID uuid.UUID) (revoked bool, err error) {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ? AND is_revoked = false", sessionID).
+		Update("is_revoked", true)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeFamily revokes every session of a refresh token rotation chain
+func (r *SessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("family_id = ? AND is_revoked = false", familyID).
+		Update("is_revoked", true)
+	return result.RowsAffected, result.Error
+}
+
 func (r *SessionRepository) RevokeByID(ctx context.Context, sessionID uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&models.Session{}).
 		Where("id = ?", sessionID).
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+)
+
+// SlugRepository handles slug uniqueness checks and slug history
+type SlugRepository struct {
+	db *gorm.DB
+}
+
+// NewSlugRepository creates a new slug repository
+func NewSlugRepository(postgres *database.PostgresDB) *SlugRepository {
+	return &SlugRepository{db: postgres.DB}
+}
+
+// IsTaken reports whether another entity uses the slug, currently or in its history.
+// model selects the entity table, e.g. &models.Movie{}
+func (r *SlugRepository) IsTaken(ctx context.Context, model interface{}, entityType, slug string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+
+	// unscoped, soft deleted rows still hold their unique slug
+	if err := r.db.WithContext(ctx).Unscoped().Model(model).
+		Where("slug = ? AND id <> ?", slug, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := r.db.WithContext(ctx).Model(&models.SlugHistory{}).
+		Where("entity_type = ? AND slug = ? AND entity_id <> ?", entityType, slug, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// AddHistory records a previous slug, re-adding a known slug just re-points it
+func (r *SlugRepository) AddHistory(ctx context.Context, entityType string, entityID uuid.UUID, slug string) error {
+	history := models.SlugHistory{EntityType: entityType, EntityID: entityID, Slug: slug}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entity_type"}, {Name: "slug"}},
+		DoUpdates: clause.AssignmentColumns([]string{"entity_id"}),
+	}).Create(&history).Error
+}
+
+// WithBackfillLock runs fn while holding the slug backfill advisory lock, so that only one replica backfills.
+// It reports false without running fn when another session holds the lock
+func (r *SlugRepository) WithBackfillLock(ctx context.Context, fn func() error) (bool, error) {
+	var locked bool
+
+	// session level locks belong to a connection, keep one for the lock and the unlock
+	err := r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw("SELECT pg_try_advisory_lock(hashtext('slug_backfill'))").
+			Scan(&locked).Error; err != nil || !locked {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(hashtext('slug_backfill'))")
+
+		return fn()
+	})
+
+	return locked, err
+}
+
+// FindHistory returns the entity that used to own the slug
+func (r *SlugRepository) FindHistory(ctx context.Context, entityType, slug string) (*models.SlugHistory, error) {
+	var history models.SlugHistory
+
+	if err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND slug = ?", entityType, slug).
+		First(&history).Error; err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+// FindWithoutSlug loads up to limit rows of the dest model that have no slug yet
+func (r *SlugRepository) FindWithoutSlug(ctx context.Context, dest interface{}, limit int) error {
+	return r.db.WithContext(ctx).
+		Where("slug IS NULL OR slug = ''").
+		Limit(limit).
+		Find(dest).Error
+}
+
+// SetSlug updates only the slug column, without touching updated_at
+func (r *SlugRepository) SetSlug(ctx context.Context, model interface{}, id uuid.UUID, slug string) error {
+	return r.db.WithContext(ctx).Model(model).
+		Where("id = ?", id).
+		UpdateColumn("slug", slug).Error
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"itv-movie/internal/storage/database"
+	"time"
+)
+
+// StatsRepository runs catalog reports as SQL aggregates
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+// StatsRange limits reports to movies added in [From, To), nil bounds are open
+type StatsRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// StatsCount is the number of movies for a key (genre, country, year, month...)
+type StatsCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// StatsRating is the average rating of the movies for a key
+type StatsRating struct {
+	Key           string  `json:"key"`
+	Movies        int64   `json:"movies"`
+	AverageRating float64 `json:"averageRating"`
+}
+
+// NewStatsRepository creates a new stats repository
+func NewStatsRepository(postgres *database.PostgresDB) *StatsRepository {
+	return &StatsRepository{db: postgres.DB}
+}
+
+// MoviesPerGenre counts movies by genre, genres without movies are listed with 0
+func (r *StatsRepository) MoviesPerGenre(ctx context.Context, rng StatsRange) ([]StatsCount, error) {
+	return r.countPerLink(ctx, "genres", "movie_genres", "genre_id", rng)
+}
+
+// MoviesPerCountry counts movies by production country
+func (r *StatsRepository) MoviesPerCountry(ctx context.Context, rng StatsRange) ([]StatsCount, error) {
+	return r.countPerLink(ctx, "countries", "movie_countries", "country_id", rng)
+}
+
+// MoviesPerLanguage counts movies by original language
+func (r *StatsRepository) MoviesPerLanguage(ctx context.Context, rng StatsRange) ([]StatsCount, error) {
+	var counts []StatsCount
+	condition, args := rangeCondition("m.created_at", rng)
+
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT l.name AS key, COUNT(m.id) AS count
+		FROM languages l
+		LEFT JOIN movies m ON m.language = l.id AND m.deleted_at IS NULL`+condition+`
+		WHERE l.deleted_at IS NULL
+		GROUP BY l.name
+		ORDER BY count DESC, l.name`, args...).
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// MoviesPerYear counts movies by release year, movies without a year are left out
+func (r *StatsRepository) MoviesPerYear(ctx context.Context, rng StatsRange) ([]StatsCount, error) {
+	var counts []StatsCount
+	condition, args := rangeCondition("created_at", rng)
+
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT year::text AS key, COUNT(*) AS count
+		FROM movies
+		WHERE deleted_at IS NULL AND year > 0`+condition+`
+		GROUP BY year
+		ORDER BY year`, args...).
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// AverageRatingPerGenre averages movie ratings by genre, unrated (0) movies are left out
+func (r *StatsRepository) AverageRatingPerGenre(ctx context.Context, rng StatsRange) ([]StatsRating, error) {
+	var ratings []StatsRating
+	condition, args := rangeCondition("m.created_at", rng)
+
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT g.name AS key, COUNT(m.id) AS movies, COALESCE(ROUND(AVG(m.rating), 2), 0) AS average_rating
+		FROM genres g
+		JOIN movie_genres mg ON mg.genre_id = g.id
+		JOIN movies m ON m.id = mg.movie_id AND m.deleted_at IS NULL AND m.rating > 0`+condition+`
+		WHERE g.deleted_at IS NULL
+		GROUP BY g.name
+		ORDER BY average_rating DESC, g.name`, args...).
+		Scan(&ratings).Error
+
+	return ratings, err
+}
+
+// MoviesAddedPerMonth counts movies by the month they were added, keyed "2006-01"
+func (r *StatsRepository) MoviesAddedPerMonth(ctx context.Context, rng StatsRange) ([]StatsCount, error) {
+	var counts []StatsCount
+	condition, args := rangeCondition("created_at", rng)
+
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT to_char(date_trunc('month', created_at), 'YYYY-MM') AS key, COUNT(*) AS count
+		FROM movies
+		WHERE deleted_at IS NULL`+condition+`
+		GROUP BY 1
+		ORDER BY 1`, args...).
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// countPerLink counts movies per row of table through a movie_id link table
+func (r *StatsRepository) countPerLink(ctx context.Context, table, linkTable, linkColumn string, rng StatsRange) ([]StatsCount, error) {
+	var counts []StatsCount
+	condition, args := rangeCondition("m.created_at", rng)
+
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT t.name AS key, COUNT(m.id) AS count
+		FROM `+table+` t
+		LEFT JOIN `+linkTable+` l ON l.`+linkColumn+` = t.id
+		LEFT JOIN movies m ON m.id = l.movie_id AND m.deleted_at IS NULL`+condition+`
+		WHERE t.deleted_at IS NULL
+		GROUP BY t.name
+		ORDER BY count DESC, t.name`, args...).
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// rangeCondition renders the date range as extra AND conditions on column
+func rangeCondition(column string, rng StatsRange) (string, []interface{}) {
+	var condition string
+	var args []interface{}
+
+	if rng.From != nil {
+		condition += " AND " + column + " >= ?"
+		args = append(args, *rng.From)
+	}
+	if rng.To != nil {
+		condition += " AND " + column + " < ?"
+		args = append(args, *rng.To)
+	}
+
+	return condition, args
+}
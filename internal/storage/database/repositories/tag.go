@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+	"strings"
+)
+
+// TagRepository handles database operations for tags
+type TagRepository struct {
+	db *gorm.DB
+}
+
+// TagCount is a tag with the number of movies carrying it
+type TagCount struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Count int64     `json:"count"`
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(postgres *database.PostgresDB) *TagRepository {
+	return &TagRepository{db: postgres.DB}
+}
+
+func (r *TagRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Tag, error) {
+	var tag models.Tag
+
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&tag).Error; err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepository) GetByName(ctx context.Context, name string) (*models.Tag, error) {
+	var tag models.Tag
+
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// Ensure returns the tags with the given normalized names, creating the missing ones
+func (r *TagRepository) Ensure(ctx context.Context, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{Name: name}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+			return err
+		}
+
+		// re-read, tags that already existed keep their IDs
+		tags = tags[:0]
+		return tx.Where("name IN ?", names).Order("name").Find(&tags).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// Autocomplete returns the tags starting with prefix, the most used first
+func (r *TagRepository) Autocomplete(ctx context.Context, prefix string, limit int) ([]TagCount, error) {
+	var tags []TagCount
+
+	if err := r.counted(ctx).
+		Where(`tags.name LIKE ? ESCAPE '\'`, escapeLike(prefix)+"%").
+		Order("count DESC, tags.name").
+		Limit(limit).
+		Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// likeEscaper escapes the LIKE wildcards, so user input only matches itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes s literal inside a LIKE pattern that declares ESCAPE '\'
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Cloud returns the most used tags with their movie counts
+func (r *TagRepository) Cloud(ctx context.Context, limit int) ([]TagCount, error) {
+	var tags []TagCount
+
+	if err := r.counted(ctx).
+		Having("COUNT(movies.id) > 0").
+		Order("count DESC, tags.name").
+		Limit(limit).
+		Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// Rename changes the name of a tag, its movies keep it
+func (r *TagRepository) Rename(ctx context.Context, id uuid.UUID, name string) (*models.Tag, error) {
+	if err := r.db.WithContext(ctx).Model(&models.Tag{}).Where("id = ?", id).Update("name", name).Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Merge moves the movies of the duplicate tags to the survivor and deletes the duplicates
+func (r *TagRepository) Merge(ctx context.Context, survivorID uuid.UUID, duplicateIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO movie_tags (movie_id, tag_id) SELECT movie_id, ? FROM movie_tags WHERE tag_id IN ? ON CONFLICT DO NOTHING",
+			survivorID, duplicateIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM movie_tags WHERE tag_id IN ?", duplicateIDs).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Tag{}, duplicateIDs).Error
+	})
+}
+
+// counted selects tags with the number of their movies that are not deleted
+func (r *TagRepository) counted(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("tags").
+		Select("tags.id, tags.name, COUNT(movies.id) AS count").
+		Joins("LEFT JOIN movie_tags ON movie_tags.tag_id = tags.id").
+		Joins("LEFT JOIN movies ON movies.id = movie_tags.movie_id AND movies.deleted_at IS NULL").
+		Group("tags.id, tags.name")
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"time travel": "time travel",
+		"100%":        `100\%`,
+		"sci_fi":      `sci\_fi`,
+		`back\slash`:  `back\\slash`,
+		`%_\`:         `\%\_\\`,
+	}
+
+	for input, want := range tests {
+		if got := escapeLike(input); got != want {
+			t.Errorf("escapeLike(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"itv-movie/internal/models"
 	"itv-movie/internal/pkg/utils/constants"
 	"itv-movie/internal/storage/database"
@@ -71,6 +72,85 @@ func (r *UserRepository) UpdateStatus(ctx context.Context, userID uuid.UUID, act
 		Update("active", active).Error
 }
 
+// UpdateRole assigns the role with the given name
+func (r *UserRepository) UpdateRole(ctx context.Context, userID uuid.UUID, role string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("role", role).Error
+}
+
+// DemoteAdmin gives an admin another role unless that would leave no admin. The admin rows are locked
+// first, so concurrent demotions wait for each other and the later one sees the earlier one's result.
+// It reports false and changes nothing when the user is the last admin
+func (r *UserRepository) DemoteAdmin(ctx context.Context, userID uuid.UUID, role string) (bool, error) {
+	demoted := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var adminIDs []uuid.UUID
+		if err := tx.Model(&models.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("role = ?", constants.AdminRole).
+			Pluck("id", &adminIDs).Error; err != nil {
+			return err
+		}
+		if len(adminIDs) <= 1 {
+			return nil
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error; err != nil {
+			return err
+		}
+		demoted = true
+		return nil
+	})
+
+	return demoted, err
+}
+
+// UpdatePassword stores a new password hash
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("password", passwordHash).Error
+}
+
+// MarkEmailVerified records that the user confirmed the email address, the first confirmation is kept
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", time.Now()).Error
+}
+
+// SetTotpSecret stores the secret of a pending TOTP enrolment, an enabled enrolment is left alone
+func (r *UserRepository) SetTotpSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_enabled_at IS NULL", userID).
+		Update("totp_secret", secret).Error
+}
+
+// EnableTotp confirms the pending enrolment, step is the time step of the confirming code
+func (r *UserRepository) EnableTotp(ctx context.Context, userID uuid.UUID, step int64) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_enabled_at": time.Now(), "totp_last_step": step}).Error
+}
+
+// UseTotpStep records the time step of an accepted code. accepted is false when a code of this or a
+// later step was already used, so every code works once even under concurrent requests
+func (r *UserRepository) UseTotpStep(ctx context.Context, userID uuid.UUID, step int64) (accepted bool, err error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DisableTotp removes the TOTP secret of the user
+func (r *UserRepository) DisableTotp(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_secret": "", "totp_enabled_at": nil, "totp_last_step": 0}).Error
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
 }
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+	"time"
+)
+
+// UserIdentityRepository stores the links between users and OIDC provider accounts, and the pending
+// OIDC logins
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository creates a new user identity repository
+func NewUserIdentityRepository(postgres *database.PostgresDB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: postgres.DB}
+}
+
+// GetByProviderSubject returns the identity with its user
+func (r *UserIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.WithContext(ctx).Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// Create links an identity to a user
+func (r *UserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// CreateWithUser creates the user and its identity together
+func (r *UserIdentityRepository) CreateWithUser(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// Touch records a login through the identity
+func (r *UserIdentityRepository) Touch(ctx context.Context, identity *models.UserIdentity, email string) error {
+	return r.db.WithContext(ctx).Model(identity).Updates(map[string]interface{}{
+		"email":         email,
+		"last_login_at": time.Now(),
+	}).Error
+}
+
+// SaveState stores a pending login and drops the expired ones
+func (r *UserIdentityRepository) SaveState(ctx context.Context, state *models.OidcState) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&models.OidcState{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(state).Error
+	})
+}
+
+// ConsumeState deletes and returns an unexpired pending login, a state can be used once only
+func (r *UserIdentityRepository) ConsumeState(ctx context.Context, stateHash string) (*models.OidcState, error) {
+	var state models.OidcState
+	result := r.db.WithContext(ctx).Raw(`
+		DELETE FROM oidc_states
+		WHERE state_hash = ? AND expires_at > ?
+		RETURNING *`,
+		stateHash, time.Now(),
+	).Scan(&state)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &state, nil
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"itv-movie/internal/models"
+	"itv-movie/internal/storage/database"
+	"time"
+)
+
+// UserTokenRepository stores the hashed single use tokens of email verification and password reset
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository(postgres *database.PostgresDB) *UserTokenRepository {
+	return &UserTokenRepository{db: postgres.DB}
+}
+
+// Replace stores the token and retires the unused tokens the user already had for the same purpose,
+// so only the latest mailed link works
+func (r *UserTokenRepository) Replace(ctx context.Context, token *models.UserToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// Consume redeems an unused, unexpired token and returns its user. Redeeming is a single conditional
+// update, so concurrent requests cannot use the same token twice
+func (r *UserTokenRepository) Consume(ctx context.Context, purpose, tokenHash string) (uuid.UUID, error) {
+	var token models.UserToken
+	result := r.db.WithContext(ctx).Raw(`
+		UPDATE user_tokens SET used_at = ?
+		WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?
+		RETURNING *`,
+		time.Now(), tokenHash, purpose, time.Now(),
+	).Scan(&token)
+	if result.Error != nil {
+		return uuid.Nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return token.UserID, nil
+}
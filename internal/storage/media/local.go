@@ -0,0 +1,108 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps objects on the local filesystem under a root directory
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage creates a local storage, the root directory is created if missing
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if root == "" {
+		root = "uploads"
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &LocalStorage{root: root}, nil
+}
+
+func (s *LocalStorage) Put(_ context.Context, key string, r io.Reader, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// write to a temp file first so readers never see partial objects
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Open(_ context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrObjectNotFound
+		}
+		return nil, nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	info := &ObjectInfo{
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(path)),
+		ModifiedAt:  stat.ModTime(),
+	}
+
+	return file, info, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps a key to a file path, rejecting keys escaping the root directory
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if strings.Contains(key, "..") || cleaned == "/" {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.root, cleaned), nil
+}
@@ -0,0 +1,45 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"itv-movie/internal/config"
+	"time"
+)
+
+const (
+	LocalDriver = "local"
+	S3Driver    = "s3"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+	ModifiedAt  time.Time
+}
+
+// Storage is the pluggable blob storage used for uploaded media files
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStorage creates the storage implementation selected in config
+func NewStorage(cfg *config.Config) (Storage, error) {
+	storageCfg := cfg.Internal.Storage
+
+	switch storageCfg.Driver {
+	case LocalDriver, "":
+		return NewLocalStorage(storageCfg.LocalPath)
+	case S3Driver:
+		return nil, fmt.Errorf("storage driver %q is not supported yet", storageCfg.Driver)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", storageCfg.Driver)
+	}
+}
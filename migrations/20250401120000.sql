@@ -0,0 +1,37 @@
+-- Set comment to column: "language" on table: "movies"
+COMMENT ON COLUMN "movies"."language" IS 'Original language';
+-- Create "movie_audio_languages" table
+CREATE TABLE "movie_audio_languages" (
+  "movie_id" uuid NOT NULL,
+  "language_id" uuid NOT NULL,
+  PRIMARY KEY ("movie_id", "language_id"),
+  CONSTRAINT "fk_movie_audio_languages_language" FOREIGN KEY ("language_id") REFERENCES "languages" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_movie_audio_languages_movie" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create "movie_subtitle_languages" table
+CREATE TABLE "movie_subtitle_languages" (
+  "movie_id" uuid NOT NULL,
+  "language_id" uuid NOT NULL,
+  PRIMARY KEY ("movie_id", "language_id"),
+  CONSTRAINT "fk_movie_subtitle_languages_language" FOREIGN KEY ("language_id") REFERENCES "languages" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_movie_subtitle_languages_movie" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create "alternate_titles" table
+CREATE TABLE "alternate_titles" (
+  "id" uuid NOT NULL,
+  "movie_id" uuid NOT NULL,
+  "country_id" uuid NULL,
+  "title" text NOT NULL,
+  "type" text NOT NULL DEFAULT 'alternate',
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_alternate_titles_country" FOREIGN KEY ("country_id") REFERENCES "countries" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_movies_alternate_titles" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_alternate_titles_movie_id" to table: "alternate_titles"
+CREATE INDEX "idx_alternate_titles_movie_id" ON "alternate_titles" ("movie_id");
+-- Create index "idx_alternate_titles_title" to table: "alternate_titles"
+CREATE INDEX "idx_alternate_titles_title" ON "alternate_titles" ("title");
+-- Set comment to column: "type" on table: "alternate_titles"
+COMMENT ON COLUMN "alternate_titles"."type" IS 'alternate | original (original script)';
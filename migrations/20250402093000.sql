@@ -0,0 +1,23 @@
+-- Create "media_assets" table
+CREATE TABLE "media_assets" (
+  "id" uuid NOT NULL,
+  "movie_id" uuid NOT NULL,
+  "kind" text NOT NULL,
+  "content_type" text NOT NULL,
+  "size" bigint NOT NULL,
+  "width" integer NULL,
+  "height" integer NULL,
+  "storage_key" text NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_movies_media_assets" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_media_assets_movie_id" to table: "media_assets"
+CREATE INDEX "idx_media_assets_movie_id" ON "media_assets" ("movie_id");
+-- Set comment to column: "kind" on table: "media_assets"
+COMMENT ON COLUMN "media_assets"."kind" IS 'poster | backdrop';
+-- Set comment to column: "size" on table: "media_assets"
+COMMENT ON COLUMN "media_assets"."size" IS 'Original size in bytes';
+-- Set comment to column: "storage_key" on table: "media_assets"
+COMMENT ON COLUMN "media_assets"."storage_key" IS 'Key of the original file';
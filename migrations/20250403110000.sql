@@ -0,0 +1,4 @@
+-- Modify "movies" table
+ALTER TABLE "movies" ADD COLUMN "trailer_provider" text NULL, ADD COLUMN "trailer_video_id" text NULL;
+-- Set comment to column: "trailer_provider" on table: "movies"
+COMMENT ON COLUMN "movies"."trailer_provider" IS 'youtube | vimeo';
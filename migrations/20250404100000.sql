@@ -0,0 +1,29 @@
+-- Modify "movies" table
+ALTER TABLE "movies" ADD COLUMN "imdb_id" text NULL, ADD COLUMN "tmdb_id" bigint NULL;
+-- Create index "idx_movies_imdb_id" to table: "movies"
+CREATE UNIQUE INDEX "idx_movies_imdb_id" ON "movies" ("imdb_id");
+-- Create index "idx_movies_tmdb_id" to table: "movies"
+CREATE UNIQUE INDEX "idx_movies_tmdb_id" ON "movies" ("tmdb_id");
+-- Set comment to column: "imdb_id" on table: "movies"
+COMMENT ON COLUMN "movies"."imdb_id" IS 'IMDb tt-ID';
+-- Create "movie_credits" table
+CREATE TABLE "movie_credits" (
+  "id" uuid NOT NULL,
+  "movie_id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "role" text NOT NULL,
+  "character" text NULL,
+  "position" integer NULL DEFAULT 0,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_movies_credits" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_movie_credits_movie_id" to table: "movie_credits"
+CREATE INDEX "idx_movie_credits_movie_id" ON "movie_credits" ("movie_id");
+-- Create index "idx_movie_credits_name" to table: "movie_credits"
+CREATE INDEX "idx_movie_credits_name" ON "movie_credits" ("name");
+-- Set comment to column: "role" on table: "movie_credits"
+COMMENT ON COLUMN "movie_credits"."role" IS 'director | writer | cast';
+-- Set comment to column: "position" on table: "movie_credits"
+COMMENT ON COLUMN "movie_credits"."position" IS 'Billing order';
@@ -0,0 +1,10 @@
+-- Create "movie_redirects" table
+CREATE TABLE "movie_redirects" (
+  "from_id" uuid NOT NULL,
+  "to_id" uuid NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("from_id"),
+  CONSTRAINT "fk_movie_redirects_to" FOREIGN KEY ("to_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_movie_redirects_to_id" to table: "movie_redirects"
+CREATE INDEX "idx_movie_redirects_to_id" ON "movie_redirects" ("to_id");
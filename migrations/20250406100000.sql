@@ -0,0 +1,27 @@
+-- Modify "countries" table
+ALTER TABLE "countries" ADD COLUMN "slug" text NULL;
+-- Create index "idx_countries_slug" to table: "countries"
+CREATE UNIQUE INDEX "idx_countries_slug" ON "countries" ("slug");
+-- Modify "genres" table
+ALTER TABLE "genres" ADD COLUMN "slug" text NULL;
+-- Create index "idx_genres_slug" to table: "genres"
+CREATE UNIQUE INDEX "idx_genres_slug" ON "genres" ("slug");
+-- Modify "movies" table
+ALTER TABLE "movies" ADD COLUMN "slug" text NULL;
+-- Create index "idx_movies_slug" to table: "movies"
+CREATE UNIQUE INDEX "idx_movies_slug" ON "movies" ("slug");
+-- Create "slug_histories" table
+CREATE TABLE "slug_histories" (
+  "id" uuid NOT NULL,
+  "entity_type" text NOT NULL,
+  "slug" text NOT NULL,
+  "entity_id" uuid NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Set comment to column: "entity_type" on table: "slug_histories"
+COMMENT ON COLUMN "slug_histories"."entity_type" IS 'movie | genre | country';
+-- Create index "idx_slug_histories_entity_id" to table: "slug_histories"
+CREATE INDEX "idx_slug_histories_entity_id" ON "slug_histories" ("entity_id");
+-- Create index "idx_slug_histories_entity_slug" to table: "slug_histories"
+CREATE UNIQUE INDEX "idx_slug_histories_entity_slug" ON "slug_histories" ("entity_type", "slug");
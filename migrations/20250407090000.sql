@@ -0,0 +1,2 @@
+-- Create index "idx_movies_created_at" to table: "movies"
+CREATE INDEX "idx_movies_created_at" ON "movies" ("created_at");
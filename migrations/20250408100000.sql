@@ -0,0 +1,8 @@
+-- Modify "movies" table
+ALTER TABLE "movies" ADD COLUMN "published_at" timestamptz NULL;
+-- Set comment to column: "published_at" on table: "movies"
+COMMENT ON COLUMN "movies"."published_at" IS 'NULL while the movie is a draft';
+-- Create index "idx_movies_published_at" to table: "movies"
+CREATE INDEX "idx_movies_published_at" ON "movies" ("published_at");
+-- Existing movies were all public
+UPDATE "movies" SET "published_at" = "created_at";
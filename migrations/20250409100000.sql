@@ -0,0 +1,54 @@
+-- Create "watch_histories" table
+CREATE TABLE "watch_histories" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "movie_id" uuid NOT NULL,
+  "watched_at" timestamptz NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_watch_histories_movie" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_watch_histories_movie_id" to table: "watch_histories"
+CREATE INDEX "idx_watch_histories_movie_id" ON "watch_histories" ("movie_id");
+-- Create index "idx_watch_histories_user_id" to table: "watch_histories"
+CREATE INDEX "idx_watch_histories_user_id" ON "watch_histories" ("user_id");
+-- Create index "idx_watch_histories_watched_at" to table: "watch_histories"
+CREATE INDEX "idx_watch_histories_watched_at" ON "watch_histories" ("watched_at");
+-- Create "movie_ratings" table
+CREATE TABLE "movie_ratings" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "movie_id" uuid NOT NULL,
+  "score" integer NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_movie_ratings_movie" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_movie_ratings_movie_id" to table: "movie_ratings"
+CREATE INDEX "idx_movie_ratings_movie_id" ON "movie_ratings" ("movie_id");
+-- Create index "idx_movie_ratings_user_movie" to table: "movie_ratings"
+CREATE UNIQUE INDEX "idx_movie_ratings_user_movie" ON "movie_ratings" ("user_id", "movie_id");
+-- Set comment to column: "score" on table: "movie_ratings"
+COMMENT ON COLUMN "movie_ratings"."score" IS '1 to 10';
+-- Create "movie_cooccurrences" table
+CREATE TABLE "movie_cooccurrences" (
+  "movie_id" uuid NOT NULL,
+  "related_id" uuid NOT NULL,
+  "users" integer NOT NULL,
+  "score" double precision NOT NULL,
+  "computed_at" timestamptz NOT NULL,
+  PRIMARY KEY ("movie_id", "related_id")
+);
+-- Set comment to column: "users" on table: "movie_cooccurrences"
+COMMENT ON COLUMN "movie_cooccurrences"."users" IS 'Users who liked both movies';
+-- Set comment to column: "score" on table: "movie_cooccurrences"
+COMMENT ON COLUMN "movie_cooccurrences"."score" IS 'Cosine similarity of the two audiences';
+-- Create "user_preferred_genres" table
+CREATE TABLE "user_preferred_genres" (
+  "user_id" uuid NOT NULL,
+  "genre_id" uuid NOT NULL,
+  PRIMARY KEY ("user_id", "genre_id"),
+  CONSTRAINT "fk_user_preferred_genres_genre" FOREIGN KEY ("genre_id") REFERENCES "genres" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_user_preferred_genres_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
@@ -0,0 +1,34 @@
+-- Create "movie_events" table
+CREATE TABLE "movie_events" (
+  "id" uuid NOT NULL,
+  "movie_id" uuid NOT NULL,
+  "user_id" uuid NULL,
+  "type" text NOT NULL,
+  "country_code" character varying(2) NOT NULL DEFAULT '',
+  "visitor" text NOT NULL DEFAULT '',
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_movie_events_created_at" to table: "movie_events"
+CREATE INDEX "idx_movie_events_created_at" ON "movie_events" ("created_at");
+-- Create index "idx_movie_events_movie_id" to table: "movie_events"
+CREATE INDEX "idx_movie_events_movie_id" ON "movie_events" ("movie_id");
+-- Create index "idx_movie_events_visitor_created_at" to table: "movie_events"
+CREATE INDEX "idx_movie_events_visitor_created_at" ON "movie_events" ("visitor", "created_at");
+-- Set comment to column: "user_id" on table: "movie_events"
+COMMENT ON COLUMN "movie_events"."user_id" IS 'NULL for anonymous viewers';
+-- Set comment to column: "type" on table: "movie_events"
+COMMENT ON COLUMN "movie_events"."type" IS 'view | review';
+-- Set comment to column: "country_code" on table: "movie_events"
+COMMENT ON COLUMN "movie_events"."country_code" IS 'ISO 3166-1 alpha-2 code, empty when unknown';
+-- Set comment to column: "visitor" on table: "movie_events"
+COMMENT ON COLUMN "movie_events"."visitor" IS 'user:<id> or ip:<HMAC-SHA256 of the address>, for deduplication and rate limiting';
+-- Create "movie_popularity" table
+CREATE TABLE "movie_popularity" (
+  "movie_id" uuid NOT NULL,
+  "country_code" character varying(2) NOT NULL,
+  "trending_score" double precision NOT NULL DEFAULT 0,
+  "popularity_score" double precision NOT NULL DEFAULT 0,
+  "computed_at" timestamptz NOT NULL,
+  PRIMARY KEY ("movie_id", "country_code")
+);
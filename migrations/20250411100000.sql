@@ -0,0 +1,6 @@
+-- Modify "genres" table
+ALTER TABLE "genres" ADD COLUMN "parent_id" uuid NULL, ADD CONSTRAINT "fk_genres_children" FOREIGN KEY ("parent_id") REFERENCES "genres" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION;
+-- Create index "idx_genres_parent_id" to table: "genres"
+CREATE INDEX "idx_genres_parent_id" ON "genres" ("parent_id");
+-- Set comment to column: "parent_id" on table: "genres"
+COMMENT ON COLUMN "genres"."parent_id" IS 'NULL for top-level genres';
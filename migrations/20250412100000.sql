@@ -0,0 +1,20 @@
+-- Create "tags" table
+CREATE TABLE "tags" (
+  "id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_tags_name" to table: "tags"
+CREATE UNIQUE INDEX "idx_tags_name" ON "tags" ("name");
+-- Set comment to column: "name" on table: "tags"
+COMMENT ON COLUMN "tags"."name" IS 'lower case, single spaced';
+-- Create "movie_tags" table
+CREATE TABLE "movie_tags" (
+  "movie_id" uuid NOT NULL,
+  "tag_id" uuid NOT NULL,
+  PRIMARY KEY ("movie_id", "tag_id"),
+  CONSTRAINT "fk_movie_tags_movie" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_movie_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
@@ -0,0 +1,45 @@
+-- Create "awards" table
+CREATE TABLE "awards" (
+  "id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "description" text NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_awards_name" to table: "awards"
+CREATE UNIQUE INDEX "idx_awards_name" ON "awards" ("name");
+-- Create "award_categories" table
+CREATE TABLE "award_categories" (
+  "id" uuid NOT NULL,
+  "award_id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_awards_categories" FOREIGN KEY ("award_id") REFERENCES "awards" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_award_categories_award_name" to table: "award_categories"
+CREATE UNIQUE INDEX "idx_award_categories_award_name" ON "award_categories" ("award_id", "name");
+-- Create "nominations" table
+CREATE TABLE "nominations" (
+  "id" uuid NOT NULL,
+  "movie_id" uuid NOT NULL,
+  "category_id" uuid NOT NULL,
+  "year" integer NOT NULL,
+  "person" text NOT NULL DEFAULT '',
+  "won" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_nominations_category" FOREIGN KEY ("category_id") REFERENCES "award_categories" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_nominations_movie" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_nominations_category_id" to table: "nominations"
+CREATE INDEX "idx_nominations_category_id" ON "nominations" ("category_id");
+-- Create index "idx_nominations_unique" to table: "nominations"
+CREATE UNIQUE INDEX "idx_nominations_unique" ON "nominations" ("movie_id", "category_id", "year", "person");
+-- Set comment to column: "year" on table: "nominations"
+COMMENT ON COLUMN "nominations"."year" IS 'Ceremony year';
+-- Set comment to column: "person" on table: "nominations"
+COMMENT ON COLUMN "nominations"."person" IS 'Nominee name, empty when the movie itself is nominated';
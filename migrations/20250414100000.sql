@@ -0,0 +1,21 @@
+-- Create "release_dates" table
+CREATE TABLE "release_dates" (
+  "id" uuid NOT NULL,
+  "movie_id" uuid NOT NULL,
+  "country_id" uuid NOT NULL,
+  "type" text NOT NULL,
+  "date" date NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_movies_release_dates" FOREIGN KEY ("movie_id") REFERENCES "movies" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_release_dates_country" FOREIGN KEY ("country_id") REFERENCES "countries" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_release_dates_date" to table: "release_dates"
+CREATE INDEX "idx_release_dates_date" ON "release_dates" ("date");
+-- Create index "idx_release_dates_movie_country_type" to table: "release_dates"
+CREATE UNIQUE INDEX "idx_release_dates_movie_country_type" ON "release_dates" ("movie_id", "country_id", "type");
+-- Set comment to column: "type" on table: "release_dates"
+COMMENT ON COLUMN "release_dates"."type" IS 'theatrical | digital | physical | tv';
+-- Set comment to column: "release_date" on table: "movies"
+COMMENT ON COLUMN "movies"."release_date" IS 'Primary date, computed from release_dates when there are any';
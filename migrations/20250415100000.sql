@@ -0,0 +1,35 @@
+-- Modify "sessions" table
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid NULL, ADD COLUMN "rotated_from" uuid NULL;
+-- Existing sessions each start their own family
+UPDATE "sessions" SET "family_id" = "id";
+-- Modify "sessions" table
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+-- Create index "idx_sessions_family_id" to table: "sessions"
+CREATE INDEX "idx_sessions_family_id" ON "sessions" ("family_id");
+-- Create index "idx_sessions_rotated_from" to table: "sessions"
+CREATE INDEX "idx_sessions_rotated_from" ON "sessions" ("rotated_from");
+-- Set comment to column: "family_id" on table: "sessions"
+COMMENT ON COLUMN "sessions"."family_id" IS 'First session of the refresh token rotation chain';
+-- Set comment to column: "rotated_from" on table: "sessions"
+COMMENT ON COLUMN "sessions"."rotated_from" IS 'Session whose refresh token was exchanged for this one';
+-- Create "security_events" table
+CREATE TABLE "security_events" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NULL,
+  "type" text NOT NULL,
+  "ip_address" text NULL,
+  "user_agent" text NULL,
+  "details" text NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_security_events_created_at" to table: "security_events"
+CREATE INDEX "idx_security_events_created_at" ON "security_events" ("created_at");
+-- Create index "idx_security_events_type" to table: "security_events"
+CREATE INDEX "idx_security_events_type" ON "security_events" ("type");
+-- Create index "idx_security_events_user_id" to table: "security_events"
+CREATE INDEX "idx_security_events_user_id" ON "security_events" ("user_id");
+-- Set comment to column: "user_id" on table: "security_events"
+COMMENT ON COLUMN "security_events"."user_id" IS 'NULL when no user could be identified';
+-- Set comment to column: "type" on table: "security_events"
+COMMENT ON COLUMN "security_events"."type" IS 'refresh_token_reuse';
@@ -0,0 +1,14 @@
+-- Modify "sessions" table
+ALTER TABLE "sessions" ADD COLUMN "access_jti" text NULL, ADD COLUMN "refresh_jti" text NULL;
+-- Sessions holding raw tokens are signed out, the placeholders never match a real jti
+UPDATE "sessions" SET "is_revoked" = true, "access_jti" = 'legacy-access-' || "id", "refresh_jti" = 'legacy-refresh-' || "id";
+-- Modify "sessions" table
+ALTER TABLE "sessions" ALTER COLUMN "access_jti" SET NOT NULL, ALTER COLUMN "refresh_jti" SET NOT NULL, DROP COLUMN "access_token", DROP COLUMN "refresh_token";
+-- Create index "idx_sessions_access_jti" to table: "sessions"
+CREATE UNIQUE INDEX "idx_sessions_access_jti" ON "sessions" ("access_jti");
+-- Create index "idx_sessions_refresh_jti" to table: "sessions"
+CREATE UNIQUE INDEX "idx_sessions_refresh_jti" ON "sessions" ("refresh_jti");
+-- Set comment to column: "access_jti" on table: "sessions"
+COMMENT ON COLUMN "sessions"."access_jti" IS 'jti of the access token, the token itself is never stored';
+-- Set comment to column: "refresh_jti" on table: "sessions"
+COMMENT ON COLUMN "sessions"."refresh_jti" IS 'jti of the refresh token';
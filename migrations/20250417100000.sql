@@ -0,0 +1,26 @@
+-- Create "login_throttles" table
+CREATE TABLE "login_throttles" (
+  "id" uuid NOT NULL,
+  "scope" text NOT NULL,
+  "subject" text NOT NULL,
+  "failures" integer NOT NULL DEFAULT 0,
+  "last_failure_at" timestamptz NOT NULL,
+  "blocked_until" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_login_throttles_blocked_until" to table: "login_throttles"
+CREATE INDEX "idx_login_throttles_blocked_until" ON "login_throttles" ("blocked_until");
+-- Create index "idx_login_throttles_last_failure_at" to table: "login_throttles"
+CREATE INDEX "idx_login_throttles_last_failure_at" ON "login_throttles" ("last_failure_at");
+-- Create index "idx_login_throttles_scope_subject" to table: "login_throttles"
+CREATE UNIQUE INDEX "idx_login_throttles_scope_subject" ON "login_throttles" ("scope", "subject");
+-- Set comment to column: "scope" on table: "login_throttles"
+COMMENT ON COLUMN "login_throttles"."scope" IS 'username | ip';
+-- Set comment to column: "subject" on table: "login_throttles"
+COMMENT ON COLUMN "login_throttles"."subject" IS 'Lowercased username or IP address';
+-- Set comment to column: "blocked_until" on table: "login_throttles"
+COMMENT ON COLUMN "login_throttles"."blocked_until" IS 'Logins are refused until then';
+-- Set comment to column: "type" on table: "security_events"
+COMMENT ON COLUMN "security_events"."type" IS 'refresh_token_reuse | login_lockout';
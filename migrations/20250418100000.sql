@@ -0,0 +1,30 @@
+-- Modify "users" table
+ALTER TABLE "users" ADD COLUMN "locale" text NOT NULL DEFAULT 'en', ADD COLUMN "email_verified_at" timestamptz NULL;
+-- Accounts created before verification existed count as verified
+UPDATE "users" SET "email_verified_at" = "created_at";
+-- Set comment to column: "locale" on table: "users"
+COMMENT ON COLUMN "users"."locale" IS 'uz | ru | en, language of the emails sent to the user';
+-- Set comment to column: "email_verified_at" on table: "users"
+COMMENT ON COLUMN "users"."email_verified_at" IS 'NULL until the email address is confirmed';
+-- Create "user_tokens" table
+CREATE TABLE "user_tokens" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "purpose" text NOT NULL,
+  "token_hash" text NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_user_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_user_tokens_token_hash" to table: "user_tokens"
+CREATE UNIQUE INDEX "idx_user_tokens_token_hash" ON "user_tokens" ("token_hash");
+-- Create index "idx_user_tokens_user_id" to table: "user_tokens"
+CREATE INDEX "idx_user_tokens_user_id" ON "user_tokens" ("user_id");
+-- Set comment to column: "purpose" on table: "user_tokens"
+COMMENT ON COLUMN "user_tokens"."purpose" IS 'email_verification | password_reset';
+-- Set comment to column: "token_hash" on table: "user_tokens"
+COMMENT ON COLUMN "user_tokens"."token_hash" IS 'SHA-256 of the token';
+-- Set comment to column: "used_at" on table: "user_tokens"
+COMMENT ON COLUMN "user_tokens"."used_at" IS 'Set when the token is redeemed or superseded';
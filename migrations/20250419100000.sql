@@ -0,0 +1,22 @@
+-- Modify "users" table
+ALTER TABLE "users" ADD COLUMN "totp_secret" text NULL, ADD COLUMN "totp_enabled_at" timestamptz NULL, ADD COLUMN "totp_last_step" bigint NOT NULL DEFAULT 0;
+-- Set comment to column: "totp_secret" on table: "users"
+COMMENT ON COLUMN "users"."totp_secret" IS 'Base32 TOTP secret, set while enrolling or enrolled';
+-- Set comment to column: "totp_enabled_at" on table: "users"
+COMMENT ON COLUMN "users"."totp_enabled_at" IS 'NULL until TOTP enrollment is confirmed';
+-- Set comment to column: "totp_last_step" on table: "users"
+COMMENT ON COLUMN "users"."totp_last_step" IS 'Time step of the last accepted code, codes cannot be replayed';
+-- Create "recovery_codes" table
+CREATE TABLE "recovery_codes" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "code_hash" text NOT NULL,
+  "used_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_recovery_codes_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_recovery_codes_user_id" to table: "recovery_codes"
+CREATE INDEX "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
+-- Set comment to column: "code_hash" on table: "recovery_codes"
+COMMENT ON COLUMN "recovery_codes"."code_hash" IS 'SHA-256 of the normalized code';
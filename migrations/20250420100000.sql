@@ -0,0 +1,38 @@
+-- Create "oidc_states" table
+CREATE TABLE "oidc_states" (
+  "state_hash" text NOT NULL,
+  "provider" text NOT NULL,
+  "code_verifier" text NOT NULL,
+  "nonce" text NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("state_hash")
+);
+-- Create index "idx_oidc_states_expires_at" to table: "oidc_states"
+CREATE INDEX "idx_oidc_states_expires_at" ON "oidc_states" ("expires_at");
+-- Set comment to column: "state_hash" on table: "oidc_states"
+COMMENT ON COLUMN "oidc_states"."state_hash" IS 'SHA-256 of the state parameter';
+-- Set comment to column: "code_verifier" on table: "oidc_states"
+COMMENT ON COLUMN "oidc_states"."code_verifier" IS 'PKCE verifier';
+-- Create "user_identities" table
+CREATE TABLE "user_identities" (
+  "id" uuid NOT NULL,
+  "user_id" uuid NOT NULL,
+  "provider" text NOT NULL,
+  "subject" text NOT NULL,
+  "email" text NULL,
+  "last_login_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_user_identities_provider_subject" to table: "user_identities"
+CREATE UNIQUE INDEX "idx_user_identities_provider_subject" ON "user_identities" ("provider", "subject");
+-- Create index "idx_user_identities_user_id" to table: "user_identities"
+CREATE INDEX "idx_user_identities_user_id" ON "user_identities" ("user_id");
+-- Set comment to column: "provider" on table: "user_identities"
+COMMENT ON COLUMN "user_identities"."provider" IS 'Provider name from the oidc config';
+-- Set comment to column: "subject" on table: "user_identities"
+COMMENT ON COLUMN "user_identities"."subject" IS 'sub claim, stable per provider';
+-- Set comment to column: "email" on table: "user_identities"
+COMMENT ON COLUMN "user_identities"."email" IS 'Email the provider reported at the last login';
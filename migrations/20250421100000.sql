@@ -0,0 +1,33 @@
+-- Create "api_keys" table
+CREATE TABLE "api_keys" (
+  "id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "prefix" text NOT NULL,
+  "key_hash" text NOT NULL,
+  "scopes" text NOT NULL,
+  "user_id" uuid NOT NULL,
+  "created_by" uuid NOT NULL,
+  "expires_at" timestamptz NULL,
+  "last_used_at" timestamptz NULL,
+  "revoked_at" timestamptz NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_api_keys_created_by" FOREIGN KEY ("created_by") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_api_keys_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_api_keys_key_hash" to table: "api_keys"
+CREATE UNIQUE INDEX "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+-- Create index "idx_api_keys_prefix" to table: "api_keys"
+CREATE UNIQUE INDEX "idx_api_keys_prefix" ON "api_keys" ("prefix");
+-- Create index "idx_api_keys_user_id" to table: "api_keys"
+CREATE INDEX "idx_api_keys_user_id" ON "api_keys" ("user_id");
+-- Set comment to column: "prefix" on table: "api_keys"
+COMMENT ON COLUMN "api_keys"."prefix" IS 'Start of the key, shown to identify it';
+-- Set comment to column: "key_hash" on table: "api_keys"
+COMMENT ON COLUMN "api_keys"."key_hash" IS 'SHA-256 of the key';
+-- Set comment to column: "scopes" on table: "api_keys"
+COMMENT ON COLUMN "api_keys"."scopes" IS 'Space separated: read | write';
+-- Set comment to column: "user_id" on table: "api_keys"
+COMMENT ON COLUMN "api_keys"."user_id" IS 'The key acts as this user, with its role';
+-- Set comment to column: "expires_at" on table: "api_keys"
+COMMENT ON COLUMN "api_keys"."expires_at" IS 'NULL for keys that do not expire';
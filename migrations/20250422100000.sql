@@ -0,0 +1,76 @@
+-- Create "permissions" table
+CREATE TABLE "permissions" (
+  "name" text NOT NULL,
+  "description" text NULL,
+  PRIMARY KEY ("name")
+);
+-- Create "roles" table
+CREATE TABLE "roles" (
+  "id" uuid NOT NULL,
+  "name" text NOT NULL,
+  "description" text NULL,
+  "system" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_roles_name" to table: "roles"
+CREATE UNIQUE INDEX "idx_roles_name" ON "roles" ("name");
+-- Set comment to column: "name" on table: "roles"
+COMMENT ON COLUMN "roles"."name" IS 'Stored in users.role and the role claim of tokens';
+-- Set comment to column: "system" on table: "roles"
+COMMENT ON COLUMN "roles"."system" IS 'Seeded roles cannot be deleted';
+-- Create "role_permissions" table
+CREATE TABLE "role_permissions" (
+  "role_id" uuid NOT NULL,
+  "permission_name" text NOT NULL,
+  PRIMARY KEY ("role_id", "permission_name"),
+  CONSTRAINT "fk_role_permissions_permission" FOREIGN KEY ("permission_name") REFERENCES "permissions" ("name") ON UPDATE NO ACTION ON DELETE NO ACTION,
+  CONSTRAINT "fk_role_permissions_role" FOREIGN KEY ("role_id") REFERENCES "roles" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Modify "users" table
+ALTER TABLE "users" ALTER COLUMN "role" SET DEFAULT 'USER';
+-- Set comment to column: "role" on table: "users"
+COMMENT ON COLUMN "users"."role" IS 'Name of a row in roles';
+-- Roles are matched by name, the old default was lower case
+UPDATE "users" SET "role" = 'USER' WHERE "role" IS NULL OR "role" = 'user';
+-- Seed the permissions checked by the routes
+INSERT INTO "permissions" ("name", "description") VALUES
+  ('movie:create', 'Create movies'),
+  ('movie:update', 'Edit movies and apply metadata enrichment'),
+  ('movie:delete', 'Delete movies'),
+  ('movie:merge', 'Find and merge duplicate movies'),
+  ('media:upload', 'Upload posters and backdrops'),
+  ('media:delete', 'Delete posters and backdrops'),
+  ('genre:create', 'Create genres'),
+  ('genre:update', 'Edit genres'),
+  ('genre:delete', 'Delete genres'),
+  ('language:create', 'Create languages'),
+  ('language:update', 'Edit languages'),
+  ('language:delete', 'Delete languages'),
+  ('country:create', 'Create countries'),
+  ('country:update', 'Edit countries'),
+  ('country:delete', 'Delete countries'),
+  ('award:create', 'Create awards, categories and nominations'),
+  ('award:update', 'Edit awards, categories and nominations'),
+  ('award:delete', 'Delete awards, categories and nominations'),
+  ('tag:manage', 'Rename and merge tags'),
+  ('stats:read', 'Read the admin reports'),
+  ('jobs:run', 'Recompute recommendations and popularity'),
+  ('user:manage', 'Manage users, their sessions and login lockouts'),
+  ('apikey:manage', 'Issue and revoke API keys'),
+  ('role:manage', 'Edit roles and assign them to users');
+-- Seed the roles that were hard-coded before, with the access they had
+INSERT INTO "roles" ("id", "name", "description", "system", "created_at", "updated_at") VALUES
+  (gen_random_uuid(), 'ADMIN', 'Full access', true, now(), now()),
+  (gen_random_uuid(), 'DIRECTOR', 'Manages the catalog', true, now(), now()),
+  (gen_random_uuid(), 'USER', 'Registered viewer', true, now(), now());
+INSERT INTO "role_permissions" ("role_id", "permission_name")
+SELECT "roles"."id", "permissions"."name" FROM "roles", "permissions" WHERE "roles"."name" = 'ADMIN';
+INSERT INTO "role_permissions" ("role_id", "permission_name")
+SELECT "roles"."id", "permissions"."name" FROM "roles", "permissions"
+WHERE "roles"."name" = 'DIRECTOR' AND "permissions"."name" IN (
+  'movie:create', 'movie:update', 'movie:delete', 'media:upload', 'media:delete',
+  'genre:create', 'genre:update', 'genre:delete', 'language:create', 'language:update', 'language:delete',
+  'country:create', 'country:update', 'country:delete', 'award:create', 'award:update', 'award:delete'
+);